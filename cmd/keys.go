@@ -0,0 +1,145 @@
+package main
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// keysCommand manages the libp2p identity that determines the node's PeerID.
+// The identity is stored encrypted with the passphrase resolved by OpenSigner.
+// It also creates the master seed regular nodes may derive secret values from.
+func keysCommand(cfg *config.Config, args []string) error {
+	name, args, err := subcommand(args, "generate", "import", "show-peer-id", "generate-seed")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("keys "+name, flag.ContinueOnError)
+	force := fs.Bool("force", false, "replace an existing identity")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if name == "generate-seed" {
+		if fs.NArg() != 1 {
+			return errors.New("expected the path of the seed file to create")
+		}
+		return generateSeed(fs.Arg(0))
+	}
+
+	if err := cfg.OpenSigner(); err != nil {
+		return err
+	}
+	passphrase := cfg.IdentityPassphrase()
+
+	closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	switch name {
+	case "generate":
+		if err := checkNoIdentity(passphrase, *force); err != nil {
+			return err
+		}
+		privKey, _, err := crypto.GenerateKeyPair(crypto.Ed25519, 0)
+		if err != nil {
+			return fmt.Errorf("failed to generate private key: %v", err)
+		}
+		return saveIdentity(privKey, passphrase)
+
+	case "import":
+		if fs.NArg() != 1 {
+			return errors.New("expected the path of a libp2p private key file")
+		}
+		if err := checkNoIdentity(passphrase, *force); err != nil {
+			return err
+		}
+		privKey, err := readLibp2pKey(fs.Arg(0))
+		if err != nil {
+			return err
+		}
+		return saveIdentity(privKey, passphrase)
+
+	default:
+		_, peerID, err := utils.LoadPeerID(passphrase)
+		if err != nil {
+			return fmt.Errorf("no libp2p identity stored for the %s node: %v", cfg.NodeType, err)
+		}
+		fmt.Println(peerID.String())
+		return nil
+	}
+}
+
+func checkNoIdentity(passphrase string, force bool) error {
+	_, peerID, err := utils.LoadPeerID(passphrase)
+	switch {
+	case force, errors.Is(err, utils.ErrNotFound):
+		return nil
+	case err == nil:
+		return fmt.Errorf("an identity with PeerID %s already exists, use -force to replace it", peerID)
+	default:
+		return fmt.Errorf("an identity is stored but cannot be loaded (%v), use -force to replace it", err)
+	}
+}
+
+func saveIdentity(privKey crypto.PrivKey, passphrase string) error {
+	peerID, err := peer.IDFromPrivateKey(privKey)
+	if err != nil {
+		return fmt.Errorf("failed to get PeerID from private key: %v", err)
+	}
+	if err := utils.SavePeerID(privKey, passphrase); err != nil {
+		return err
+	}
+	fmt.Println(peerID.String())
+	return nil
+}
+
+// generateSeed writes a new random secret seed to path, which must not exist.
+func generateSeed(path string) error {
+	seed := make([]byte, commitreveal2.MinSecretSeedLength)
+	if _, err := rand.Read(seed); err != nil {
+		return fmt.Errorf("failed to generate secret seed: %v", err)
+	}
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return fmt.Errorf("failed to create seed file: %v", err)
+	}
+	if _, err := fmt.Fprintln(f, hex.EncodeToString(seed)); err != nil {
+		f.Close()
+		return fmt.Errorf("failed to write seed file: %v", err)
+	}
+	if err := f.Close(); err != nil {
+		return fmt.Errorf("failed to write seed file: %v", err)
+	}
+	fmt.Printf("Secret seed written to %s. Back it up and set regular.secret_seed_file (SECRET_SEED_FILE) to use it.\n", path)
+	return nil
+}
+
+// readLibp2pKey reads a protobuf-encoded libp2p private key, either raw or hex encoded.
+func readLibp2pKey(path string) (crypto.PrivKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read key file: %v", err)
+	}
+	if decoded, err := hex.DecodeString(string(bytes.TrimPrefix(bytes.TrimSpace(data), []byte("0x")))); err == nil {
+		data = decoded
+	}
+	privKey, err := crypto.UnmarshalPrivateKey(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode libp2p private key: %v", err)
+	}
+	return privKey, nil
+}
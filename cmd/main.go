@@ -1,32 +1,206 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/joho/godotenv"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
 	"github.com/tokamak-network/DRB-node/logger"
 	"github.com/tokamak-network/DRB-node/nodes"
+	"github.com/tokamak-network/DRB-node/sensitive"
+	"github.com/tokamak-network/DRB-node/utils"
 )
 
+const usage = `Usage: drbnode [-config file] [-node leader|regular] <command> [arguments]
+
+Commands:
+  run [leader|regular]            run a node (the configured node type when omitted)
+  keys generate [-force]          create a new encrypted libp2p identity
+  keys import [-force] <file>     import a libp2p private key (hex or binary protobuf)
+  keys show-peer-id               print the PeerID of the stored libp2p identity
+  keys generate-seed <file>       create a master seed to derive secret values from
+  operator status [address]       show the deposit and activation of an operator
+  operator deposit [-amount wei]  deposit, by default up to the activation threshold
+  operator activate [address]     activate an operator
+  operator deactivate [address]   deactivate an operator
+  operator withdraw -amount wei   withdraw from the deposit
+  round show <round>              show the stored state of a round
+  state export <file>             write the node state to a JSON file
+  state import [-force] <file>    load node state written by state export
+
+Without a command the node selected by NODE_TYPE is run.
+The operator commands default to the address of the node's key.
+
+Global flags:
+`
+
+// command runs a subcommand with the configuration read from file and environment.
+type command func(cfg *config.Config, args []string) error
+
+var commands = map[string]command{
+	"run":      runCommand,
+	"keys":     keysCommand,
+	"operator": operatorCommand,
+	"round":    roundCommand,
+	"state":    stateCommand,
+}
+
 func main() {
 	if err := godotenv.Load(); err != nil {
 		log.Println("No .env file found")
 	}
-	
+
+	flag.Usage = func() {
+		fmt.Fprint(flag.CommandLine.Output(), usage)
+		flag.PrintDefaults()
+	}
+	configPath := flag.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML config file; environment variables override its values")
+	nodeType := flag.String("node", "", "node type (leader or regular), overrides NODE_TYPE")
+	logSecrets := flag.Bool("unsafe-log-secrets", false, "write secret values, COS and signatures to the logs in full, for debugging only")
+	flag.Parse()
+
 	logger.InitLogger()
 	defer logger.CloseLogger()
-	
-	nodeType := os.Getenv("NODE_TYPE") // Expecting 'leader' or 'regular'
 
-	switch nodeType {
-	case "leader":
-		nodes.RunLeaderNode()
-	case "regular":
-		nodes.RunRegularNode()
-	default:
-		log.Fatal("NODE_TYPE must be set to either 'leader' or 'regular'")
+	// Only the command line flag reveals secrets, so no config file or
+	// environment left behind can turn it on.
+	if *logSecrets {
+		sensitive.RevealInLogs()
+	}
+
+	cfg, err := config.Read(*configPath)
+	if err != nil {
+		log.Fatalf("%v", err)
+	}
+	if *nodeType != "" {
+		cfg.NodeType = *nodeType
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		args = []string{"run"}
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := cmd(cfg, args[1:]); err != nil {
+		log.Fatalf("%s: %v", args[0], err)
+	}
+}
+
+// runCommand validates the configuration and runs a leader or regular node.
+func runCommand(cfg *config.Config, args []string) error {
+	if len(args) > 1 {
+		return fmt.Errorf("expected at most one node type, got %v", args)
+	}
+	if len(args) == 1 {
+		cfg.NodeType = args[0]
+	}
+	if err := cfg.Validate(); err != nil {
+		return err
+	}
+	if err := cfg.OpenSigner(); err != nil {
+		return err
+	}
+
+	closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if err := openSecretStore(cfg); err != nil {
+		return err
+	}
+
+	drb, err := dialContract(cfg)
+	if err != nil {
+		return err
+	}
+	defer drb.Close()
+
+	switch cfg.NodeType {
+	case config.NodeTypeLeader:
+		nodes.RunLeaderNode(cfg, drb)
+	case config.NodeTypeRegular:
+		nodes.RunRegularNode(cfg, drb)
 	}
+	return nil
 }
 
+// openStore opens the configured store as the process-wide default store.
+func openStore(cfg *config.Config) (func(), error) {
+	if cfg.NodeType != config.NodeTypeLeader && cfg.NodeType != config.NodeTypeRegular {
+		return nil, fmt.Errorf("node type must be leader or regular, set NODE_TYPE or -node")
+	}
+
+	store, err := utils.OpenStore(cfg.Storage.Backend, cfg.Storage.Dir, cfg.NodeType)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open storage: %v", err)
+	}
+	utils.SetDefaultStore(store, cfg.NodeType)
+	return func() { store.Close() }, nil
+}
 
+// openSecretStore opens the secret store. A store that earlier versions
+// encrypted with the passphrase derived from a raw private key is encrypted
+// again with the configured passphrase first.
+func openSecretStore(cfg *config.Config) error {
+	err := utils.OpenSecretStore(cfg.SecretsPassphrase())
+	if !errors.Is(err, utils.ErrWrongSecretPassphrase) || cfg.LegacySecretsPassphrase() == "" {
+		return err
+	}
+	if err := utils.ChangeSecretStorePassphrase(cfg.LegacySecretsPassphrase(), cfg.SecretsPassphrase()); err != nil {
+		return fmt.Errorf("failed to re-encrypt the secret store: %v", err)
+	}
+	return utils.OpenSecretStore(cfg.SecretsPassphrase())
+}
+
+// dialContract creates the DRB contract client shared by the whole process and
+// checks the configured chain ID against the RPC endpoint and the contract's
+// EIP-712 domain.
+func dialContract(cfg *config.Config) (*eth.DRBContract, error) {
+	drb, err := eth.DialDRBContract(context.Background(), cfg.Ethereum.RPCURL, cfg.ContractAddress(), cfg.Signer, cfg.Transactions.TxManagerConfig())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create DRB contract client: %v", err)
+	}
+	if err := cfg.CheckChainID(drb.ChainID()); err != nil {
+		drb.Close()
+		return nil, err
+	}
+
+	// CVS signatures are made and checked in the domain the contract reports,
+	// which must be for the configured chain and contract.
+	domain, err := drb.EIP712Domain(context.Background())
+	if err == nil {
+		err = domain.Check(cfg.ChainID(), cfg.ContractAddress())
+	}
+	if err != nil {
+		drb.Close()
+		return nil, err
+	}
+	log.Printf("EIP-712 domain: %s", domain)
+	return drb, nil
+}
+
+// subcommand splits args into a subcommand name and its arguments.
+func subcommand(args []string, names ...string) (string, []string, error) {
+	if len(args) == 0 {
+		return "", nil, fmt.Errorf("expected one of %v", names)
+	}
+	for _, name := range names {
+		if args[0] == name {
+			return name, args[1:], nil
+		}
+	}
+	return "", nil, fmt.Errorf("unknown subcommand %q, expected one of %v", args[0], names)
+}
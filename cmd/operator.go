@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/nodes"
+	"github.com/tokamak-network/DRB-node/nodes/leaderNode_helper"
+)
+
+// operatorCommand manages the operator deposit and activation on the DRB contract.
+func operatorCommand(cfg *config.Config, args []string) error {
+	name, args, err := subcommand(args, "status", "deposit", "activate", "deactivate", "withdraw")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("operator "+name, flag.ContinueOnError)
+	amountFlag := fs.String("amount", "", "amount in wei")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	if err := cfg.ValidateOperator(); err != nil {
+		return err
+	}
+	if err := cfg.OpenSigner(); err != nil {
+		return err
+	}
+	drb, err := dialContract(cfg)
+	if err != nil {
+		return err
+	}
+	defer drb.Close()
+
+	ctx := context.Background()
+	operator := drb.From()
+	if fs.NArg() > 0 {
+		if !common.IsHexAddress(fs.Arg(0)) {
+			return fmt.Errorf("invalid operator address %q", fs.Arg(0))
+		}
+		operator = common.HexToAddress(fs.Arg(0))
+	}
+
+	var amount *big.Int
+	if *amountFlag != "" {
+		var ok bool
+		if amount, ok = new(big.Int).SetString(*amountFlag, 10); !ok || amount.Sign() <= 0 {
+			return fmt.Errorf("invalid amount %q", *amountFlag)
+		}
+	}
+
+	switch name {
+	case "status":
+		return printOperatorStatus(ctx, drb, operator)
+
+	case "deposit":
+		if amount == nil {
+			sent, err := nodes.DepositAndCheckActivation(ctx, drb, drb.From().Hex())
+			if err != nil {
+				return err
+			}
+			if !sent {
+				fmt.Println("Deposit already reaches the activation threshold.")
+			}
+			return nil
+		}
+		return printReceipt(drb.Deposit(ctx, amount))
+
+	case "activate":
+		return leaderNode_helper.ActivateOnChain(drb, operator.Hex())
+
+	case "deactivate":
+		return printReceipt(drb.Deactivate(ctx, operator))
+
+	default:
+		if amount == nil {
+			return errors.New("-amount is required")
+		}
+		return printReceipt(drb.Withdraw(ctx, amount))
+	}
+}
+
+func printOperatorStatus(ctx context.Context, drb *eth.DRBContract, operator common.Address) error {
+	deposit, err := drb.DepositAmount(ctx, operator)
+	if err != nil {
+		return err
+	}
+	threshold, err := drb.ActivationThreshold(ctx)
+	if err != nil {
+		return err
+	}
+	sufficient, err := nodes.CheckDepositAmount(drb, operator.Hex())
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Operator:             %s\n", operator.Hex())
+	fmt.Printf("Deposit:              %s wei\n", deposit)
+	fmt.Printf("Activation threshold: %s wei\n", threshold)
+	fmt.Printf("Deposit sufficient:   %t\n", sufficient)
+	fmt.Printf("Activated:            %t\n", nodes.CheckActivationStatus(drb, operator.Hex()))
+	return nil
+}
+
+func printReceipt(receipt *types.Receipt, err error) error {
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Transaction %s confirmed in block %v\n", receipt.TxHash.Hex(), receipt.BlockNumber)
+	return nil
+}
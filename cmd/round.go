@@ -0,0 +1,88 @@
+package main
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// roundCommand shows what the node has stored about a round.
+func roundCommand(cfg *config.Config, args []string) error {
+	_, args, err := subcommand(args, "show")
+	if err != nil {
+		return err
+	}
+	if len(args) != 1 {
+		return errors.New("expected a round number")
+	}
+	round := args[0]
+
+	closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	out := make(map[string]interface{})
+
+	state, err := utils.LoadRoundState(round)
+	switch {
+	case err == nil:
+		out["state"] = state
+	case !errors.Is(err, utils.ErrNotFound):
+		return err
+	}
+
+	reports, err := utils.LoadRoundReports()
+	if err != nil {
+		return err
+	}
+	if report, ok := reports[round]; ok {
+		out["report"] = report
+	}
+
+	if order, err := utils.LoadRevealOrder(round); err == nil {
+		out["reveal_order"] = order
+	} else if order, err := utils.LoadVerifiedRevealOrder(round); err == nil {
+		out["reveal_order"] = order
+	}
+
+	evidence, err := utils.LoadEvidence(round)
+	if err != nil {
+		return err
+	}
+	if len(evidence) > 0 {
+		out["evidence"] = evidence
+	}
+
+	// The secret value is never printed.
+	if commitData, err := utils.LoadCommitData(round); err == nil {
+		out["commit"] = map[string]interface{}{
+			"cvs":                hex.EncodeToString(commitData.Cvs[:]),
+			"cos_sent_to_leader": commitData.SendCosToLeader,
+			"cvs_sent_to_leader": commitData.SendToLeader,
+			"sign":               commitData.Sign,
+		}
+	}
+
+	outbox, err := utils.LoadRoundOutbox(round)
+	if err != nil {
+		return err
+	}
+	if len(outbox) > 0 {
+		out["outbox"] = outbox
+	}
+
+	if len(out) == 0 {
+		return fmt.Errorf("nothing is stored for round %s on the %s node", round, cfg.NodeType)
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
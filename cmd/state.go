@@ -0,0 +1,70 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// stateCommand exports and imports the stored node state. The export contains
+// the libp2p identity and round secrets, so it is written readable by the owner only.
+func stateCommand(cfg *config.Config, args []string) error {
+	name, args, err := subcommand(args, "export", "import")
+	if err != nil {
+		return err
+	}
+
+	fs := flag.NewFlagSet("state "+name, flag.ContinueOnError)
+	force := fs.Bool("force", false, "import a snapshot taken from another node type")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("expected a file path")
+	}
+	path := fs.Arg(0)
+
+	closeStore, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer closeStore()
+
+	if name == "export" {
+		snapshot, err := utils.ExportState(cfg.NodeType)
+		if err != nil {
+			return err
+		}
+		data, err := json.MarshalIndent(snapshot, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to encode state: %v", err)
+		}
+		if err := os.WriteFile(path, data, 0600); err != nil {
+			return fmt.Errorf("failed to write %s: %v", path, err)
+		}
+		fmt.Printf("Exported %d buckets of the %s node to %s\n", len(snapshot.Buckets), cfg.NodeType, path)
+		return nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", path, err)
+	}
+	var snapshot utils.StateSnapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return fmt.Errorf("failed to decode %s: %v", path, err)
+	}
+	if snapshot.NodeType != cfg.NodeType && !*force {
+		return fmt.Errorf("snapshot was taken from a %s node, use -force to import it into the %s node", snapshot.NodeType, cfg.NodeType)
+	}
+	if err := utils.ImportState(&snapshot); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d buckets into the %s node\n", len(snapshot.Buckets), cfg.NodeType)
+	return nil
+}
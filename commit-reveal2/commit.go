@@ -1,49 +1,94 @@
 package commitreveal2
 
 import (
+	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"io"
 	"log"
 	"math/big"
-	"time"
+	"slices"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokamak-network/DRB-node/sensitive"
+	"golang.org/x/crypto/hkdf"
 	"golang.org/x/crypto/sha3"
 )
 
-// GenerateCommit generates the secret value, cos, and cvs for a single regular node.
-func GenerateCommit(round string, operator string) ([32]byte, [32]byte, [32]byte, error) {
-	// Current timestamp to simulate block.timestamp in Solidity
-	timestamp := big.NewInt(time.Now().Unix())
+// MinSecretSeedLength is the minimum length of an operator master seed.
+const MinSecretSeedLength = 32
+
+// secretSeedInfo separates the secrets derived from a seed from any other key
+// material derived from it.
+const secretSeedInfo = "DRB-node secret value v1"
+
+// SecretSeed derives the secret value of every round from an operator master
+// seed with HKDF-SHA256, so a node that lost its commits can regenerate them.
+// The chain ID, contract, operator and round are bound into each secret.
+type SecretSeed struct {
+	seed     []byte
+	chainID  *big.Int
+	contract common.Address
+}
+
+// NewSecretSeed returns a secret seed for the DRB contract on chainID.
+func NewSecretSeed(seed []byte, chainID *big.Int, contract common.Address) (*SecretSeed, error) {
+	if len(seed) < MinSecretSeedLength {
+		return nil, fmt.Errorf("secret seed must be at least %d bytes, got %d", MinSecretSeedLength, len(seed))
+	}
+	return &SecretSeed{seed: common.CopyBytes(seed), chainID: new(big.Int).Set(chainID), contract: contract}, nil
+}
+
+// Secret derives the secret value of operator for a round.
+func (s *SecretSeed) Secret(round *big.Int, operator common.Address) ([32]byte, error) {
+	info := slices.Concat([]byte(secretSeedInfo), common.BigToHash(s.chainID).Bytes(), s.contract.Bytes(), operator.Bytes(), common.BigToHash(round).Bytes())
+
+	var secret [32]byte
+	if _, err := io.ReadFull(hkdf.New(sha256.New, s.seed, nil, info), secret[:]); err != nil {
+		return [32]byte{}, fmt.Errorf("failed to derive secret value: %v", err)
+	}
+	return secret, nil
+}
 
+// GenerateCommit generates the secret value, cos, and cvs for a single regular node.
+// The secret value is derived from seed, or drawn from crypto/rand when seed is nil.
+func GenerateCommit(round string, operator string, seed *SecretSeed) (sensitive.Bytes32, sensitive.Bytes32, [32]byte, error) {
 	// Convert round to big.Int
 	roundInt := new(big.Int)
 	_, ok := roundInt.SetString(round, 10)
 	if !ok {
-		return [32]byte{}, [32]byte{}, [32]byte{}, fmt.Errorf("invalid round: %s", round)
+		return sensitive.Bytes32{}, sensitive.Bytes32{}, [32]byte{}, fmt.Errorf("invalid round: %s", round)
 	}
 
-	// Convert operator to Ethereum address
-	operatorAddress := common.HexToAddress(operator)
-
-	// Generate secret value using keccak256(abi.encodePacked(round, operator, timestamp))
-	secretValue := Keccak256(abiEncodePacked(intToBytes(roundInt), operatorAddress.Bytes(), intToBytes(timestamp)))
+	var secretValueBytes32 sensitive.Bytes32
+	if seed != nil {
+		secret, err := seed.Secret(roundInt, common.HexToAddress(operator))
+		if err != nil {
+			return sensitive.Bytes32{}, sensitive.Bytes32{}, [32]byte{}, err
+		}
+		secretValueBytes32 = secret
+		sensitive.Zero(secret[:])
+	} else if _, err := rand.Read(secretValueBytes32[:]); err != nil {
+		return sensitive.Bytes32{}, sensitive.Bytes32{}, [32]byte{}, fmt.Errorf("failed to generate secret value: %v", err)
+	}
 
-	// Generate cos by hashing the secretValue using abi.encode
-	cos := Keccak256(abiEncode(secretValue))
+	// Generate cos by hashing the secretValue; abi.encode of a bytes32 is the value itself
+	cos := Keccak256(secretValueBytes32[:])
 
-	// Generate cvs by hashing the cos using abi.encode
-	cvs := Keccak256(abiEncode(cos))
+	// Generate cvs by hashing the cos
+	cvs := Keccak256(cos)
 
 	// Convert results into [32]byte format (Solidity's bytes32)
-	var secretValueBytes32, cosBytes32, cvsBytes32 [32]byte
-	copy(secretValueBytes32[:], secretValue)
+	var cosBytes32 sensitive.Bytes32
+	var cvsBytes32 [32]byte
 	copy(cosBytes32[:], cos)
 	copy(cvsBytes32[:], cvs)
+	sensitive.Zero(cos)
 
-	// Print results
-	log.Printf("Secret Value (bytes32): 0x%s", hex.EncodeToString(secretValue))
-	log.Printf("COS (bytes32): 0x%s", hex.EncodeToString(cos))
+	// Print results; the secret value and COS are redacted unless revealed for debugging
+	log.Printf("Secret Value (bytes32): %v", secretValueBytes32)
+	log.Printf("COS (bytes32): %v", cosBytes32)
 	log.Printf("CVS (bytes32): 0x%s", hex.EncodeToString(cvs))
 
 	return secretValueBytes32, cosBytes32, cvsBytes32, nil
@@ -55,26 +100,3 @@ func Keccak256(data []byte) []byte {
 	hash.Write(data)
 	return hash.Sum(nil)
 }
-
-// abiEncode replicates Solidity's abi.encode behavior with 32-byte padding.
-func abiEncode(elements ...[]byte) []byte {
-	var encoded []byte
-	for _, e := range elements {
-		encoded = append(encoded, common.LeftPadBytes(e, 32)...)
-	}
-	return encoded
-}
-
-// abiEncodePacked replicates Solidity's abi.encodePacked behavior.
-func abiEncodePacked(elements ...[]byte) []byte {
-	var packed []byte
-	for _, e := range elements {
-		packed = append(packed, e...)
-	}
-	return packed
-}
-
-// intToBytes converts a *big.Int to its padded big-endian byte representation.
-func intToBytes(n *big.Int) []byte {
-	return common.LeftPadBytes(n.Bytes(), 32)
-}
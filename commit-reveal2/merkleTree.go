@@ -4,12 +4,31 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/ethereum/go-ethereum/common"
 	"golang.org/x/crypto/sha3"
 )
 
-// CreateMerkleTree generates a Merkle root using the provided leaves.
-// The leaves are expected to be byte slices of length 32 (bytes32 in Solidity).
-func CreateMerkleTree(leaves [][]byte) ([]byte, error) {
+// MerkleTree is the Merkle tree the DRB contract builds over the CVS values of
+// a round. The contract hashes a queue: the leaves, followed by every hash it
+// produces, are consumed two at a time until a single root remains. Node i of
+// the queue is therefore hashed with node i^1 into node leafCount+i/2.
+type MerkleTree struct {
+	nodes     []common.Hash // the leaves, then the hashes in the order they are computed
+	leafCount int
+}
+
+// MerkleProofStep is one sibling on the path from a leaf to the root.
+type MerkleProofStep struct {
+	Sibling common.Hash `json:"sibling"`
+	Left    bool        `json:"left"` // the sibling is the first input of the hash
+}
+
+// MerkleProof proves that a leaf is included in a Merkle root.
+type MerkleProof []MerkleProofStep
+
+// NewMerkleTree builds the Merkle tree of leaves, each 32 bytes as bytes32 in
+// Solidity; leaves is not modified. Like the contract, it needs two leaves.
+func NewMerkleTree(leaves [][]byte) (*MerkleTree, error) {
 	leavesLen := len(leaves)
 
 	// Ensure there are at least two leaves
@@ -17,51 +36,69 @@ func CreateMerkleTree(leaves [][]byte) ([]byte, error) {
 		return nil, errors.New("not enough leaves to generate a Merkle root")
 	}
 
-	// Ensure all leaves are padded to 32 bytes (mimicking bytes32 in Solidity)
-	for i := range leaves {
-		if len(leaves[i]) != 32 {
-			paddedLeaf := make([]byte, 32)
-			copy(paddedLeaf, leaves[i])
-			leaves[i] = paddedLeaf
+	t := &MerkleTree{nodes: make([]common.Hash, 2*leavesLen-1), leafCount: leavesLen}
+	for i, leaf := range leaves {
+		if len(leaf) != common.HashLength {
+			return nil, fmt.Errorf("leaf %d is %d bytes, expected %d", i, len(leaf), common.HashLength)
 		}
+		copy(t.nodes[i][:], leaf)
 	}
+	for i := 0; i < leavesLen-1; i++ {
+		t.nodes[leavesLen+i] = common.BytesToHash(efficientKeccak256(t.nodes[2*i][:], t.nodes[2*i+1][:]))
+	}
+	return t, nil
+}
 
-	// Calculate the total number of hashes needed
-	hashCount := leavesLen - 1
-	hashes := make([][]byte, hashCount)
-
-	leafPos := 0
-	hashPos := 0
+// CreateMerkleTree generates a Merkle root using the provided leaves.
+// The leaves must be byte slices of length 32 (bytes32 in Solidity).
+func CreateMerkleTree(leaves [][]byte) ([]byte, error) {
+	t, err := NewMerkleTree(leaves)
+	if err != nil {
+		return nil, err
+	}
+	root := t.Root()
+	return root[:], nil
+}
 
-	for i := 0; i < hashCount; i++ {
-		var a, b []byte
+// Root returns the Merkle root.
+func (t *MerkleTree) Root() common.Hash {
+	return t.nodes[len(t.nodes)-1]
+}
 
-		// Assign 'a' and 'b' based on the current position in leaves or hashes
-		if leafPos < leavesLen {
-			a = leaves[leafPos]
-			leafPos++
-		} else {
-			a = hashes[hashPos]
-			hashPos++
-		}
+// LeafCount returns the number of leaves.
+func (t *MerkleTree) LeafCount() int {
+	return t.leafCount
+}
 
-		if leafPos < leavesLen {
-			b = leaves[leafPos]
-			leafPos++
-		} else {
-			b = hashes[hashPos]
-			hashPos++
-		}
+// Leaf returns the leaf at index.
+func (t *MerkleTree) Leaf(index int) common.Hash {
+	return t.nodes[index]
+}
 
-		// Compute the hash for the pair (a, b)
-		hashes[i] = efficientKeccak256(a, b)
+// Proof returns the inclusion proof of the leaf at index.
+func (t *MerkleTree) Proof(index int) (MerkleProof, error) {
+	if index < 0 || index >= t.leafCount {
+		return nil, fmt.Errorf("leaf index %d out of range, the tree has %d leaves", index, t.leafCount)
 	}
 
-	// The last element in the hashes array is the Merkle root
-	merkleRoot := hashes[hashCount-1]
-	fmt.Printf("Merkle Root: 0x%x\n", merkleRoot)
+	var proof MerkleProof
+	for node := index; node != len(t.nodes)-1; node = t.leafCount + node/2 {
+		proof = append(proof, MerkleProofStep{Sibling: t.nodes[node^1], Left: node%2 == 1})
+	}
+	return proof, nil
+}
 
-	return merkleRoot, nil
+// VerifyMerkleProof reports whether proof shows that leaf is included in root.
+func VerifyMerkleProof(root, leaf common.Hash, proof MerkleProof) bool {
+	hash := leaf[:]
+	for _, step := range proof {
+		if step.Left {
+			hash = efficientKeccak256(step.Sibling[:], hash)
+		} else {
+			hash = efficientKeccak256(hash, step.Sibling[:])
+		}
+	}
+	return common.BytesToHash(hash) == root
 }
 
 // efficientKeccak256 hashes two bytes32 using Keccak256.
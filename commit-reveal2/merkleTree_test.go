@@ -0,0 +1,79 @@
+package commitreveal2
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// contractRoots are the roots getMerkleRoot of the DRB contract returns for
+// the leaves 1, 2, ..., n as bytes32. The contract reverts for a single leaf.
+var contractRoots = map[int]common.Hash{
+	2: common.HexToHash("0xe90b7bceb6e7df5418fb78d8ee546e97c83a08bbccc01a0644d599ccd2a7c2e0"),
+	3: common.HexToHash("0x9b0225f2c6f59eeaf8302811ea290e95258763189b82dc033158e99a6ef45a87"),
+	4: common.HexToHash("0xa9bb8c3f1f12e9aa903a50c47f314b57610a3ab32f2d463293f58836def38d36"),
+	5: common.HexToHash("0xf6c00687a2a50c87101e36eddc215e458f8ca89ee0fb3be978e73e0ea380b768"),
+}
+
+func testLeaves(n int) [][]byte {
+	leaves := make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = make([]byte, common.HashLength)
+		leaves[i][31] = byte(i + 1)
+	}
+	return leaves
+}
+
+func TestMerkleTreeMatchesContract(t *testing.T) {
+	if _, err := NewMerkleTree(testLeaves(1)); err == nil {
+		t.Errorf("a tree of 1 leaf was built, the contract reverts")
+	}
+
+	for n := 2; n <= 5; n++ {
+		leaves := testLeaves(n)
+		tree, err := NewMerkleTree(leaves)
+		if err != nil {
+			t.Fatalf("%d leaves: %v", n, err)
+		}
+		if tree.Root() != contractRoots[n] {
+			t.Errorf("%d leaves: root %s, contract root %s", n, tree.Root().Hex(), contractRoots[n].Hex())
+		}
+		root, err := CreateMerkleTree(leaves)
+		if err != nil || common.BytesToHash(root) != contractRoots[n] {
+			t.Errorf("%d leaves: CreateMerkleTree returned %x, %v", n, root, err)
+		}
+
+		for i := 0; i < n; i++ {
+			proof, err := tree.Proof(i)
+			if err != nil {
+				t.Fatalf("%d leaves: proof of leaf %d: %v", n, i, err)
+			}
+			leaf := common.BytesToHash(leaves[i])
+			if !VerifyMerkleProof(contractRoots[n], leaf, proof) {
+				t.Errorf("%d leaves: proof of leaf %d does not verify", n, i)
+			}
+
+			// The proof must not hold for another leaf or root
+			other := common.BytesToHash(leaves[(i+1)%n])
+			if VerifyMerkleProof(contractRoots[n], other, proof) {
+				t.Errorf("%d leaves: proof of leaf %d verifies leaf %d", n, i, (i+1)%n)
+			}
+			if VerifyMerkleProof(contractRoots[n%4+2], leaf, proof) {
+				t.Errorf("%d leaves: proof of leaf %d verifies another root", n, i)
+			}
+		}
+		if _, err := tree.Proof(n); err == nil {
+			t.Errorf("%d leaves: proof of leaf %d out of range was returned", n, n)
+		}
+	}
+}
+
+func TestMerkleTreeRejectsLeafSize(t *testing.T) {
+	for _, size := range []int{0, 20, 31, 33, 64} {
+		leaves := testLeaves(3)
+		leaves[1] = make([]byte, size)
+		if _, err := NewMerkleTree(leaves); err == nil {
+			t.Errorf("a leaf of %d bytes was accepted", size)
+		}
+	}
+}
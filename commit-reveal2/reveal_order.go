@@ -3,13 +3,12 @@ package commitreveal2
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
-	"os"
 	"sort"
 
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
@@ -26,7 +25,8 @@ func calculateRV(cosValues [][]byte) [32]byte {
 	return rv
 }
 
-// determineOrder calculates the reveal order by comparing COS values with RV
+// determineOrder calculates the reveal order by comparing COS values with RV.
+// Ties keep the order of cosValues, so every node computes the same order.
 func determineOrder(rv [32]byte, cosValues [][]byte) []int {
 	type revealOrderEntry struct {
 		index int
@@ -43,7 +43,7 @@ func determineOrder(rv [32]byte, cosValues [][]byte) []int {
 	}
 
 	// Sort by the difference value
-	sort.Slice(entries, func(i, j int) bool {
+	sort.SliceStable(entries, func(i, j int) bool {
 		return entries[i].value.Cmp(entries[j].value) < 0
 	})
 
@@ -55,74 +55,101 @@ func determineOrder(rv [32]byte, cosValues [][]byte) []int {
 	return order
 }
 
-// saveRevealOrder stores the RV and reveal order in a file
-func saveRevealOrders(filePath string, data map[string]interface{}) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create reveal order file: %v", err)
+// ComputeRevealOrder returns the reveal order of a round whose operators, in
+// on-chain activated-operator order, sent cosValues.
+func ComputeRevealOrder(roundNum string, operators []string, cosValues [][]byte) (*utils.RevealOrder, error) {
+	if len(operators) == 0 {
+		return nil, fmt.Errorf("no operators for round %s", roundNum)
 	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	err = encoder.Encode(data)
-	if err != nil {
-		return fmt.Errorf("failed to write reveal order to file: %v", err)
+	if len(operators) != len(cosValues) {
+		return nil, fmt.Errorf("%d COS values for %d operators", len(cosValues), len(operators))
 	}
 
-	return nil
+	rv := calculateRV(cosValues)
+	revealOrder := determineOrder(rv, cosValues)
+
+	order := &utils.RevealOrder{
+		Round:        roundNum,
+		Operators:    append([]string(nil), operators...),
+		RV:           hex.EncodeToString(rv[:]),
+		Indexes:      revealOrder,
+		OrderedNodes: make([]string, len(operators)),
+	}
+	for _, cos := range cosValues {
+		order.Cos = append(order.Cos, hex.EncodeToString(cos))
+	}
+	// Reorder addresses based on reveal order
+	for i, index := range revealOrder {
+		order.OrderedNodes[i] = operators[index]
+	}
+	return order, nil
 }
 
-func LoadRevealOrders(filePath string) (map[string]interface{}, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			// Return an empty map if the file doesn't exist
-			return make(map[string]interface{}), nil
+// VerifyRevealOrder recomputes a reveal order from its operators and COS values
+// and checks that it matches.
+func VerifyRevealOrder(order *utils.RevealOrder) error {
+	var cosValues [][]byte
+	for i, cosHex := range order.Cos {
+		cos, err := hex.DecodeString(cosHex)
+		if err != nil || len(cos) != 32 {
+			return fmt.Errorf("invalid COS %q at index %d", cosHex, i)
 		}
-		return nil, fmt.Errorf("failed to open reveal order file: %v", err)
+		cosValues = append(cosValues, cos)
 	}
-	defer file.Close()
 
-	var data map[string]interface{}
-	err = json.NewDecoder(file).Decode(&data)
+	expected, err := ComputeRevealOrder(order.Round, order.Operators, cosValues)
 	if err != nil {
-		return nil, fmt.Errorf("failed to decode reveal order file: %v", err)
+		return err
 	}
-
-	return data, nil
+	if expected.RV != order.RV {
+		return fmt.Errorf("RV is %s, expected %s", order.RV, expected.RV)
+	}
+	if !expected.Equal(order) {
+		return fmt.Errorf("reveal order is %v, expected %v", order.OrderedNodes, expected.OrderedNodes)
+	}
+	return nil
 }
 
-func DetermineRevealOrder(roundNum string, activatedOperators map[string]map[common.Address]bool) error {
-	// File path for reveal order storage
-	filePath := "reveal_orders.json"
-
-	// Load existing data
-	data, err := LoadRevealOrders(filePath)
+// LoadRevealOrders returns every stored reveal order keyed by round number.
+func LoadRevealOrders() (map[string]interface{}, error) {
+	entries, err := utils.DefaultStore().List(utils.BucketRevealOrders)
 	if err != nil {
-		log.Printf("Failed to load existing reveal orders: %v", err)
-		return err
+		return nil, fmt.Errorf("failed to load reveal orders: %v", err)
+	}
+
+	data := make(map[string]interface{}, len(entries))
+	for round, raw := range entries {
+		var order interface{}
+		if err := json.Unmarshal(raw, &order); err != nil {
+			return nil, fmt.Errorf("failed to decode reveal order for round %s: %v", round, err)
+		}
+		data[round] = order
 	}
 
+	return data, nil
+}
+
+// DetermineRevealOrder computes and stores the reveal order of a round from the
+// COS values of its operators, given in on-chain activated-operator order.
+func DetermineRevealOrder(roundNum string, operators []string) error {
 	// Check if the round already exists
-	if _, exists := data[roundNum]; exists {
+	if _, err := utils.LoadRevealOrder(roundNum); err == nil {
 		log.Printf("Reveal order already exists for round %s. Skipping calculation.", roundNum)
 		return nil
+	} else if !errors.Is(err, utils.ErrNotFound) {
+		log.Printf("Failed to load existing reveal order: %v", err)
+		return err
 	}
 
 	log.Printf("Determining reveal order for round %s...", roundNum)
 
-	// Ensure activated operators exist for the round
-	operators, exists := activatedOperators[roundNum]
-	if !exists || len(operators) == 0 {
+	if len(operators) == 0 {
 		log.Printf("No activated operators found for round %s", roundNum)
 		return fmt.Errorf("no activated operators found for round %s", roundNum)
 	}
 
 	var cosValues [][]byte
-	var addresses []string
-	for eoaAddress := range operators {
-		eoaAddressStr := eoaAddress.Hex()
-
+	for _, eoaAddressStr := range operators {
 		commitData, err := utils.LoadLeaderCommitData(roundNum, eoaAddressStr)
 		if err != nil {
 			log.Printf("Failed to load COS for operator %s in round %s: %v", eoaAddressStr, roundNum, err)
@@ -135,29 +162,16 @@ func DetermineRevealOrder(roundNum string, activatedOperators map[string]map[com
 		}
 
 		cosValues = append(cosValues, commitData.Cos[:])
-		addresses = append(addresses, eoaAddressStr)
 	}
 
 	// Calculate the RV and determine the reveal order
-	rv := calculateRV(cosValues)
-	revealOrder := determineOrder(rv, cosValues)
-
-	// Reorder addresses based on reveal order
-	orderedAddresses := make([]string, len(addresses))
-	for i, index := range revealOrder {
-		orderedAddresses[i] = addresses[index]
-	}
-
-	// Add the new reveal order to the data map
-	data[roundNum] = map[string]interface{}{
-		"rv":            hex.EncodeToString(rv[:]),
-		"reveal_order":  revealOrder,
-		"ordered_nodes": orderedAddresses, // Save addresses in reveal order
+	order, err := ComputeRevealOrder(roundNum, operators, cosValues)
+	if err != nil {
+		return err
 	}
 
-	// Save the updated data back to the file
-	err = saveRevealOrders(filePath, data)
-	if err != nil {
+	// Store the new reveal order for the round
+	if err := utils.DefaultStore().Put(utils.BucketRevealOrders, roundNum, order); err != nil {
 		log.Printf("Failed to save reveal order for round %s: %v", roundNum, err)
 		return fmt.Errorf("failed to save reveal order for round %s", roundNum)
 	}
@@ -165,4 +179,3 @@ func DetermineRevealOrder(roundNum string, activatedOperators map[string]map[com
 	log.Printf("Reveal order determined and stored for round %s", roundNum)
 	return nil
 }
-
@@ -0,0 +1,637 @@
+// Package config loads and validates the node configuration. Settings are read
+// from an optional YAML file and then overridden by environment variables, so
+// existing .env based deployments keep working unchanged.
+package config
+
+import (
+	"bytes"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/signer"
+	"gopkg.in/yaml.v3"
+)
+
+// Node types.
+const (
+	NodeTypeLeader  = "leader"
+	NodeTypeRegular = "regular"
+)
+
+// Config is the validated configuration of a node.
+type Config struct {
+	NodeType     string       `yaml:"node_type"`
+	Ethereum     Ethereum     `yaml:"ethereum"`
+	Leader       Leader       `yaml:"leader"`
+	Regular      Regular      `yaml:"regular"`
+	Deadlines    Deadlines    `yaml:"deadlines"`
+	Watcher      Watcher      `yaml:"watcher"`
+	Transactions Transactions `yaml:"transactions"`
+	Storage      Storage      `yaml:"storage"`
+	Identity     Identity     `yaml:"identity"`
+
+	// Signer is the signing key of this node, opened by OpenSigner: the leader
+	// key on a leader node and the operator key on a regular node.
+	Signer signer.Signer `yaml:"-"`
+
+	identityPassphrase      string
+	secretsPassphrase       string
+	legacySecretsPassphrase string
+}
+
+// Ethereum holds the chain and contract settings shared by both node types.
+type Ethereum struct {
+	RPCURL          string `yaml:"rpc_url"`
+	WSURL           string `yaml:"ws_url"`
+	ContractAddress string `yaml:"contract_address"`
+	// ChainID is checked against the RPC endpoint at startup. When it is 0 the
+	// chain ID reported by the endpoint is used.
+	ChainID     uint64 `yaml:"chain_id"`
+	SubgraphURL string `yaml:"subgraph_url"`
+}
+
+// Leader describes the leader node. A leader node listens on Port and signs
+// with Key; a regular node connects to IP, Port and PeerID and expects
+// requests signed by EOA.
+type Leader struct {
+	IP     string `yaml:"ip"`
+	Port   string `yaml:"port"`
+	PeerID string `yaml:"peer_id"`
+	EOA    string `yaml:"eoa"`
+	Key    `yaml:",inline"`
+}
+
+// Regular holds the settings of a regular node.
+type Regular struct {
+	Port string `yaml:"port"`
+	Key  `yaml:",inline"`
+	// SecretSeedFile holds a hex encoded master seed. When set, secret values
+	// are derived from it per round instead of drawn at random, so they can be
+	// regenerated if the stored commits are lost.
+	SecretSeedFile string `yaml:"secret_seed_file"`
+}
+
+// Key selects where a signing key comes from. Exactly one of PrivateKey,
+// Keystore and ExternalSigner must be set.
+type Key struct {
+	// PrivateKey is a raw hex private key. Keystore or ExternalSigner keep the
+	// key off the disk in plain text.
+	PrivateKey string `yaml:"private_key"`
+	// Keystore is the path of an Ethereum V3 keystore file. Its passphrase is
+	// read from PasswordFile, or prompted for when PasswordFile is empty.
+	Keystore     string `yaml:"keystore"`
+	PasswordFile string `yaml:"password_file"`
+	// ExternalSigner is the http(s) URL or IPC socket path of a Clef
+	// compatible signer. Account defaults to the first account it lists.
+	ExternalSigner string `yaml:"external_signer"`
+	Account        string `yaml:"account"`
+}
+
+// Identity holds the protection of the stored libp2p identity.
+type Identity struct {
+	// PasswordFile holds the passphrase that encrypts the libp2p private key.
+	// When empty the keystore passphrase, or with a raw private key the key
+	// itself, is used. It is required with an external signer.
+	PasswordFile string `yaml:"password_file"`
+}
+
+// Deadlines holds how long the leader waits in each round phase. Zero
+// disables a deadline.
+type Deadlines struct {
+	Commit time.Duration `yaml:"commit"`
+	Cos    time.Duration `yaml:"cos"`
+	Reveal time.Duration `yaml:"reveal"`
+}
+
+// Watcher holds the round detection settings.
+type Watcher struct {
+	// Source is "chain" (default) to follow contract logs or "subgraph" to
+	// read rounds from the subgraph only.
+	Source        string        `yaml:"source"`
+	Confirmations uint64        `yaml:"confirmations"`
+	StartBlock    uint64        `yaml:"start_block"`
+	PollInterval  time.Duration `yaml:"poll_interval"`
+}
+
+// Transactions holds the transaction manager settings.
+type Transactions struct {
+	Confirmations  uint64        `yaml:"confirmations"`
+	GasLimitMargin uint64        `yaml:"gas_limit_margin"`
+	BumpAfter      time.Duration `yaml:"bump_after"`
+	FeeBumpPercent uint64        `yaml:"fee_bump_percent"`
+	MaxFeeBumps    int           `yaml:"max_fee_bumps"`
+	Timeout        time.Duration `yaml:"timeout"`
+}
+
+// Storage selects the state backend.
+type Storage struct {
+	Backend string `yaml:"backend"`
+	Dir     string `yaml:"dir"`
+	// SecretsPasswordFile holds the passphrase that encrypts the stored
+	// secrets. When empty the passphrase of the libp2p identity is used; with
+	// a raw private key it is required unless identity.password_file is set.
+	SecretsPasswordFile string `yaml:"secrets_password_file"`
+}
+
+// Default returns the configuration used for settings that are not set.
+func Default() *Config {
+	tx := eth.DefaultTxManagerConfig()
+	return &Config{
+		Deadlines: Deadlines{
+			Commit: 10 * time.Minute,
+			Cos:    10 * time.Minute,
+			Reveal: 10 * time.Minute,
+		},
+		Watcher: Watcher{
+			Source:        "chain",
+			Confirmations: 1,
+		},
+		Transactions: Transactions{
+			Confirmations:  tx.Confirmations,
+			GasLimitMargin: tx.GasLimitMargin,
+			BumpAfter:      tx.BumpAfter,
+			FeeBumpPercent: tx.FeeBumpPercent,
+			MaxFeeBumps:    tx.MaxFeeBumps,
+			Timeout:        tx.Timeout,
+		},
+		Storage: Storage{
+			Backend: "bolt",
+		},
+	}
+}
+
+// Load reads the configuration with Read and validates it for running a node.
+func Load(path string) (*Config, error) {
+	cfg, err := Read(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// Read reads the YAML file at path (skipped when path is empty) and applies the
+// environment overrides, without validating the result.
+func Read(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %v", err)
+		}
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// applyEnv overrides the settings with the environment variables that are set.
+func (c *Config) applyEnv() error {
+	str := func(dst *string) func(string) error {
+		return func(v string) error { *dst = v; return nil }
+	}
+	uint64s := func(dst *uint64) func(string) error {
+		return func(v string) (err error) { *dst, err = strconv.ParseUint(v, 10, 64); return }
+	}
+	ints := func(dst *int) func(string) error {
+		return func(v string) (err error) { *dst, err = strconv.Atoi(v); return }
+	}
+	durations := func(dst *time.Duration) func(string) error {
+		return func(v string) (err error) { *dst, err = time.ParseDuration(v); return }
+	}
+
+	overrides := []struct {
+		env string
+		set func(string) error
+	}{
+		{"NODE_TYPE", str(&c.NodeType)},
+		{"ETH_RPC_URL", str(&c.Ethereum.RPCURL)},
+		{"ETH_WS_URL", str(&c.Ethereum.WSURL)},
+		{"CONTRACT_ADDRESS", str(&c.Ethereum.ContractAddress)},
+		{"CHAIN_ID", uint64s(&c.Ethereum.ChainID)},
+		{"SUBGRAPH_URL", str(&c.Ethereum.SubgraphURL)},
+		{"LEADER_IP", str(&c.Leader.IP)},
+		{"LEADER_PORT", str(&c.Leader.Port)},
+		{"LEADER_PEER_ID", str(&c.Leader.PeerID)},
+		{"LEADER_EOA", str(&c.Leader.EOA)},
+		{"LEADER_PRIVATE_KEY", str(&c.Leader.PrivateKey)},
+		{"LEADER_KEYSTORE", str(&c.Leader.Keystore)},
+		{"LEADER_PASSWORD_FILE", str(&c.Leader.PasswordFile)},
+		{"LEADER_EXTERNAL_SIGNER", str(&c.Leader.ExternalSigner)},
+		{"LEADER_SIGNER_ACCOUNT", str(&c.Leader.Account)},
+		{"PORT", str(&c.Regular.Port)},
+		{"EOA_PRIVATE_KEY", str(&c.Regular.PrivateKey)},
+		{"EOA_KEYSTORE", str(&c.Regular.Keystore)},
+		{"EOA_PASSWORD_FILE", str(&c.Regular.PasswordFile)},
+		{"EOA_EXTERNAL_SIGNER", str(&c.Regular.ExternalSigner)},
+		{"EOA_SIGNER_ACCOUNT", str(&c.Regular.Account)},
+		{"SECRET_SEED_FILE", str(&c.Regular.SecretSeedFile)},
+		{"LEADER_COMMIT_TIMEOUT", durations(&c.Deadlines.Commit)},
+		{"LEADER_COS_TIMEOUT", durations(&c.Deadlines.Cos)},
+		{"LEADER_REVEAL_TIMEOUT", durations(&c.Deadlines.Reveal)},
+		{"ROUND_SOURCE", str(&c.Watcher.Source)},
+		{"WATCHER_CONFIRMATIONS", uint64s(&c.Watcher.Confirmations)},
+		{"WATCHER_START_BLOCK", uint64s(&c.Watcher.StartBlock)},
+		{"WATCHER_POLL_INTERVAL", durations(&c.Watcher.PollInterval)},
+		{"TX_CONFIRMATIONS", uint64s(&c.Transactions.Confirmations)},
+		{"TX_GAS_LIMIT_MARGIN", uint64s(&c.Transactions.GasLimitMargin)},
+		{"TX_BUMP_AFTER", durations(&c.Transactions.BumpAfter)},
+		{"TX_FEE_BUMP_PERCENT", uint64s(&c.Transactions.FeeBumpPercent)},
+		{"TX_MAX_FEE_BUMPS", ints(&c.Transactions.MaxFeeBumps)},
+		{"TX_TIMEOUT", durations(&c.Transactions.Timeout)},
+		{"STORAGE_BACKEND", str(&c.Storage.Backend)},
+		{"STORAGE_DIR", str(&c.Storage.Dir)},
+		{"SECRETS_PASSWORD_FILE", str(&c.Storage.SecretsPasswordFile)},
+		{"LIBP2P_KEY_PASSWORD_FILE", str(&c.Identity.PasswordFile)},
+	}
+
+	for _, o := range overrides {
+		v, ok := os.LookupEnv(o.env)
+		if !ok || v == "" {
+			continue
+		}
+		if err := o.set(v); err != nil {
+			return fmt.Errorf("invalid %s: %v", o.env, err)
+		}
+	}
+	return nil
+}
+
+// Validate checks every setting needed to run the configured node type. All
+// problems are reported together. The signing key is opened by OpenSigner.
+func (c *Config) Validate() error {
+	return c.validate(true)
+}
+
+// ValidateOperator checks the settings needed to send contract transactions
+// with the node's key, skipping the peer-to-peer and round detection settings.
+func (c *Config) ValidateOperator() error {
+	return c.validate(false)
+}
+
+func (c *Config) validate(network bool) error {
+	var errs []error
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	check(validateURL("ethereum.rpc_url (ETH_RPC_URL)", c.Ethereum.RPCURL, true, "http", "https", "ws", "wss"))
+	check(validateURL("ethereum.ws_url (ETH_WS_URL)", c.Ethereum.WSURL, false, "ws", "wss"))
+	check(validateURL("ethereum.subgraph_url (SUBGRAPH_URL)", c.Ethereum.SubgraphURL, false, "http", "https"))
+	check(validateAddress("ethereum.contract_address (CONTRACT_ADDRESS)", c.Ethereum.ContractAddress, true))
+
+	switch c.Watcher.Source {
+	case "", "chain":
+	case "subgraph":
+		if network && c.Ethereum.SubgraphURL == "" {
+			check(errors.New("ethereum.subgraph_url (SUBGRAPH_URL) is required when the round source is subgraph"))
+		}
+	default:
+		check(fmt.Errorf("watcher.source (ROUND_SOURCE) must be chain or subgraph, got %q", c.Watcher.Source))
+	}
+
+	if c.Transactions.FeeBumpPercent < 10 {
+		check(errors.New("transactions.fee_bump_percent (TX_FEE_BUMP_PERCENT) must be at least 10"))
+	}
+	if c.Transactions.Confirmations == 0 {
+		check(errors.New("transactions.confirmations (TX_CONFIRMATIONS) must be at least 1"))
+	}
+
+	switch c.Storage.Backend {
+	case "", "bolt", "json":
+	default:
+		check(fmt.Errorf("storage.backend (STORAGE_BACKEND) must be bolt or json, got %q", c.Storage.Backend))
+	}
+
+	switch c.NodeType {
+	case NodeTypeLeader:
+		if network {
+			check(validatePort("leader.port (LEADER_PORT)", c.Leader.Port))
+		}
+	case NodeTypeRegular:
+		if network {
+			check(validatePort("regular.port (PORT)", c.Regular.Port))
+			check(validatePort("leader.port (LEADER_PORT)", c.Leader.Port))
+			if net.ParseIP(c.Leader.IP).To4() == nil {
+				check(fmt.Errorf("leader.ip (LEADER_IP) must be an IPv4 address, got %q", c.Leader.IP))
+			}
+			if _, err := peer.Decode(c.Leader.PeerID); err != nil {
+				check(fmt.Errorf("leader.peer_id (LEADER_PEER_ID) is invalid: %v", err))
+			}
+			check(validateAddress("leader.eoa (LEADER_EOA)", c.Leader.EOA, true))
+			if _, err := c.SecretSeed(); err != nil {
+				check(err)
+			}
+		}
+	default:
+		check(fmt.Errorf("node_type (NODE_TYPE) must be leader or regular, got %q", c.NodeType))
+	}
+
+	if key, section, env := c.key(); section != "" {
+		check(key.validate(section, env))
+
+		// A passphrase derived from a raw private key sits next to the
+		// secrets it would encrypt.
+		if network && key.PrivateKey != "" && c.Storage.SecretsPasswordFile == "" && c.Identity.PasswordFile == "" {
+			check(fmt.Errorf("storage.secrets_password_file (SECRETS_PASSWORD_FILE) is required with %s.private_key (%s_PRIVATE_KEY)", section, env))
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// OpenSigner opens the signing key of the configured node type into Signer,
+// prompting for the keystore passphrase when no password file is set. It also
+// resolves the passphrase of the libp2p identity.
+func (c *Config) OpenSigner() error {
+	key, section, env := c.key()
+	if section == "" {
+		return fmt.Errorf("node_type (NODE_TYPE) must be leader or regular, got %q", c.NodeType)
+	}
+	if err := key.validate(section, env); err != nil {
+		return fmt.Errorf("invalid configuration: %w", err)
+	}
+
+	var passphrase, keyPassphrase string
+	switch {
+	case key.ExternalSigner != "":
+		s, err := signer.DialExternal(key.ExternalSigner, key.Account)
+		if err != nil {
+			return err
+		}
+		c.Signer = s
+	case key.Keystore != "":
+		p, err := signer.ReadPassphrase(key.PasswordFile, "Passphrase for "+key.Keystore)
+		if err != nil {
+			return err
+		}
+		s, err := signer.OpenKeystore(key.Keystore, p)
+		if err != nil {
+			return err
+		}
+		c.Signer, passphrase = s, p
+	default:
+		k, err := signer.ParsePrivateKey(key.PrivateKey)
+		if err != nil {
+			return fmt.Errorf("%s.private_key (%s_PRIVATE_KEY) is invalid: %v", section, env, err)
+		}
+		keyPassphrase = hex.EncodeToString(crypto.FromECDSA(k))
+		c.Signer, passphrase = signer.NewKeySigner(k), keyPassphrase
+	}
+
+	if c.Identity.PasswordFile != "" {
+		p, err := signer.ReadPassphrase(c.Identity.PasswordFile, "")
+		if err != nil {
+			return err
+		}
+		passphrase = p
+	}
+	if passphrase == "" {
+		return errors.New("identity.password_file (LIBP2P_KEY_PASSWORD_FILE) is required with an external signer")
+	}
+	c.identityPassphrase = passphrase
+
+	c.secretsPassphrase = passphrase
+	if c.Storage.SecretsPasswordFile != "" {
+		p, err := signer.ReadPassphrase(c.Storage.SecretsPasswordFile, "")
+		if err != nil {
+			return err
+		}
+		c.secretsPassphrase = p
+	}
+
+	// The raw key is stored next to the secrets, so it does not protect them.
+	// Earlier versions used it; it is kept only to encrypt them again.
+	if keyPassphrase != "" {
+		c.legacySecretsPassphrase = keyPassphrase
+		if c.secretsPassphrase == keyPassphrase {
+			c.secretsPassphrase = ""
+		}
+	}
+	return nil
+}
+
+// SecretsPassphrase returns the passphrase that encrypts the stored secrets.
+// It is set by OpenSigner, and empty with a raw private key unless a password
+// file is configured.
+func (c *Config) SecretsPassphrase() string {
+	return c.secretsPassphrase
+}
+
+// LegacySecretsPassphrase returns the passphrase earlier versions derived from
+// a raw private key to encrypt the stored secrets, or "". It is set by
+// OpenSigner.
+func (c *Config) LegacySecretsPassphrase() string {
+	return c.legacySecretsPassphrase
+}
+
+// SecretSeed returns the master seed read from regular.secret_seed_file, or nil
+// when no seed file is set.
+func (c *Config) SecretSeed() ([]byte, error) {
+	if c.Regular.SecretSeedFile == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(c.Regular.SecretSeedFile)
+	if err != nil {
+		return nil, fmt.Errorf("regular.secret_seed_file (SECRET_SEED_FILE) cannot be read: %v", err)
+	}
+	line, _, _ := strings.Cut(string(data), "\n")
+	seed, err := hex.DecodeString(strings.TrimPrefix(strings.TrimSpace(line), "0x"))
+	if err != nil {
+		return nil, fmt.Errorf("regular.secret_seed_file (SECRET_SEED_FILE) must hold a hex encoded seed: %v", err)
+	}
+	if len(seed) < 32 {
+		return nil, fmt.Errorf("regular.secret_seed_file (SECRET_SEED_FILE) must hold at least 32 bytes, got %d", len(seed))
+	}
+	return seed, nil
+}
+
+// IdentityPassphrase returns the passphrase that encrypts the stored libp2p
+// identity. It is set by OpenSigner.
+func (c *Config) IdentityPassphrase() string {
+	return c.identityPassphrase
+}
+
+// CheckChainID verifies that the configured chain ID matches the one reported
+// by the RPC endpoint. An unset chain ID takes the endpoint's value.
+func (c *Config) CheckChainID(rpcChainID *big.Int) error {
+	if c.Ethereum.ChainID == 0 {
+		c.Ethereum.ChainID = rpcChainID.Uint64()
+		return nil
+	}
+	if new(big.Int).SetUint64(c.Ethereum.ChainID).Cmp(rpcChainID) != 0 {
+		return fmt.Errorf("configured chain ID %d does not match chain ID %s of %s", c.Ethereum.ChainID, rpcChainID, c.Ethereum.RPCURL)
+	}
+	return nil
+}
+
+// ChainID returns the chain ID as a big integer.
+func (c *Config) ChainID() *big.Int {
+	return new(big.Int).SetUint64(c.Ethereum.ChainID)
+}
+
+// ContractAddress returns the DRB contract address.
+func (c *Config) ContractAddress() common.Address {
+	return common.HexToAddress(c.Ethereum.ContractAddress)
+}
+
+// Address returns the address of the node's signing key.
+func (c *Config) Address() common.Address {
+	return c.Signer.Address()
+}
+
+// ListenPort returns the port the node's libp2p host listens on.
+func (c *Config) ListenPort() string {
+	if c.NodeType == NodeTypeLeader {
+		return c.Leader.Port
+	}
+	return c.Regular.Port
+}
+
+// TxManagerConfig converts the transaction settings for eth.NewTxManager.
+func (t Transactions) TxManagerConfig() eth.TxManagerConfig {
+	cfg := eth.DefaultTxManagerConfig()
+	cfg.Confirmations = t.Confirmations
+	cfg.GasLimitMargin = t.GasLimitMargin
+	cfg.BumpAfter = t.BumpAfter
+	cfg.FeeBumpPercent = t.FeeBumpPercent
+	cfg.MaxFeeBumps = t.MaxFeeBumps
+	cfg.Timeout = t.Timeout
+	return cfg
+}
+
+// WatcherConfig converts the round detection settings for eth.NewRoundWatcher.
+func (c *Config) WatcherConfig() eth.WatcherConfig {
+	return eth.WatcherConfig{
+		WSURL:         c.Ethereum.WSURL,
+		Confirmations: c.Watcher.Confirmations,
+		StartBlock:    c.Watcher.StartBlock,
+		PollInterval:  c.Watcher.PollInterval,
+		StateKey:      c.NodeType,
+	}
+}
+
+// key returns the key settings of the configured node type with the name of
+// their config section and environment prefix.
+func (c *Config) key() (Key, string, string) {
+	switch c.NodeType {
+	case NodeTypeLeader:
+		return c.Leader.Key, "leader", "LEADER"
+	case NodeTypeRegular:
+		return c.Regular.Key, "regular", "EOA"
+	}
+	return Key{}, "", ""
+}
+
+func (k Key) validate(section, env string) error {
+	sources := 0
+	for _, source := range []string{k.PrivateKey, k.Keystore, k.ExternalSigner} {
+		if source != "" {
+			sources++
+		}
+	}
+	names := fmt.Sprintf("%[1]s.private_key (%[2]s_PRIVATE_KEY), %[1]s.keystore (%[2]s_KEYSTORE) or %[1]s.external_signer (%[2]s_EXTERNAL_SIGNER)", section, env)
+	switch {
+	case sources == 0:
+		return fmt.Errorf("one of %s is required", names)
+	case sources > 1:
+		return fmt.Errorf("only one of %s may be set", names)
+	}
+
+	var errs []error
+	switch {
+	case k.PrivateKey != "":
+		if _, err := signer.ParsePrivateKey(k.PrivateKey); err != nil {
+			errs = append(errs, fmt.Errorf("%s.private_key (%s_PRIVATE_KEY) is invalid: %v", section, env, err))
+		}
+	case k.Keystore != "":
+		if _, err := os.Stat(k.Keystore); err != nil {
+			errs = append(errs, fmt.Errorf("%s.keystore (%s_KEYSTORE) is not readable: %v", section, env, err))
+		}
+		if k.PasswordFile != "" {
+			if _, err := os.Stat(k.PasswordFile); err != nil {
+				errs = append(errs, fmt.Errorf("%s.password_file (%s_PASSWORD_FILE) is not readable: %v", section, env, err))
+			}
+		}
+	default:
+		if strings.Contains(k.ExternalSigner, "://") {
+			errs = append(errs, validateURL(fmt.Sprintf("%s.external_signer (%s_EXTERNAL_SIGNER)", section, env), k.ExternalSigner, true, "http", "https", "ws", "wss"))
+		}
+		errs = append(errs, validateAddress(fmt.Sprintf("%s.account (%s_SIGNER_ACCOUNT)", section, env), k.Account, false))
+	}
+	return errors.Join(errs...)
+}
+
+func validateURL(name, raw string, required bool, schemes ...string) error {
+	if raw == "" {
+		if required {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("%s is invalid: %v", name, err)
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme && u.Host != "" {
+			return nil
+		}
+	}
+	return fmt.Errorf("%s must be a %v URL, got %q", name, schemes, raw)
+}
+
+func validateAddress(name, raw string, required bool) error {
+	if raw == "" {
+		if required {
+			return fmt.Errorf("%s is required", name)
+		}
+		return nil
+	}
+	if !common.IsHexAddress(raw) {
+		return fmt.Errorf("%s is not a valid address: %q", name, raw)
+	}
+	if common.HexToAddress(raw) == (common.Address{}) {
+		return fmt.Errorf("%s must not be the zero address", name)
+	}
+	return nil
+}
+
+func validatePort(name, raw string) error {
+	if raw == "" {
+		return fmt.Errorf("%s is required", name)
+	}
+	port, err := strconv.Atoi(raw)
+	if err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("%s must be a port between 1 and 65535, got %q", name, raw)
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Package eip712 builds, hashes and verifies the EIP-712 typed data of the DRB
+// contract, in the domain the contract reports through EIP-5267.
+package eip712
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Bits of the EIP-5267 fields value, one for each domain field in use.
+const (
+	FieldName byte = 1 << iota
+	FieldVersion
+	FieldChainID
+	FieldVerifyingContract
+	FieldSalt
+)
+
+// Domain is an EIP-712 domain as returned by eip712Domain(). Only the fields
+// set in Fields are part of the domain separator.
+type Domain struct {
+	Fields            byte
+	Name              string
+	Version           string
+	ChainID           *big.Int
+	VerifyingContract common.Address
+	Salt              common.Hash
+}
+
+// NewDomain returns the domain described by the values of eip712Domain().
+// Domains with extensions are not supported.
+func NewDomain(fields byte, name, version string, chainID *big.Int, verifyingContract common.Address, salt [32]byte, extensions []*big.Int) (Domain, error) {
+	if fields&^(FieldName|FieldVersion|FieldChainID|FieldVerifyingContract|FieldSalt) != 0 {
+		return Domain{}, fmt.Errorf("unknown EIP-712 domain fields 0x%02x", fields)
+	}
+	if len(extensions) != 0 {
+		return Domain{}, errors.New("EIP-712 domain extensions are not supported")
+	}
+	d := Domain{
+		Fields:            fields,
+		Name:              name,
+		Version:           version,
+		VerifyingContract: verifyingContract,
+		Salt:              salt,
+	}
+	if chainID != nil {
+		d.ChainID = new(big.Int).Set(chainID)
+	}
+	return d, nil
+}
+
+// Check verifies that the domain binds signatures to the contract at
+// contractAddress on chainID.
+func (d Domain) Check(chainID *big.Int, contractAddress common.Address) error {
+	if d.Fields&FieldChainID == 0 || d.ChainID == nil {
+		return errors.New("EIP-712 domain has no chain ID")
+	}
+	if d.ChainID.Cmp(chainID) != 0 {
+		return fmt.Errorf("EIP-712 domain chain ID %s does not match chain ID %s", d.ChainID, chainID)
+	}
+	if d.Fields&FieldVerifyingContract == 0 || d.VerifyingContract != contractAddress {
+		return fmt.Errorf("EIP-712 domain verifying contract %s is not %s", d.VerifyingContract.Hex(), contractAddress.Hex())
+	}
+	return nil
+}
+
+// String describes the domain for logs.
+func (d Domain) String() string {
+	return fmt.Sprintf("%q version %q on chain %v at %s", d.Name, d.Version, d.ChainID, d.VerifyingContract.Hex())
+}
+
+// TypedData returns typed data of primaryType in the domain. types holds the
+// message types without EIP712Domain. Values are strings so the typed data can
+// also be sent to an external signer.
+func (d Domain) TypedData(primaryType string, types apitypes.Types, message apitypes.TypedDataMessage) apitypes.TypedData {
+	all := apitypes.Types{"EIP712Domain": d.types()}
+	for name, fields := range types {
+		all[name] = fields
+	}
+	return apitypes.TypedData{
+		Types:       all,
+		PrimaryType: primaryType,
+		Domain:      d.typedDataDomain(),
+		Message:     message,
+	}
+}
+
+// Hash returns the EIP-712 hash of typedData, the hash that is signed.
+func Hash(typedData apitypes.TypedData) (common.Hash, error) {
+	hash, _, err := apitypes.TypedDataAndHash(typedData)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to hash typed data: %v", err)
+	}
+	return common.BytesToHash(hash), nil
+}
+
+// types lists the EIP712Domain fields in use, in the order of EIP-712.
+func (d Domain) types() []apitypes.Type {
+	var types []apitypes.Type
+	if d.Fields&FieldName != 0 {
+		types = append(types, apitypes.Type{Name: "name", Type: "string"})
+	}
+	if d.Fields&FieldVersion != 0 {
+		types = append(types, apitypes.Type{Name: "version", Type: "string"})
+	}
+	if d.Fields&FieldChainID != 0 {
+		types = append(types, apitypes.Type{Name: "chainId", Type: "uint256"})
+	}
+	if d.Fields&FieldVerifyingContract != 0 {
+		types = append(types, apitypes.Type{Name: "verifyingContract", Type: "address"})
+	}
+	if d.Fields&FieldSalt != 0 {
+		types = append(types, apitypes.Type{Name: "salt", Type: "bytes32"})
+	}
+	return types
+}
+
+func (d Domain) typedDataDomain() apitypes.TypedDataDomain {
+	var domain apitypes.TypedDataDomain
+	if d.Fields&FieldName != 0 {
+		domain.Name = d.Name
+	}
+	if d.Fields&FieldVersion != 0 {
+		domain.Version = d.Version
+	}
+	if d.Fields&FieldChainID != 0 && d.ChainID != nil {
+		domain.ChainId = (*math.HexOrDecimal256)(new(big.Int).Set(d.ChainID))
+	}
+	if d.Fields&FieldVerifyingContract != 0 {
+		domain.VerifyingContract = d.VerifyingContract.Hex()
+	}
+	if d.Fields&FieldSalt != 0 {
+		domain.Salt = d.Salt.Hex()
+	}
+	return domain
+}
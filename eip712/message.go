@@ -0,0 +1,124 @@
+package eip712
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// MessageTypes are the types of Message(uint256 round,bytes32 cv), which an
+// operator signs over the CVS it commits to in a round.
+var MessageTypes = apitypes.Types{
+	"Message": {
+		{Name: "round", Type: "uint256"},
+		{Name: "cv", Type: "bytes32"},
+	},
+}
+
+// Message returns the typed data of the Message over cv for a round.
+func (d Domain) Message(round *big.Int, cv [32]byte) apitypes.TypedData {
+	return d.TypedData("Message", MessageTypes, apitypes.TypedDataMessage{
+		"round": round.String(),
+		"cv":    hexutil.Encode(cv[:]),
+	})
+}
+
+// MessageHash returns the EIP-712 hash of the Message over cv for a round.
+func (d Domain) MessageHash(round *big.Int, cv [32]byte) (common.Hash, error) {
+	return Hash(d.Message(round, cv))
+}
+
+// Signature is an EIP-712 signature split as the contract takes it, with V
+// 27 or 28.
+type Signature struct {
+	V uint8
+	R common.Hash
+	S common.Hash
+}
+
+// SplitSignature splits a 65 byte signature with V 27 or 28.
+func SplitSignature(signature []byte) (Signature, error) {
+	if len(signature) != crypto.SignatureLength {
+		return Signature{}, fmt.Errorf("signature is %d bytes, expected %d", len(signature), crypto.SignatureLength)
+	}
+	return Signature{
+		V: signature[64],
+		R: common.BytesToHash(signature[:32]),
+		S: common.BytesToHash(signature[32:64]),
+	}, nil
+}
+
+// ParseSignature decodes a signature stored as strings: v in decimal, r and s
+// in hex with or without 0x prefix.
+func ParseSignature(v, r, s string) (Signature, error) {
+	if v == "" || r == "" || s == "" {
+		return Signature{}, errors.New("incomplete signature")
+	}
+	vValue, err := strconv.ParseUint(v, 10, 8)
+	if err != nil {
+		return Signature{}, fmt.Errorf("invalid v value %q", v)
+	}
+	rValue, err := parseHash(r)
+	if err != nil {
+		return Signature{}, fmt.Errorf("invalid r value: %v", err)
+	}
+	sValue, err := parseHash(s)
+	if err != nil {
+		return Signature{}, fmt.Errorf("invalid s value: %v", err)
+	}
+	return Signature{V: uint8(vValue), R: rValue, S: sValue}, nil
+}
+
+// RecoverMessageSigner returns the address that signed the Message over cv for
+// a round. Signatures the contract would reject, such as malleable ones with a
+// high s value, are rejected too.
+func (d Domain) RecoverMessageSigner(round *big.Int, cv [32]byte, sig Signature) (common.Address, error) {
+	if sig.V != 27 && sig.V != 28 {
+		return common.Address{}, fmt.Errorf("invalid v value %d, expected 27 or 28", sig.V)
+	}
+	if !crypto.ValidateSignatureValues(sig.V-27, sig.R.Big(), sig.S.Big(), true) {
+		return common.Address{}, errors.New("invalid r or s value")
+	}
+
+	hash, err := d.MessageHash(round, cv)
+	if err != nil {
+		return common.Address{}, err
+	}
+	signature := append(append(sig.R.Bytes(), sig.S.Bytes()...), sig.V-27)
+	pubKey, err := crypto.SigToPub(hash.Bytes(), signature)
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to recover signer: %v", err)
+	}
+	return crypto.PubkeyToAddress(*pubKey), nil
+}
+
+// VerifyMessage checks that signer signed the Message over cv for a round.
+func (d Domain) VerifyMessage(round *big.Int, cv [32]byte, sig Signature, signer common.Address) error {
+	recovered, err := d.RecoverMessageSigner(round, cv, sig)
+	if err != nil {
+		return err
+	}
+	if recovered != signer {
+		return fmt.Errorf("signed by %s, not by %s", recovered.Hex(), signer.Hex())
+	}
+	return nil
+}
+
+// parseHash decodes a 32 byte hex value with or without 0x prefix.
+func parseHash(value string) (common.Hash, error) {
+	b, err := hexutil.Decode("0x" + strings.TrimPrefix(value, "0x"))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	if len(b) != common.HashLength {
+		return common.Hash{}, fmt.Errorf("expected %d bytes, got %d", common.HashLength, len(b))
+	}
+	return common.BytesToHash(b), nil
+}
@@ -0,0 +1,273 @@
+package eth
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+	contract "github.com/tokamak-network/DRB-node/contract/Commit2RevealDRB"
+	"github.com/tokamak-network/DRB-node/eip712"
+	"github.com/tokamak-network/DRB-node/logger"
+	"github.com/tokamak-network/DRB-node/signer"
+)
+
+// RoundInfo is the on-chain s_roundInfo entry of a round.
+type RoundInfo struct {
+	MerkleRoot       common.Hash
+	RandomNumber     *big.Int
+	FulfillSucceeded bool
+}
+
+// DRBContract is a typed client for the Commit2RevealDRB contract. One client
+// is created per process and shared by everything that talks to the contract.
+type DRBContract struct {
+	client   *ethclient.Client
+	address  common.Address
+	contract *contract.Contract
+	from     common.Address
+	chainID  *big.Int
+	txm      *TxManager
+
+	domainMu sync.Mutex
+	domain   *eip712.Domain
+}
+
+// DialDRBContract connects to rpcURL and binds the DRB contract at address.
+// Transactions are signed by s and sent through a TxManager using txCfg.
+func DialDRBContract(ctx context.Context, rpcURL string, address common.Address, s signer.Signer, txCfg TxManagerConfig) (*DRBContract, error) {
+	client, err := ethclient.DialContext(ctx, rpcURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to Ethereum client: %v", err)
+	}
+
+	drb, err := NewDRBContract(ctx, client, address, s, txCfg)
+	if err != nil {
+		client.Close()
+		return nil, err
+	}
+	return drb, nil
+}
+
+// NewDRBContract binds the DRB contract at address on an existing client.
+func NewDRBContract(ctx context.Context, client *ethclient.Client, address common.Address, s signer.Signer, txCfg TxManagerConfig) (*DRBContract, error) {
+	bound, err := contract.NewContract(address, client)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind DRB contract: %v", err)
+	}
+
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch chain ID: %v", err)
+	}
+
+	return &DRBContract{
+		client:   client,
+		address:  address,
+		contract: bound,
+		from:     s.Address(),
+		chainID:  chainID,
+		txm:      NewTxManager(client, s, chainID, txCfg),
+	}, nil
+}
+
+// Client returns the underlying Ethereum client.
+func (c *DRBContract) Client() *ethclient.Client {
+	return c.client
+}
+
+// Address returns the contract address.
+func (c *DRBContract) Address() common.Address {
+	return c.address
+}
+
+// From returns the account that signs transactions.
+func (c *DRBContract) From() common.Address {
+	return c.from
+}
+
+// ChainID returns the chain ID reported by the RPC endpoint.
+func (c *DRBContract) ChainID() *big.Int {
+	return new(big.Int).Set(c.chainID)
+}
+
+// Close closes the underlying Ethereum client.
+func (c *DRBContract) Close() {
+	c.client.Close()
+}
+
+// ActivatedOperators returns the currently activated operators. The zero address
+// at index 0 of the contract's list is dropped.
+func (c *DRBContract) ActivatedOperators(ctx context.Context) ([]common.Address, error) {
+	operators, err := c.contract.GetActivatedOperators(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getActivatedOperators: %v", err)
+	}
+	return dropZeroAddresses(operators), nil
+}
+
+// ActivatedOperatorsAtRound returns the operators activated for a round in
+// on-chain order, without the leading zero address.
+func (c *DRBContract) ActivatedOperatorsAtRound(ctx context.Context, round *big.Int) ([]common.Address, error) {
+	operators, err := c.contract.GetActivatedOperatorsAtRound(&bind.CallOpts{Context: ctx}, round)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call getActivatedOperatorsAtRound: %v", err)
+	}
+	return dropZeroAddresses(operators), nil
+}
+
+// DepositAmount returns the deposit of an operator.
+func (c *DRBContract) DepositAmount(ctx context.Context, operator common.Address) (*big.Int, error) {
+	amount, err := c.contract.SDepositAmount(&bind.CallOpts{Context: ctx}, operator)
+	if err != nil {
+		return nil, fmt.Errorf("failed to call s_depositAmount: %v", err)
+	}
+	return amount, nil
+}
+
+// ActivationThreshold returns the deposit an operator needs to be activated.
+func (c *DRBContract) ActivationThreshold(ctx context.Context) (*big.Int, error) {
+	threshold, err := c.contract.SActivationThreshold(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return nil, fmt.Errorf("failed to call s_activationThreshold: %v", err)
+	}
+	return threshold, nil
+}
+
+// Owner returns the owner of the contract.
+func (c *DRBContract) Owner(ctx context.Context) (common.Address, error) {
+	owner, err := c.contract.Owner(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return common.Address{}, fmt.Errorf("failed to call owner: %v", err)
+	}
+	return owner, nil
+}
+
+// RoundInfo returns the s_roundInfo entry of a round.
+func (c *DRBContract) RoundInfo(ctx context.Context, round *big.Int) (RoundInfo, error) {
+	info, err := c.contract.SRoundInfo(&bind.CallOpts{Context: ctx}, round)
+	if err != nil {
+		return RoundInfo{}, fmt.Errorf("failed to call s_roundInfo: %v", err)
+	}
+	return RoundInfo{
+		MerkleRoot:       info.MerkleRoot,
+		RandomNumber:     info.RandomNumber,
+		FulfillSucceeded: info.FulfillSucceeded,
+	}, nil
+}
+
+// MerkleRoot computes the Merkle root of leaves with the contract's getMerkleRoot.
+func (c *DRBContract) MerkleRoot(ctx context.Context, leaves [][32]byte) (common.Hash, error) {
+	root, err := c.contract.GetMerkleRoot(&bind.CallOpts{Context: ctx}, leaves)
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("failed to call getMerkleRoot: %v", err)
+	}
+	return root, nil
+}
+
+// EIP712Domain returns the EIP-712 domain the contract reports with the
+// EIP-5267 eip712Domain(). It is fetched once and cached.
+func (c *DRBContract) EIP712Domain(ctx context.Context) (eip712.Domain, error) {
+	c.domainMu.Lock()
+	defer c.domainMu.Unlock()
+	if c.domain != nil {
+		return *c.domain, nil
+	}
+
+	d, err := c.contract.Eip712Domain(&bind.CallOpts{Context: ctx})
+	if err != nil {
+		return eip712.Domain{}, fmt.Errorf("failed to call eip712Domain: %v", err)
+	}
+	domain, err := eip712.NewDomain(d.Fields[0], d.Name, d.Version, d.ChainId, d.VerifyingContract, d.Salt, d.Extensions)
+	if err != nil {
+		return eip712.Domain{}, err
+	}
+	c.domain = &domain
+	return domain, nil
+}
+
+// Deposit adds amount to the caller's deposit.
+func (c *DRBContract) Deposit(ctx context.Context, amount *big.Int) (*types.Receipt, error) {
+	return c.transact(ctx, "deposit", amount, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Deposit(opts)
+	})
+}
+
+// Activate activates an operator whose deposit reaches the activation threshold.
+func (c *DRBContract) Activate(ctx context.Context, operator common.Address) (*types.Receipt, error) {
+	return c.transact(ctx, "activate", nil, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Activate(opts, operator)
+	})
+}
+
+// Deactivate deactivates an operator.
+func (c *DRBContract) Deactivate(ctx context.Context, operator common.Address) (*types.Receipt, error) {
+	return c.transact(ctx, "deactivate", nil, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Deactivate(opts, operator)
+	})
+}
+
+// Withdraw withdraws amount from the caller's deposit.
+func (c *DRBContract) Withdraw(ctx context.Context, amount *big.Int) (*types.Receipt, error) {
+	return c.transact(ctx, "withdraw", nil, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.Withdraw(opts, amount)
+	})
+}
+
+// SubmitMerkleRoot records the Merkle root of a round's CVS values.
+func (c *DRBContract) SubmitMerkleRoot(ctx context.Context, round *big.Int, merkleRoot [32]byte) (*types.Receipt, error) {
+	return c.transact(ctx, "submitMerkleRoot", nil, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.SubmitMerkleRoot(opts, round, merkleRoot)
+	})
+}
+
+// GenerateRandomNumber reveals the secrets of a round and the signatures over their CVS values.
+func (c *DRBContract) GenerateRandomNumber(ctx context.Context, round *big.Int, secrets [][32]byte, vs []uint8, rs, ss [][32]byte) (*types.Receipt, error) {
+	return c.transact(ctx, "generateRandomNumber", nil, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return c.contract.GenerateRandomNumber(opts, round, secrets, vs, rs, ss)
+	})
+}
+
+// transact packs the call built by send with the generated bindings and hands it
+// to the TxManager, which estimates, prices, sends and confirms it.
+func (c *DRBContract) transact(ctx context.Context, method string, value *big.Int, send func(*bind.TransactOpts) (*types.Transaction, error)) (*types.Receipt, error) {
+	// Nonce, gas and fees are filled in by the TxManager. Setting them here
+	// keeps the bindings from querying the node for values that are discarded.
+	opts := &bind.TransactOpts{
+		From:     c.from,
+		Nonce:    new(big.Int),
+		GasLimit: 1,
+		GasPrice: new(big.Int),
+		Value:    value,
+		Context:  ctx,
+		NoSend:   true,
+		Signer: func(_ common.Address, tx *types.Transaction) (*types.Transaction, error) {
+			return tx, nil
+		},
+	}
+
+	tx, err := send(opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to pack %s: %v", method, err)
+	}
+
+	logger.Log.WithFields(logrus.Fields{
+		"function": method,
+	}).Infof("Preparing to execute %s...", method)
+	return c.txm.Send(ctx, method, c.address, value, tx.Data())
+}
+
+func dropZeroAddresses(addresses []common.Address) []common.Address {
+	var filtered []common.Address
+	for _, addr := range addresses {
+		if addr != (common.Address{}) {
+			filtered = append(filtered, addr)
+		}
+	}
+	return filtered
+}
@@ -0,0 +1,144 @@
+package eth
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	contract "github.com/tokamak-network/DRB-node/contract/Commit2RevealDRB"
+)
+
+// ContractError is a revert decoded with the custom errors of the DRB contract,
+// e.g. MerkleVerificationFailed or RevealNotInAscendingOrder.
+type ContractError struct {
+	Name string
+	Args []interface{}
+}
+
+func (e *ContractError) Error() string {
+	if len(e.Args) == 0 {
+		return fmt.Sprintf("execution reverted: %s()", e.Name)
+	}
+	args := make([]string, len(e.Args))
+	for i, arg := range e.Args {
+		args[i] = fmt.Sprint(arg)
+	}
+	return fmt.Sprintf("execution reverted: %s(%s)", e.Name, strings.Join(args, ", "))
+}
+
+// RevertError is a revert that is not one of the contract's custom errors.
+type RevertError struct {
+	Reason string
+	Data   []byte
+}
+
+func (e *RevertError) Error() string {
+	if e.Reason != "" {
+		return "execution reverted: " + e.Reason
+	}
+	return fmt.Sprintf("execution reverted with data %s", hexutil.Encode(e.Data))
+}
+
+// IsContractError reports whether err is the custom error name of the DRB contract.
+func IsContractError(err error, name string) bool {
+	var contractErr *ContractError
+	return errors.As(err, &contractErr) && contractErr.Name == name
+}
+
+var drbErrors = func() map[string]abi.Error {
+	parsed, err := contract.ContractMetaData.GetAbi()
+	if err != nil {
+		panic(fmt.Sprintf("invalid DRB contract ABI: %v", err))
+	}
+	return parsed.Errors
+}()
+
+// DecodeRevert turns the revert data carried by an RPC error into a
+// ContractError or RevertError. Errors without revert data are returned as is.
+func DecodeRevert(err error) error {
+	if err == nil {
+		return nil
+	}
+	var dataErr rpc.DataError
+	if !errors.As(err, &dataErr) {
+		return err
+	}
+	hexData, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return err
+	}
+	data, decodeErr := hexutil.Decode(hexData)
+	if decodeErr != nil {
+		return err
+	}
+	return decodeRevertData(data)
+}
+
+func decodeRevertData(data []byte) error {
+	if len(data) >= 4 {
+		for _, abiErr := range drbErrors {
+			if !bytes.Equal(data[:4], abiErr.ID[:4]) {
+				continue
+			}
+			values, err := abiErr.Inputs.Unpack(data[4:])
+			if err != nil {
+				break
+			}
+			return &ContractError{Name: abiErr.Name, Args: values}
+		}
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return &RevertError{Reason: reason, Data: data}
+	}
+	return &RevertError{Data: data}
+}
+
+// TxFailure is how the round logic should react to a failed transaction.
+type TxFailure int
+
+const (
+	// TxRetry means the failure may be transient, such as an RPC error, a
+	// timeout or a transaction that was not mined; the call can be sent again.
+	TxRetry TxFailure = iota
+	// TxAbort means the contract rejects the call itself. Sending the same
+	// call again cannot succeed.
+	TxAbort
+)
+
+func (f TxFailure) String() string {
+	if f == TxAbort {
+		return "abort"
+	}
+	return "retry"
+}
+
+// retryableContractErrors are the custom errors of the DRB contract that do not
+// depend on the call itself.
+var retryableContractErrors = map[string]bool{
+	"ReentrancyGuardReentrantCall": true,
+}
+
+// ClassifyTxError decides from the decoded revert of err whether a failed
+// transaction should be retried or its round aborted. Reverts with one of the
+// contract's custom errors, e.g. MerkleVerificationFailed,
+// RevealNotInAscendingOrder, NotEnoughParticipatedOperators or
+// InvalidSignatureS, and other reverts with data abort. Everything else is
+// retried.
+func ClassifyTxError(err error) TxFailure {
+	var contractErr *ContractError
+	if errors.As(err, &contractErr) {
+		if retryableContractErrors[contractErr.Name] {
+			return TxRetry
+		}
+		return TxAbort
+	}
+	var revertErr *RevertError
+	if errors.As(err, &revertErr) {
+		return TxAbort
+	}
+	return TxRetry
+}
@@ -0,0 +1,357 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+	"github.com/tokamak-network/DRB-node/logger"
+	"github.com/tokamak-network/DRB-node/signer"
+)
+
+// TxManagerConfig holds the tunables for a TxManager.
+type TxManagerConfig struct {
+	// GasLimitMargin is added on top of the gas estimate, in percent.
+	GasLimitMargin uint64
+	// Confirmations is the number of blocks, including the one with the
+	// transaction, to wait for before a receipt is returned.
+	Confirmations uint64
+	// Timeout bounds a whole Send when the caller's context has no deadline.
+	Timeout time.Duration
+	// BumpAfter is how long a transaction may stay pending before it is
+	// replaced with higher fees.
+	BumpAfter time.Duration
+	// FeeBumpPercent is the fee increase of each replacement. Nodes require at
+	// least 10%.
+	FeeBumpPercent uint64
+	// MaxFeeBumps limits the number of replacements of a transaction.
+	MaxFeeBumps int
+	// PollInterval is how often receipts are polled.
+	PollInterval time.Duration
+}
+
+// DefaultTxManagerConfig returns the settings used when none are configured.
+func DefaultTxManagerConfig() TxManagerConfig {
+	return TxManagerConfig{
+		GasLimitMargin: 20,
+		Confirmations:  1,
+		Timeout:        10 * time.Minute,
+		BumpAfter:      90 * time.Second,
+		FeeBumpPercent: 15,
+		MaxFeeBumps:    5,
+		PollInterval:   3 * time.Second,
+	}
+}
+
+// TxManager signs and sends transactions for a single account. It keeps the
+// account nonce locally so concurrent sends do not collide, prices
+// transactions with EIP-1559 fees where the chain supports them, and replaces
+// transactions that stay pending for too long.
+type TxManager struct {
+	client  *ethclient.Client
+	signer  signer.Signer
+	from    common.Address
+	chainID *big.Int
+	cfg     TxManagerConfig
+
+	mu          sync.Mutex
+	nonce       uint64
+	nonceLoaded bool
+}
+
+// NewTxManager creates a transaction manager for the account of s.
+func NewTxManager(client *ethclient.Client, s signer.Signer, chainID *big.Int, cfg TxManagerConfig) *TxManager {
+	defaults := DefaultTxManagerConfig()
+	if cfg.Confirmations == 0 {
+		cfg.Confirmations = defaults.Confirmations
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = defaults.Timeout
+	}
+	if cfg.BumpAfter <= 0 {
+		cfg.BumpAfter = defaults.BumpAfter
+	}
+	if cfg.FeeBumpPercent < 10 {
+		cfg.FeeBumpPercent = defaults.FeeBumpPercent
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = defaults.PollInterval
+	}
+
+	return &TxManager{
+		client:  client,
+		signer:  s,
+		from:    s.Address(),
+		chainID: chainID,
+		cfg:     cfg,
+	}
+}
+
+// From returns the sending account.
+func (m *TxManager) From() common.Address {
+	return m.from
+}
+
+// Send simulates, estimates, signs and sends a call to `to`, replaces it with
+// higher fees while it is pending, and waits for the configured confirmations.
+// A call that reverts in the simulation is never signed. Reverts are returned
+// as a ContractError or RevertError where possible; see ClassifyTxError.
+func (m *TxManager) Send(ctx context.Context, method string, to common.Address, value *big.Int, data []byte) (*types.Receipt, error) {
+	log := logger.Log.WithFields(logrus.Fields{
+		"function": method,
+	})
+
+	if _, ok := ctx.Deadline(); !ok {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.cfg.Timeout)
+		defer cancel()
+	}
+	if value == nil {
+		value = new(big.Int)
+	}
+
+	// Simulate the call against the pending state before anything is signed,
+	// so a revert is reported with its decoded reason and costs no gas
+	msg := ethereum.CallMsg{From: m.from, To: &to, Value: value, Data: data}
+	if _, err := m.client.PendingCallContract(ctx, msg); err != nil {
+		err = DecodeRevert(err)
+		log.Errorf("Simulation of %s failed: %v", method, err)
+		return nil, fmt.Errorf("simulation of %s failed: %w", method, err)
+	}
+
+	estimate, err := m.client.EstimateGas(ctx, msg)
+	if err != nil {
+		err = DecodeRevert(err)
+		log.Errorf("Gas estimation failed for %s: %v", method, err)
+		return nil, fmt.Errorf("gas estimation failed for %s: %w", method, err)
+	}
+	gasLimit := estimate + estimate*m.cfg.GasLimitMargin/100
+
+	fees, err := m.suggestFees(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := m.sendNew(ctx, to, value, data, gasLimit, fees)
+	if err != nil {
+		log.Errorf("Failed to send %s: %v", method, err)
+		return nil, fmt.Errorf("failed to send %s: %v", method, err)
+	}
+	log.Infof("Sent %s transaction %s (nonce %d, gas limit %d)", method, tx.Hash().Hex(), tx.Nonce(), gasLimit)
+
+	receipt, err := m.waitMined(ctx, log, tx, fees)
+	if err != nil {
+		return nil, fmt.Errorf("%s transaction %s: %v", method, tx.Hash().Hex(), err)
+	}
+
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		reason := m.revertReason(ctx, msg, receipt)
+		log.Errorf("Transaction %s reverted in block %v: %v", receipt.TxHash.Hex(), receipt.BlockNumber, reason)
+		return receipt, fmt.Errorf("%s transaction %s reverted: %w", method, receipt.TxHash.Hex(), reason)
+	}
+
+	if err := m.waitConfirmations(ctx, receipt); err != nil {
+		return receipt, fmt.Errorf("%s transaction %s: %v", method, receipt.TxHash.Hex(), err)
+	}
+
+	log.Infof("Transaction %s confirmed in block %v", receipt.TxHash.Hex(), receipt.BlockNumber)
+	return receipt, nil
+}
+
+// txFees are the fees of a transaction. GasPrice is set on chains without a
+// base fee, GasTipCap and GasFeeCap otherwise.
+type txFees struct {
+	GasPrice  *big.Int
+	GasTipCap *big.Int
+	GasFeeCap *big.Int
+}
+
+func (m *TxManager) suggestFees(ctx context.Context) (txFees, error) {
+	head, err := m.client.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return txFees{}, fmt.Errorf("failed to fetch latest header: %v", err)
+	}
+
+	if head.BaseFee == nil {
+		gasPrice, err := m.client.SuggestGasPrice(ctx)
+		if err != nil {
+			return txFees{}, fmt.Errorf("failed to suggest gas price: %v", err)
+		}
+		return txFees{GasPrice: gasPrice}, nil
+	}
+
+	tip, err := m.client.SuggestGasTipCap(ctx)
+	if err != nil {
+		return txFees{}, fmt.Errorf("failed to suggest gas tip cap: %v", err)
+	}
+	feeCap := new(big.Int).Add(new(big.Int).Mul(head.BaseFee, big.NewInt(2)), tip)
+	return txFees{GasTipCap: tip, GasFeeCap: feeCap}, nil
+}
+
+// bump raises every fee by FeeBumpPercent, and never below what the current
+// base fee requires.
+func (m *TxManager) bump(ctx context.Context, fees txFees) txFees {
+	raise := func(v *big.Int) *big.Int {
+		bumped := new(big.Int).Mul(v, new(big.Int).SetUint64(100+m.cfg.FeeBumpPercent))
+		return bumped.Div(bumped, big.NewInt(100))
+	}
+
+	if fees.GasPrice != nil {
+		return txFees{GasPrice: raise(fees.GasPrice)}
+	}
+
+	bumped := txFees{GasTipCap: raise(fees.GasTipCap), GasFeeCap: raise(fees.GasFeeCap)}
+	if current, err := m.suggestFees(ctx); err == nil && current.GasFeeCap != nil {
+		if current.GasTipCap.Cmp(bumped.GasTipCap) > 0 {
+			bumped.GasTipCap = current.GasTipCap
+		}
+		if current.GasFeeCap.Cmp(bumped.GasFeeCap) > 0 {
+			bumped.GasFeeCap = current.GasFeeCap
+		}
+	}
+	return bumped
+}
+
+func (m *TxManager) newTx(nonce uint64, to common.Address, value *big.Int, data []byte, gasLimit uint64, fees txFees) (*types.Transaction, error) {
+	var inner types.TxData
+	if fees.GasPrice != nil {
+		inner = &types.LegacyTx{Nonce: nonce, GasPrice: fees.GasPrice, Gas: gasLimit, To: &to, Value: value, Data: data}
+	} else {
+		inner = &types.DynamicFeeTx{ChainID: m.chainID, Nonce: nonce, GasTipCap: fees.GasTipCap, GasFeeCap: fees.GasFeeCap, Gas: gasLimit, To: &to, Value: value, Data: data}
+	}
+	return m.signer.SignTx(types.NewTx(inner), m.chainID)
+}
+
+// sendNew assigns the next local nonce and sends the transaction. The local
+// nonce is reloaded from the node whenever a send fails.
+func (m *TxManager) sendNew(ctx context.Context, to common.Address, value *big.Int, data []byte, gasLimit uint64, fees txFees) (*types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for attempt := 0; ; attempt++ {
+		if !m.nonceLoaded {
+			nonce, err := m.client.PendingNonceAt(ctx, m.from)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch nonce: %v", err)
+			}
+			m.nonce = nonce
+			m.nonceLoaded = true
+		}
+
+		tx, err := m.newTx(m.nonce, to, value, data, gasLimit, fees)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign the transaction: %v", err)
+		}
+
+		err = m.client.SendTransaction(ctx, tx)
+		if err == nil {
+			m.nonce++
+			return tx, nil
+		}
+
+		m.nonceLoaded = false
+		if attempt == 0 && isNonceError(err) {
+			continue
+		}
+		return nil, err
+	}
+}
+
+// waitMined polls for the receipt of tx or any of its replacements, replacing
+// it with higher fees every BumpAfter.
+func (m *TxManager) waitMined(ctx context.Context, log *logrus.Entry, tx *types.Transaction, fees txFees) (*types.Receipt, error) {
+	sent := []*types.Transaction{tx}
+	lastSent := time.Now()
+	bumps := 0
+
+	ticker := time.NewTicker(m.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		for _, candidate := range sent {
+			receipt, err := m.client.TransactionReceipt(ctx, candidate.Hash())
+			if err == nil {
+				return receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				log.Warnf("Failed to fetch receipt of %s: %v", candidate.Hash().Hex(), err)
+			}
+		}
+
+		if time.Since(lastSent) >= m.cfg.BumpAfter && bumps < m.cfg.MaxFeeBumps {
+			fees = m.bump(ctx, fees)
+			replacement, err := m.newTx(tx.Nonce(), *tx.To(), tx.Value(), tx.Data(), tx.Gas(), fees)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign replacement: %v", err)
+			}
+			if err := m.client.SendTransaction(ctx, replacement); err != nil {
+				// A nonce error means one of the sent transactions was mined;
+				// its receipt is picked up on the next poll.
+				log.Warnf("Failed to send replacement for nonce %d: %v", tx.Nonce(), err)
+			} else {
+				bumps++
+				sent = append(sent, replacement)
+				log.Infof("Replaced pending transaction with %s (nonce %d, bump %d)", replacement.Hash().Hex(), tx.Nonce(), bumps)
+			}
+			lastSent = time.Now()
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("not mined before deadline: %v", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// waitConfirmations waits until the block holding receipt has the configured
+// number of confirmations and is still canonical.
+func (m *TxManager) waitConfirmations(ctx context.Context, receipt *types.Receipt) error {
+	ticker := time.NewTicker(m.cfg.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		head, err := m.client.BlockNumber(ctx)
+		if err == nil && head+1 >= receipt.BlockNumber.Uint64()+m.cfg.Confirmations {
+			current, err := m.client.TransactionReceipt(ctx, receipt.TxHash)
+			if err != nil {
+				return fmt.Errorf("receipt disappeared while waiting for confirmations: %v", err)
+			}
+			if current.BlockHash != receipt.BlockHash {
+				return fmt.Errorf("transaction was reorged from block %s", receipt.BlockHash.Hex())
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("not confirmed before deadline: %v", ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+// revertReason replays a reverted transaction at its block to recover the revert data.
+func (m *TxManager) revertReason(ctx context.Context, msg ethereum.CallMsg, receipt *types.Receipt) error {
+	msg.Gas = receipt.GasUsed
+	_, err := m.client.CallContract(ctx, msg, receipt.BlockNumber)
+	if err == nil {
+		return errors.New("execution reverted")
+	}
+	return DecodeRevert(err)
+}
+
+func isNonceError(err error) bool {
+	msg := strings.ToLower(err.Error())
+	return strings.Contains(msg, "nonce too low") || strings.Contains(msg, "nonce too high") ||
+		strings.Contains(msg, "already known") || strings.Contains(msg, "replacement transaction underpriced")
+}
@@ -0,0 +1,419 @@
+package eth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/sirupsen/logrus"
+	contract "github.com/tokamak-network/DRB-node/contract/Commit2RevealDRB"
+	"github.com/tokamak-network/DRB-node/logger"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+const (
+	defaultWatcherLookback  = 5000
+	defaultWatcherBatchSize = 2000
+	// watcherReorgDepth is how far the watcher rewinds when the last processed
+	// block is no longer part of the canonical chain.
+	watcherReorgDepth = 64
+)
+
+// RoundStatus is the watcher's view of a single DRB round, built from contract logs.
+type RoundStatus struct {
+	Round              string           `json:"round"`
+	ActivatedOperators []common.Address `json:"activated_operators"`
+	MerkleRoot         common.Hash      `json:"merkle_root"`
+	RandomNumber       string           `json:"random_number,omitempty"`
+	RequestedBlock     uint64           `json:"requested_block"`
+	GeneratedBlock     uint64           `json:"generated_block,omitempty"`
+}
+
+// MerkleRootSubmitted reports whether a non-zero Merkle root is stored on-chain for the round.
+func (r RoundStatus) MerkleRootSubmitted() bool {
+	return r.MerkleRoot != (common.Hash{})
+}
+
+// RandomNumberGenerated reports whether the RandomNumberGenerated event was seen for the round.
+func (r RoundStatus) RandomNumberGenerated() bool {
+	return r.RandomNumber != ""
+}
+
+// watcherState is the checkpoint persisted between restarts.
+type watcherState struct {
+	LastBlock uint64                  `json:"last_block"`
+	LastHash  common.Hash             `json:"last_hash"`
+	Rounds    map[string]*RoundStatus `json:"rounds"`
+}
+
+// WatcherConfig holds the tunables for a RoundWatcher.
+type WatcherConfig struct {
+	// WSURL is optional. When set, new heads are received over a websocket
+	// subscription and each head triggers a log scan; otherwise the watcher
+	// polls every PollInterval.
+	WSURL         string
+	PollInterval  time.Duration
+	Confirmations uint64
+	// StartBlock is used only when no checkpoint exists. Zero means
+	// "head minus a default lookback".
+	StartBlock uint64
+	// StateKey names the checkpoint in the watcher bucket of the store.
+	StateKey string
+}
+
+// RoundWatcher follows RandomNumberRequested and RandomNumberGenerated logs of the
+// Commit2RevealDRB contract by block range and keeps track of every open round.
+// The contract does not emit an event for submitMerkleRoot, so the Merkle root of
+// each open round is read from s_roundInfo after every scan.
+type RoundWatcher struct {
+	client   *ethclient.Client
+	contract *contract.Contract
+	cfg      WatcherConfig
+
+	mu      sync.RWMutex
+	state   watcherState
+	updates chan struct{}
+}
+
+// NewRoundWatcher creates a watcher on the shared DRB contract client and
+// restores its checkpoint from the store if present.
+func NewRoundWatcher(drb *DRBContract, cfg WatcherConfig) (*RoundWatcher, error) {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = 5 * time.Second
+	}
+	if cfg.StateKey == "" {
+		cfg.StateKey = "default"
+	}
+
+	w := &RoundWatcher{
+		client:   drb.client,
+		contract: drb.contract,
+		cfg:      cfg,
+		state:    watcherState{Rounds: make(map[string]*RoundStatus)},
+		updates:  make(chan struct{}, 1),
+	}
+
+	if err := w.loadState(); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// Updates returns a channel that receives a value every time a scan changed the set of rounds.
+func (w *RoundWatcher) Updates() <-chan struct{} {
+	return w.updates
+}
+
+// OpenRounds returns the rounds that have not generated a random number yet, ordered by round number.
+func (w *RoundWatcher) OpenRounds() []RoundStatus {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	var rounds []RoundStatus
+	for _, r := range w.state.Rounds {
+		if r.RandomNumberGenerated() {
+			continue
+		}
+		rounds = append(rounds, *r)
+	}
+
+	sort.Slice(rounds, func(i, j int) bool {
+		a, _ := new(big.Int).SetString(rounds[i].Round, 10)
+		b, _ := new(big.Int).SetString(rounds[j].Round, 10)
+		return a.Cmp(b) < 0
+	})
+	return rounds
+}
+
+// Run scans the chain until ctx is cancelled.
+func (w *RoundWatcher) Run(ctx context.Context) {
+	log := logger.Log.WithField("component", "round-watcher")
+
+	heads := make(chan *types.Header, 16)
+	var headSub interface {
+		Unsubscribe()
+		Err() <-chan error
+	}
+
+	if strings.HasPrefix(w.cfg.WSURL, "ws") {
+		wsClient, err := ethclient.DialContext(ctx, w.cfg.WSURL)
+		if err != nil {
+			log.Warnf("Failed to dial websocket endpoint, falling back to polling: %v", err)
+		} else {
+			defer wsClient.Close()
+			sub, err := wsClient.SubscribeNewHead(ctx, heads)
+			if err != nil {
+				log.Warnf("Failed to subscribe to new heads, falling back to polling: %v", err)
+			} else {
+				headSub = sub
+				defer sub.Unsubscribe()
+				log.Info("Subscribed to new heads over websocket")
+			}
+		}
+	}
+
+	ticker := time.NewTicker(w.cfg.PollInterval)
+	defer ticker.Stop()
+
+	var subErr <-chan error
+	if headSub != nil {
+		subErr = headSub.Err()
+	}
+
+	for {
+		if err := w.scan(ctx); err != nil {
+			log.Errorf("Scan failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-heads:
+		case <-ticker.C:
+		case err := <-subErr:
+			log.Warnf("New head subscription dropped, falling back to polling: %v", err)
+			subErr = nil
+		}
+	}
+}
+
+// scan processes every confirmed block since the checkpoint.
+func (w *RoundWatcher) scan(ctx context.Context) error {
+	log := logger.Log.WithField("component", "round-watcher")
+
+	head, err := w.client.BlockNumber(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch block number: %v", err)
+	}
+	if head < w.cfg.Confirmations {
+		return nil
+	}
+	target := head - w.cfg.Confirmations
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.state.LastBlock == 0 {
+		w.state.LastBlock = w.initialBlock(target)
+	} else if err := w.checkReorg(ctx); err != nil {
+		return err
+	}
+
+	changed := false
+	from := w.state.LastBlock + 1
+	for from <= target {
+		to := from + defaultWatcherBatchSize - 1
+		if to > target {
+			to = target
+		}
+
+		n, err := w.processRange(ctx, from, to)
+		if err != nil {
+			return err
+		}
+		if n > 0 {
+			changed = true
+		}
+
+		header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(to))
+		if err != nil {
+			return fmt.Errorf("failed to fetch header %d: %v", to, err)
+		}
+		w.state.LastBlock = to
+		w.state.LastHash = header.Hash()
+		if err := w.saveState(); err != nil {
+			log.Errorf("Failed to persist watcher checkpoint: %v", err)
+		}
+		from = to + 1
+	}
+
+	if w.refreshMerkleRoots(ctx) {
+		changed = true
+		if err := w.saveState(); err != nil {
+			log.Errorf("Failed to persist watcher checkpoint: %v", err)
+		}
+	}
+	w.pruneRounds()
+
+	if changed {
+		select {
+		case w.updates <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+func (w *RoundWatcher) initialBlock(target uint64) uint64 {
+	if w.cfg.StartBlock > 0 {
+		return w.cfg.StartBlock - 1
+	}
+	if target > defaultWatcherLookback {
+		return target - defaultWatcherLookback
+	}
+	return 0
+}
+
+// checkReorg rewinds the checkpoint when the last processed block was reorged out.
+// Called with w.mu locked.
+func (w *RoundWatcher) checkReorg(ctx context.Context) error {
+	if w.state.LastHash == (common.Hash{}) {
+		return nil
+	}
+
+	header, err := w.client.HeaderByNumber(ctx, new(big.Int).SetUint64(w.state.LastBlock))
+	if err != nil {
+		return fmt.Errorf("failed to fetch header %d: %v", w.state.LastBlock, err)
+	}
+	if header.Hash() == w.state.LastHash {
+		return nil
+	}
+
+	rewindTo := uint64(0)
+	if w.state.LastBlock > watcherReorgDepth {
+		rewindTo = w.state.LastBlock - watcherReorgDepth
+	}
+	logger.Log.WithField("component", "round-watcher").Warnf(
+		"Reorg detected at block %d, rewinding to block %d", w.state.LastBlock, rewindTo)
+
+	for key, r := range w.state.Rounds {
+		if r.RequestedBlock > rewindTo {
+			delete(w.state.Rounds, key)
+			continue
+		}
+		if r.GeneratedBlock > rewindTo {
+			r.RandomNumber = ""
+			r.GeneratedBlock = 0
+		}
+	}
+	w.state.LastBlock = rewindTo
+	w.state.LastHash = common.Hash{}
+	return nil
+}
+
+// processRange applies the logs of [from, to] and returns how many were applied.
+// Called with w.mu locked.
+func (w *RoundWatcher) processRange(ctx context.Context, from, to uint64) (int, error) {
+	opts := &bind.FilterOpts{Start: from, End: &to, Context: ctx}
+	applied := 0
+
+	requested, err := w.contract.FilterRandomNumberRequested(opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to filter RandomNumberRequested logs in [%d, %d]: %v", from, to, err)
+	}
+	for requested.Next() {
+		ev := requested.Event
+		if ev.Raw.Removed {
+			continue
+		}
+		key := ev.Round.String()
+		var operators []common.Address
+		for _, op := range ev.ActivatedOperators {
+			if op != (common.Address{}) {
+				operators = append(operators, op)
+			}
+		}
+		w.state.Rounds[key] = &RoundStatus{
+			Round:              key,
+			ActivatedOperators: operators,
+			RequestedBlock:     ev.Raw.BlockNumber,
+		}
+		applied++
+	}
+	if err := requested.Error(); err != nil {
+		return 0, fmt.Errorf("failed to iterate RandomNumberRequested logs: %v", err)
+	}
+	requested.Close()
+
+	generated, err := w.contract.FilterRandomNumberGenerated(opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to filter RandomNumberGenerated logs in [%d, %d]: %v", from, to, err)
+	}
+	for generated.Next() {
+		ev := generated.Event
+		if ev.Raw.Removed {
+			continue
+		}
+		r, ok := w.state.Rounds[ev.Round.String()]
+		if !ok {
+			r = &RoundStatus{Round: ev.Round.String()}
+			w.state.Rounds[r.Round] = r
+		}
+		r.RandomNumber = ev.RandomNumber.String()
+		r.GeneratedBlock = ev.Raw.BlockNumber
+		applied++
+	}
+	if err := generated.Error(); err != nil {
+		return 0, fmt.Errorf("failed to iterate RandomNumberGenerated logs: %v", err)
+	}
+	generated.Close()
+
+	return applied, nil
+}
+
+// refreshMerkleRoots reads s_roundInfo for every open round without a known root.
+// Called with w.mu locked.
+func (w *RoundWatcher) refreshMerkleRoots(ctx context.Context) bool {
+	changed := false
+	for _, r := range w.state.Rounds {
+		if r.RandomNumberGenerated() || r.MerkleRootSubmitted() {
+			continue
+		}
+		round, ok := new(big.Int).SetString(r.Round, 10)
+		if !ok {
+			continue
+		}
+		info, err := w.contract.SRoundInfo(&bind.CallOpts{Context: ctx}, round)
+		if err != nil {
+			logger.Log.WithFields(logrus.Fields{
+				"component": "round-watcher",
+				"round":     r.Round,
+			}).Warnf("Failed to read s_roundInfo: %v", err)
+			continue
+		}
+		if info.MerkleRoot != [32]byte{} {
+			r.MerkleRoot = info.MerkleRoot
+			changed = true
+		}
+	}
+	return changed
+}
+
+// pruneRounds drops generated rounds once they are deeper than the reorg window.
+// Called with w.mu locked.
+func (w *RoundWatcher) pruneRounds() {
+	for key, r := range w.state.Rounds {
+		if r.RandomNumberGenerated() && r.GeneratedBlock+watcherReorgDepth < w.state.LastBlock {
+			delete(w.state.Rounds, key)
+		}
+	}
+}
+
+func (w *RoundWatcher) loadState() error {
+	var state watcherState
+	err := utils.DefaultStore().Get(utils.BucketWatcher, w.cfg.StateKey, &state)
+	if errors.Is(err, utils.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to load watcher state: %v", err)
+	}
+	if state.Rounds == nil {
+		state.Rounds = make(map[string]*RoundStatus)
+	}
+	w.state = state
+	return nil
+}
+
+func (w *RoundWatcher) saveState() error {
+	return utils.DefaultStore().Put(utils.BucketWatcher, w.cfg.StateKey, w.state)
+}
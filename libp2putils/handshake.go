@@ -0,0 +1,123 @@
+package libp2putils
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// ErrNoHandshake is returned by Handshake when the peer runs a version without
+// the handshake, and only understands the unversioned protocol IDs.
+var ErrNoHandshake = errors.New("peer does not support the handshake")
+
+// HandleProtocol sets handler for every ID of proto, including the previous
+// versions that are still handled.
+func HandleProtocol(h host.Host, proto string, handler network.StreamHandler) {
+	for _, id := range utils.ProtocolIDs(proto) {
+		h.SetStreamHandler(id, handler)
+	}
+}
+
+// LocalHello returns the handshake of this node: its version, the protocols it
+// handles and the contract it serves.
+func LocalHello(h host.Host, cfg *config.Config) *utils.Hello {
+	hello := &utils.Hello{
+		EOAAddress: cfg.Address().Hex(),
+		Version:    utils.Version,
+		ChainID:    cfg.ChainID().String(),
+		Contract:   cfg.ContractAddress().Hex(),
+	}
+	for _, id := range h.Mux().Protocols() {
+		hello.Protocols = append(hello.Protocols, string(id))
+	}
+	sort.Strings(hello.Protocols)
+	return hello
+}
+
+// Handshake exchanges the handshake of this node with peer id and returns the
+// peer's. It fails if the peer serves another chain or contract.
+func Handshake(ctx context.Context, h host.Host, cfg *config.Config, id peer.ID) (*utils.Hello, error) {
+	stream, err := h.NewStream(ctx, id, utils.ProtocolHandshake)
+	if err != nil {
+		// The protocols of a connected peer are known once the stream was tried
+		if protocols, _ := h.Peerstore().GetProtocols(id); len(protocols) > 0 {
+			if supported, _ := h.Peerstore().SupportsProtocols(id, utils.ProtocolHandshake); len(supported) == 0 {
+				return nil, ErrNoHandshake
+			}
+		}
+		return nil, fmt.Errorf("failed to create handshake stream: %v", err)
+	}
+	defer stream.Close()
+
+	// The signer is checked against the EOA the peer announces
+	local := LocalHello(h, cfg)
+	resp, env, err := utils.RoundTrip(ctx, stream, cfg.Signer, "", "", local)
+	if err != nil {
+		return nil, err
+	}
+	if !resp.Status.Accepted() {
+		return nil, fmt.Errorf("peer rejected the handshake: %v", resp)
+	}
+
+	var remote utils.Hello
+	if err := json.Unmarshal(resp.Data, &remote); err != nil {
+		return nil, fmt.Errorf("failed to decode handshake: %v", err)
+	}
+	if !env.SentBy(remote.EOAAddress) {
+		return nil, fmt.Errorf("handshake of %s was signed by %s", remote.EOAAddress, env.Sender)
+	}
+	if err := local.CheckPeer(&remote); err != nil {
+		return nil, err
+	}
+	return &remote, nil
+}
+
+// HandleHandshake answers a handshake with the handshake of this node. A peer
+// that serves another chain or contract is rejected.
+func HandleHandshake(h host.Host, cfg *config.Config, s network.Stream) {
+	defer s.Close()
+
+	reply := func(resp *utils.Response) {
+		if err := utils.WriteResponse(s, cfg.Signer, resp); err != nil {
+			log.Printf("Failed to answer handshake of peer %s: %v", s.Conn().RemotePeer(), err)
+		}
+	}
+
+	var remote utils.Hello
+	env, err := utils.ReadEnvelope(s, &remote)
+	if err != nil {
+		log.Printf("Rejected handshake from peer %s: %v", s.Conn().RemotePeer(), err)
+		reply(&utils.Response{Status: utils.StatusInvalid, Reason: "the request could not be verified"})
+		return
+	}
+	if env.Round != "" || !env.SentBy(remote.EOAAddress) {
+		log.Printf("Envelope of %s does not match handshake of %s", env.Sender, remote.EOAAddress)
+		reply(&utils.Response{Status: utils.StatusInvalid, Reason: "the request does not match its envelope"})
+		return
+	}
+
+	local := LocalHello(h, cfg)
+	if err := local.CheckPeer(&remote); err != nil {
+		log.Printf("ALERT: Rejected handshake of EOA %s from peer %s: %v", remote.EOAAddress, s.Conn().RemotePeer(), err)
+		reply(&utils.Response{Status: utils.StatusConflict, Reason: err.Error()})
+		return
+	}
+
+	data, err := json.Marshal(local)
+	if err != nil {
+		log.Printf("Failed to encode handshake: %v", err)
+		reply(&utils.Response{Status: utils.StatusUnavailable, Reason: "the handshake could not be encoded"})
+		return
+	}
+	reply(&utils.Response{Status: utils.StatusOK, Data: data})
+	log.Printf("Handshake with EOA %s from peer %s, version %s", remote.EOAAddress, s.Conn().RemotePeer(), remote.Version)
+}
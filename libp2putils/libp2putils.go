@@ -2,6 +2,7 @@ package libp2putils
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 
@@ -14,9 +15,13 @@ import (
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
-// CreateHost creates a new libp2p host with a given port and private key.
-func CreateHost(port string) (host.Host, peer.ID, error) {
-	privKey, peerID, err := utils.LoadPeerID()
+// CreateHost creates a new libp2p host with a given port and the stored private key,
+// decrypted with passphrase. A new key is generated when none is stored.
+func CreateHost(port string, passphrase string) (host.Host, peer.ID, error) {
+	privKey, peerID, err := utils.LoadPeerID(passphrase)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		return nil, "", fmt.Errorf("failed to load PeerID: %v", err)
+	}
 	if err != nil {
 		log.Println("PeerID not found, generating a new one.")
 		privKey, _, err = crypto.GenerateKeyPair(crypto.Ed25519, 0)
@@ -24,7 +29,7 @@ func CreateHost(port string) (host.Host, peer.ID, error) {
 			return nil, "", fmt.Errorf("failed to generate private key: %v", err)
 		}
 
-		err = utils.SavePeerID(privKey)
+		err = utils.SavePeerID(privKey, passphrase)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to save PeerID: %v", err)
 		}
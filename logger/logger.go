@@ -32,6 +32,9 @@ func InitLogger() {
 
 	// Set log level (e.g., Info, Debug)
 	Log.SetLevel(logrus.InfoLevel)
+
+	// Scrub secret values, COS and signatures from the fields of every entry
+	Log.AddHook(redactHook{})
 }
 
 // CloseLogger closes the file output if it is open.
@@ -0,0 +1,28 @@
+package logger
+
+import (
+	"github.com/sirupsen/logrus"
+	"github.com/tokamak-network/DRB-node/sensitive"
+)
+
+// redactHook replaces the values of secret fields with sensitive.Redacted,
+// unless sensitive values are revealed for debugging.
+type redactHook struct{}
+
+// Levels returns every level, so no entry is written unscrubbed.
+func (redactHook) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Fire scrubs the secret fields of entry.
+func (redactHook) Fire(entry *logrus.Entry) error {
+	if sensitive.Revealed() {
+		return nil
+	}
+	for key := range entry.Data {
+		if sensitive.IsSecretField(key) {
+			entry.Data[key] = sensitive.Redacted
+		}
+	}
+	return nil
+}
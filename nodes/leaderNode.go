@@ -4,24 +4,23 @@ import (
 	"bytes"
 	"context"
 	"encoding/hex"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
 	"math/big"
-	"os"
-	"strconv"
 	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/machinebox/graphql"
 	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eip712"
+	"github.com/tokamak-network/DRB-node/eth"
 	"github.com/tokamak-network/DRB-node/libp2putils"
 	"github.com/tokamak-network/DRB-node/nodes/leaderNode_helper"
-	"github.com/tokamak-network/DRB-node/eth"
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
@@ -45,52 +44,84 @@ type GraphQLResponse struct {
 }
 
 // committedNodes and activatedOperators are authoritative in-memory states.
+// activatedOperators holds the operators expected to take part in each round,
+// which is narrowed to the participants when a round continues after the commit deadline.
 var committedNodes = make(map[string]map[common.Address]utils.LeaderCommitData)
 var activatedOperators = make(map[string]map[common.Address]bool)
 
-func RunLeaderNode() {
-	port := os.Getenv("LEADER_PORT")
-	if port == "" {
-		log.Fatal("LEADER_PORT is not set in environment variables.")
-	}
+// submittingMerkleRoot holds the rounds whose Merkle root is being generated
+// and submitted, so a round is submitted by one caller at a time.
+// Guarded by commitMu.
+var submittingMerkleRoot = make(map[string]bool)
 
-	h, peerID, err := libp2putils.CreateHost(port)
+// RunLeaderNode runs the leader node with a validated configuration. drb is the
+// process-wide contract client.
+func RunLeaderNode(cfg *config.Config, drb *eth.DRBContract) {
+	h, peerID, err := libp2putils.CreateHost(cfg.Leader.Port, cfg.IdentityPassphrase())
 	if err != nil {
 		log.Fatalf("Error creating host: %v", err)
 	}
 	defer h.Close()
 
-	h.SetStreamHandler("/register", handleRegistrationRequest)
-	h.SetStreamHandler("/cvs", handleCommitRequest)
-	h.SetStreamHandler("/cos", func(s network.Stream) {
-		handleCOSRequest(h, s)
+	restoreRounds(cfg, drb)
+
+	// Every protocol is also served on its binary ID and the IDs of its previous versions
+	libp2putils.HandleProtocol(h, utils.ProtocolHandshake, func(s network.Stream) {
+		libp2putils.HandleHandshake(h, cfg, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolRegister, func(s network.Stream) {
+		handleRegistrationRequest(cfg, drb, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolCVS, func(s network.Stream) {
+		handleCommitRequest(cfg, drb, s)
 	})
-	h.SetStreamHandler("/secretValue", func(s network.Stream) {
-		leaderNode_helper.AcceptSecretValue(h, s)
+	libp2putils.HandleProtocol(h, utils.ProtocolCOS, func(s network.Stream) {
+		handleCOSRequest(h, cfg, drb, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolSecretValue, func(s network.Stream) {
+		leaderNode_helper.AcceptSecretValue(h, cfg, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolMerkleLeaves, func(s network.Stream) {
+		leaderNode_helper.HandleMerkleLeavesRequest(cfg, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolSync, func(s network.Stream) {
+		leaderNode_helper.HandleSyncRequest(cfg, s)
 	})
 
 	log.Printf("Leader node running on: %s", h.Addrs())
 	log.Printf("Leader node PeerID: %s", peerID.String())
 
-	go leaderNode_helper.MonitorCommits(h)
+	go leaderNode_helper.MonitorCommits(h, cfg, drb)
+	resumeRounds(h, cfg, drb)
+	go monitorDeadlines(cfg, drb)
 
+	watcher := startRoundWatcher(context.Background(), cfg, drb)
+
+	synced := false
 	for {
-		roundsData, err := fetchRoundsData()
+		roundsData, err := fetchRounds(cfg, watcher)
 		if err != nil {
 			log.Printf("Error fetching rounds data: %v", err)
-			time.Sleep(30 * time.Second)
+			waitForRoundUpdate(watcher)
 			continue
 		}
 
-		processRounds(roundsData)
-		time.Sleep(30 * time.Second)
+		processRounds(cfg, drb, roundsData)
+
+		// Once the open rounds are known, ask the operators for what was lost
+		if !synced {
+			go leaderNode_helper.RequestSync(h, cfg)
+			synced = true
+		}
+		waitForRoundUpdate(watcher)
 	}
 }
 
-func fetchRoundsData() (*GraphQLResponse, error) {
-	subGraphURL := os.Getenv("SUBGRAPH_URL")
+// fetchRoundsData reads the open rounds from the subgraph. It is used when the
+// on-chain round watcher is disabled or unavailable.
+func fetchRoundsData(subGraphURL string) (*GraphQLResponse, error) {
 	if subGraphURL == "" {
-		log.Fatal("SUBGRAPH_URL is not set in environment variables.")
+		return nil, fmt.Errorf("no subgraph URL is configured")
 	}
 	client := graphql.NewClient(subGraphURL)
 	ctx := context.Background()
@@ -98,101 +129,216 @@ func fetchRoundsData() (*GraphQLResponse, error) {
 
 	var resp GraphQLResponse
 	if err := client.Run(ctx, req, &resp); err != nil {
-		log.Fatalf("Failed to execute GraphQL request: %v", err)
+		return nil, fmt.Errorf("failed to execute GraphQL request: %v", err)
 	}
 	return &resp, nil
 }
 
-func handleRegistrationRequest(s network.Stream) {
+func handleRegistrationRequest(cfg *config.Config, drb *eth.DRBContract, s network.Stream) {
 	defer s.Close()
-	filePath := "registered_nodes.json"
-	if err := leaderNode_helper.RegisterNode(s, filePath, "contract/abi/Commit2RevealDRB.json"); err != nil {
+	if err := leaderNode_helper.RegisterNode(cfg, drb, s); err != nil {
 		log.Printf("Failed to handle registration request: %v", err)
 		return
 	}
 	log.Println("Node registration and activation completed.")
 }
 
-func handleCommitRequest(s network.Stream) {
+func handleCommitRequest(cfg *config.Config, drb *eth.DRBContract, s network.Stream) {
 	defer s.Close()
 
 	var req utils.CommitRequest
-	if err := json.NewDecoder(s).Decode(&req); err != nil {
-		log.Printf("Failed to decode commit request: %v", err)
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		log.Printf("Rejected commit request from peer %s: %v", s.Conn().RemotePeer(), err)
+		leaderNode_helper.Reply(cfg, s, "", "", utils.StatusInvalid, "the request could not be verified")
 		return
 	}
 
-	commitVerificationRequest := utils.Request{Round: req.Round, EOAAddress: req.EOAAddress, Signature: req.Signature}
+	commitVerificationRequest := utils.Request{Round: req.Round, EOAAddress: req.EOAAddress}
 
-	if !VerifySignatureAndCheckActivation(commitVerificationRequest, "commit") {
+	if status, reason := VerifySignatureAndCheckActivation(cfg, drb, env, commitVerificationRequest, "commit"); status != utils.StatusOK {
+		leaderNode_helper.Reply(cfg, s, env.Round, env.Sender, status, reason)
 		return
 	}
 	
 	roundNum := req.Round
 	eoaAddress := common.HexToAddress(req.EOAAddress)
+	reply := func(status utils.Status, reason string) {
+		leaderNode_helper.Reply(cfg, s, roundNum, eoaAddress.Hex(), status, reason)
+	}
+
+	// Reject a CVS signature now rather than have generateRandomNumber revert
+	// with InvalidSignature at the end of the round
+	if status, reason := checkCvsSignature(drb, req); status != utils.StatusOK {
+		log.Printf("Rejected CVS from EOA %s for round %s: %s", eoaAddress.Hex(), roundNum, reason)
+		reply(status, reason)
+		return
+	}
+
+	// The secret value is requested from the registered peer later on
+	if status, reason := checkRegistered(env); status != utils.StatusOK {
+		log.Printf("Rejected CVS from EOA %s for round %s: %s", eoaAddress.Hex(), roundNum, reason)
+		reply(status, reason)
+		return
+	}
+
+	state, err := loadOrCreateRoundState(cfg, drb, roundNum)
+	if err != nil {
+		log.Printf("Failed to load round state for round %s: %v", roundNum, err)
+		reply(utils.StatusUnavailable, "the round state could not be loaded")
+		return
+	}
+	if state.Reached(utils.PhaseMerkleSubmitted) || state.Phase == utils.PhaseFailed {
+		log.Printf("Round %s is in phase %s, rejecting CVS from EOA %s.", roundNum, state.Phase, eoaAddress.Hex())
+		reply(utils.StatusTooLate, fmt.Sprintf("round %s is in phase %s", roundNum, state.Phase))
+		return
+	}
 
 	commitMu.Lock()
 	defer commitMu.Unlock()
 
+	trackRoundUnlocked(state)
 	commitData := getOrCreateLeaderCommitData(roundNum, eoaAddress)
-	if commitData.Cvs == [32]byte{} {
-		commitData.Cvs = req.Cvs
-		commitData.CvsHex = hex.EncodeToString(req.Cvs[:])
-		commitData.Sign = req.Sign
-		log.Printf("Storing CVS and signature for round %s EOA %s", roundNum, eoaAddress.Hex())
+	if commitData.Cvs != [32]byte{} && commitData.Cvs == req.Cvs {
+		log.Printf("CVS already received for round %s EOA %s. Skipping.", roundNum, eoaAddress.Hex())
+		reply(utils.StatusDuplicate, "")
+		return
+	} else if commitData.Cvs != [32]byte{} {
+		log.Printf("Rejected CVS from EOA %s for round %s: another CVS was already received", eoaAddress.Hex(), roundNum)
+		reply(utils.StatusConflict, "another CVS was already received for the round")
+		return
 	}
 
+	commitData.Cvs = req.Cvs
+	commitData.CvsHex = hex.EncodeToString(req.Cvs[:])
+	commitData.Sign = req.Sign
+	log.Printf("Storing CVS and signature for round %s EOA %s", roundNum, eoaAddress.Hex())
+
 	if err := utils.SaveLeaderCommitData(*commitData); err != nil {
 		log.Printf("Error saving commit data for round %s EOA %s: %v", roundNum, eoaAddress.Hex(), err)
+		reply(utils.StatusUnavailable, "the CVS could not be stored")
 		return
 	}
 	updateInMemoryData(roundNum, eoaAddress, *commitData)
 	log.Printf("Commit data saved and updated in-memory for round %s EOA %s", roundNum, eoaAddress.Hex())
+	reply(utils.StatusOK, "")
+
+	if state, err = utils.TransitionRound(roundNum, utils.PhaseCollectingCVS); err != nil {
+		log.Printf("Failed to move round %s to the CVS collection phase: %v", roundNum, err)
+		return
+	}
 
 	// Check if all commits are ready after this update
-	if !isMerkleRootSubmitted(roundNum) && allCommitsReceivedUnlocked(roundNum) {
+	if state.Phase == utils.PhaseCollectingCVS && allCommitsReceivedUnlocked(roundNum) {
+		// Submission retries with backoff, so it must not hold the sender's stream
 		log.Printf("All CVS received for round %s. Generating Merkle root...", roundNum)
-		commitMu.Unlock() // Unlock before calling generateMerkleRoot
-		generateMerkleRoot(roundNum)
-		commitMu.Lock() // Re-lock if needed
+		go generateMerkleRoot(cfg, drb, roundNum)
+	}
+}
+
+// checkCvsSignature checks that the EIP-712 signature of a commit request was
+// made by its EOA over its round and CVS. It returns the status to reject the
+// request with, and why, or StatusOK.
+func checkCvsSignature(drb *eth.DRBContract, req utils.CommitRequest) (utils.Status, string) {
+	round, ok := new(big.Int).SetString(req.Round, 10)
+	if !ok {
+		return utils.StatusInvalid, fmt.Sprintf("invalid round number %q", req.Round)
 	}
+	domain, err := drb.EIP712Domain(context.Background())
+	if err != nil {
+		return utils.StatusUnavailable, fmt.Sprintf("failed to load the EIP-712 domain: %v", err)
+	}
+	sig, err := eip712.ParseSignature(req.Sign["v"], req.Sign["r"], req.Sign["s"])
+	if err == nil {
+		err = domain.VerifyMessage(round, req.Cvs, sig, common.HexToAddress(req.EOAAddress))
+	}
+	if err != nil {
+		return utils.StatusInvalid, fmt.Sprintf("invalid CVS signature: %v", err)
+	}
+	return utils.StatusOK, ""
+}
+
+// checkRegistered checks that the sender of env is registered from the peer it
+// sent env from, so the leader can reach it when the round needs its secret.
+func checkRegistered(env *utils.Envelope) (utils.Status, string) {
+	registered, err := leaderNode_helper.IsRegistered(env.Sender, env.PeerID)
+	if err != nil {
+		log.Printf("Failed to check the registration of EOA %s: %v", env.Sender, err)
+		return utils.StatusUnavailable, "the registration could not be checked"
+	}
+	if !registered {
+		return utils.StatusNotRegistered, fmt.Sprintf("EOA %s is not registered from peer %s", common.HexToAddress(env.Sender).Hex(), env.PeerID)
+	}
+	return utils.StatusOK, ""
 }
 
-func handleCOSRequest(h host.Host, s network.Stream) {
+func handleCOSRequest(h host.Host, cfg *config.Config, drb *eth.DRBContract, s network.Stream) {
 	defer s.Close()
 
 	var req utils.CosRequest
-	if err := json.NewDecoder(s).Decode(&req); err != nil {
-		log.Printf("Failed to decode COS request: %v", err)
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		log.Printf("Rejected COS request from peer %s: %v", s.Conn().RemotePeer(), err)
+		leaderNode_helper.Reply(cfg, s, "", "", utils.StatusInvalid, "the request could not be verified")
 		return
 	}
 
-	cosVerificationRequest := utils.Request{Round: req.Round, EOAAddress: req.EOAAddress, Signature: req.Signature}
+	cosVerificationRequest := utils.Request{Round: req.Round, EOAAddress: req.EOAAddress}
 
-	if !VerifySignatureAndCheckActivation(cosVerificationRequest, "COS") {
+	if status, reason := VerifySignatureAndCheckActivation(cfg, drb, env, cosVerificationRequest, "COS"); status != utils.StatusOK {
+		leaderNode_helper.Reply(cfg, s, env.Round, env.Sender, status, reason)
 		return
 	}
 
 	roundNum := req.Round
 	eoaAddress := common.HexToAddress(req.EOAAddress)
-		
+	reply := func(status utils.Status, reason string) {
+		leaderNode_helper.Reply(cfg, s, roundNum, eoaAddress.Hex(), status, reason)
+	}
+
+	if status, reason := checkRegistered(env); status != utils.StatusOK {
+		log.Printf("Rejected COS from EOA %s for round %s: %s", eoaAddress.Hex(), roundNum, reason)
+		reply(status, reason)
+		return
+	}
+
+	state, err := utils.LoadRoundState(roundNum)
+	if err != nil {
+		log.Printf("Failed to load round state for round %s: %v", roundNum, err)
+		reply(utils.StatusUnavailable, "the round state could not be loaded")
+		return
+	}
+	if state.Phase == utils.PhaseFailed {
+		log.Printf("Round %s is in phase %s, rejecting COS from EOA %s.", roundNum, state.Phase, eoaAddress.Hex())
+		reply(utils.StatusTooLate, fmt.Sprintf("round %s is in phase %s", roundNum, state.Phase))
+		return
+	}
+	if !state.Reached(utils.PhaseMerkleSubmitted) {
+		log.Printf("Round %s is in phase %s, rejecting COS from EOA %s.", roundNum, state.Phase, eoaAddress.Hex())
+		reply(utils.StatusTooEarly, fmt.Sprintf("round %s is in phase %s", roundNum, state.Phase))
+		return
+	}
+
 	commitMu.Lock()
 	defer commitMu.Unlock()
 
 	commitData := getOrCreateLeaderCommitData(roundNum, eoaAddress)
 	if commitData.Cvs == [32]byte{} {
 		log.Printf("No CVS found for round %s EOA %s, rejecting COS.", roundNum, eoaAddress.Hex())
+		reply(utils.StatusConflict, "no CVS was received from this operator for the round")
 		return
 	}
 
 	recalculatedCvs := commitreveal2.Keccak256(req.Cos[:])
 	if !bytes.Equal(recalculatedCvs, commitData.Cvs[:]) {
 		log.Printf("COS hash mismatch for round %s EOA %s. Rejecting COS.", roundNum, eoaAddress.Hex())
+		reply(utils.StatusConflict, "the hash of the COS does not match the CVS")
 		return
 	}
 
 	if commitData.Cos != [32]byte{} {
 		log.Printf("COS already received for round %s EOA %s. Skipping.", roundNum, eoaAddress.Hex())
+		reply(utils.StatusDuplicate, "")
 		return
 	}
 
@@ -202,63 +348,102 @@ func handleCOSRequest(h host.Host, s network.Stream) {
 
 	if err := utils.SaveLeaderCommitData(*commitData); err != nil {
 		log.Printf("Error saving COS data for round %s EOA %s: %v", roundNum, eoaAddress.Hex(), err)
+		reply(utils.StatusUnavailable, "the COS could not be stored")
 		return
 	}
 	updateInMemoryData(roundNum, eoaAddress, *commitData)
 	log.Printf("COS data saved and updated in-memory for round %s EOA %s", roundNum, eoaAddress.Hex())
+	reply(utils.StatusOK, "")
 
-	// Check if all commits are ready after this COS
-	if !isMerkleRootSubmitted(roundNum) && allCommitsReceivedUnlocked(roundNum) {
-		log.Printf("All CVS received for round %s after COS, generating Merkle root...", roundNum)
-		commitMu.Unlock()
-		generateMerkleRoot(roundNum)
-		commitMu.Lock()
+	if state, err = utils.TransitionRound(roundNum, utils.PhaseCollectingCOS); err != nil {
+		log.Printf("Failed to move round %s to the COS collection phase: %v", roundNum, err)
+		return
 	}
 
-	// Also, if all COS are received (if that matters), we determine reveal order as existing code:
-	if allCommitsReceivedUnlocked(roundNum) {
-		log.Printf("All COS received for round %s. Determining reveal order...", roundNum)
-		err := commitreveal2.DetermineRevealOrder(roundNum, activatedOperators)
-		if err != nil {
-			log.Printf("Failed to determine reveal order for round %s: %v", roundNum, err)
-			return
-		}
-		log.Printf("Reveal order determined for round %s.", roundNum)
-		leaderNode_helper.StartSecretValueRequests(h, roundNum)
+	if state.Phase == utils.PhaseCollectingCOS && allCosReceivedUnlocked(roundNum) {
+		go startReveal(h, cfg, roundNum)
 	}
 }
 
-func isMerkleRootSubmitted(roundNum string) bool {
-	// Call with commitMu locked or ensure commitMu is locked outside
-	roundMap, exists := committedNodes[roundNum]
-	if !exists || len(roundMap) == 0 {
-		return false
+// startReveal determines the reveal order of a round and requests the first secret value.
+// It waits for the answer of the first node, so it must not be called with commitMu locked.
+func startReveal(h host.Host, cfg *config.Config, roundNum string) {
+	log.Printf("All COS received for round %s. Determining reveal order...", roundNum)
+	state, err := utils.LoadRoundState(roundNum)
+	if err != nil {
+		log.Printf("Failed to load round state for round %s: %v", roundNum, err)
+		return
+	}
+	if err := commitreveal2.DetermineRevealOrder(roundNum, state.Operators()); err != nil {
+		log.Printf("Failed to determine reveal order for round %s: %v", roundNum, err)
+		return
 	}
+	log.Printf("Reveal order determined for round %s.", roundNum)
+	leaderNode_helper.StartSecretValueRequests(h, cfg, roundNum)
+}
 
-	// Check any operator to see if SubmitMerkleRootDone is set
-	for _, data := range roundMap {
-		if data.SubmitMerkleRootDone {
-			return true
+// loadOrCreateRoundState returns the state of a round, creating it from the
+// on-chain activated operators when the round has not been seen yet.
+func loadOrCreateRoundState(cfg *config.Config, drb *eth.DRBContract, roundNum string) (*utils.RoundState, error) {
+	state, err := utils.LoadRoundState(roundNum)
+	if !errors.Is(err, utils.ErrNotFound) {
+		return state, err
+	}
+
+	operators, err := leaderNode_helper.FetchActivatedOperators(cfg, drb, roundNum)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch activated operators: %v", err)
+	}
+	return utils.EnsureRoundState(roundNum, filterZeroAddresses(operators))
+}
+
+// trackRoundUnlocked records the operators of a round in memory.
+// Called with commitMu locked.
+func trackRoundUnlocked(state *utils.RoundState) {
+	if _, exists := activatedOperators[state.Round]; exists || len(state.Operators()) == 0 {
+		return
+	}
+	setRoundOperatorsUnlocked(state.Round, state.Operators())
+}
+
+// setRoundOperatorsUnlocked replaces the operators expected to take part in a round.
+// Called with commitMu locked.
+func setRoundOperatorsUnlocked(roundNum string, operators []string) {
+	ops := make(map[common.Address]bool, len(operators))
+	for _, op := range operators {
+		ops[common.HexToAddress(op)] = true
+	}
+	activatedOperators[roundNum] = ops
+}
+
+// filterZeroAddresses drops the zero address from a list of operators.
+func filterZeroAddresses(operators []string) []string {
+	var filtered []string
+	for _, op := range operators {
+		if common.HexToAddress(op) != (common.Address{}) {
+			filtered = append(filtered, common.HexToAddress(op).Hex())
 		}
 	}
-	return false
+	return filtered
 }
 
-func VerifySignatureAndCheckActivation(temp utils.Request, reqType string, ) bool {
-	verifyReq := utils.RegistrationRequest{EOAAddress: temp.EOAAddress, Signature: temp.Signature}
-	if !utils.VerifySignature(verifyReq) {
-		log.Printf("Signature verification failed for round %s EOA %s", temp.Round, temp.EOAAddress)
-		return false
+// VerifySignatureAndCheckActivation checks that the verified envelope env was signed
+// by the EOA of the request for its round, and that the EOA takes part in the round.
+// It returns the status to reject the request with, and why, or StatusOK.
+func VerifySignatureAndCheckActivation(cfg *config.Config, drb *eth.DRBContract, env *utils.Envelope, temp utils.Request, reqType string) (utils.Status, string) {
+	if env.Round != temp.Round || !env.SentBy(temp.EOAAddress) {
+		log.Printf("Envelope of %s for round %s does not match %s request for round %s EOA %s", env.Sender, env.Round, reqType, temp.Round, temp.EOAAddress)
+		return utils.StatusInvalid, "the request does not match its envelope"
 	}
 
 	roundNum := temp.Round
 	eoaAddress := common.HexToAddress(temp.EOAAddress)
 
-	if !isEOAActivatedForRound(roundNum, eoaAddress) {
+	if !isEOAActivatedForRound(cfg, drb, roundNum, eoaAddress) {
 		log.Printf("EOA %s not activated for round %s, skipping %s.", eoaAddress.Hex(), roundNum, reqType)
-		return false
+		return utils.StatusNotActivated, fmt.Sprintf("EOA %s is not an activated operator of round %s", eoaAddress.Hex(), roundNum)
 	}
-	return true
+	return utils.StatusOK, ""
 }
 
 // allCommitsReceivedUnlocked checks if all operators have CVS in-memory.
@@ -283,6 +468,24 @@ func allCommitsReceivedUnlocked(roundNum string) bool {
 	return true
 }
 
+// allCosReceivedUnlocked checks if all operators have COS in-memory.
+// Called with commitMu locked.
+func allCosReceivedUnlocked(roundNum string) bool {
+	ops, exists := activatedOperators[roundNum]
+	if !exists || len(ops) == 0 {
+		return false
+	}
+
+	roundCommits := committedNodes[roundNum]
+	for op := range ops {
+		data, ok := roundCommits[op]
+		if !ok || data.Cos == [32]byte{} {
+			return false
+		}
+	}
+	return true
+}
+
 // getOrCreateLeaderCommitData returns commitData from in-memory map or creates a new one.
 // Called with commitMu locked.
 func getOrCreateLeaderCommitData(roundNum string, eoaAddress common.Address) *utils.LeaderCommitData {
@@ -311,30 +514,46 @@ func updateInMemoryData(roundNum string, eoaAddress common.Address, commitData u
 	roundMap[eoaAddress] = commitData
 }
 
-// generateMerkleRoot doesn't lock; it locks inside to read from memory
-func generateMerkleRoot(roundNum string) {
+// generateMerkleRoot doesn't lock; it locks inside to read from memory. It
+// skips a round whose Merkle root another caller is already submitting.
+func generateMerkleRoot(cfg *config.Config, drb *eth.DRBContract, roundNum string) {
 	commitMu.Lock()
-	// Check if merkle root is already done before proceeding
-	if isMerkleRootSubmitted(roundNum) {
-		log.Printf("Merkle root already submitted for round %s, skipping.", roundNum)
+	if submittingMerkleRoot[roundNum] {
 		commitMu.Unlock()
+		log.Printf("Merkle root for round %s is already being submitted, skipping.", roundNum)
 		return
 	}
+	submittingMerkleRoot[roundNum] = true
 	commitMu.Unlock()
 
-	log.Printf("Generating Merkle root for round %s...", roundNum)
+	// A failed submission is retried by the next caller
+	defer func() {
+		commitMu.Lock()
+		delete(submittingMerkleRoot, roundNum)
+		commitMu.Unlock()
+	}()
 
-	activatedOperatorsList, err := leaderNode_helper.FetchActivatedOperators(roundNum)
+	state, err := loadOrCreateRoundState(cfg, drb, roundNum)
 	if err != nil {
-		log.Printf("Failed to fetch activated operators for round %s: %v", roundNum, err)
+		log.Printf("Failed to load round state for round %s: %v", roundNum, err)
+		return
+	}
+	// Check if merkle root is already done before proceeding
+	if state.Phase != utils.PhaseCollectingCVS {
+		log.Printf("Round %s is in phase %s, skipping Merkle root generation.", roundNum, state.Phase)
 		return
 	}
 
-	var filteredOperators []string
-	for _, operator := range activatedOperatorsList {
-		if operator != "0x0000000000000000000000000000000000000000" {
-			filteredOperators = append(filteredOperators, operator)
+	log.Printf("Generating Merkle root for round %s...", roundNum)
+
+	filteredOperators := state.Operators()
+	if len(filteredOperators) == 0 {
+		activatedOperatorsList, err := leaderNode_helper.FetchActivatedOperators(cfg, drb, roundNum)
+		if err != nil {
+			log.Printf("Failed to fetch activated operators for round %s: %v", roundNum, err)
+			return
 		}
+		filteredOperators = filterZeroAddresses(activatedOperatorsList)
 	}
 
 	log.Printf("Activated operators for round %s in order: %v", roundNum, filteredOperators)
@@ -373,137 +592,104 @@ func generateMerkleRoot(roundNum string) {
 
 	log.Printf("Leaves for Merkle tree for round %s: %v", roundNum, leaves)
 
-	merkleRoot, err := commitreveal2.CreateMerkleTree(leaves)
+	tree, err := commitreveal2.NewMerkleTree(leaves)
 	if err != nil {
 		log.Printf("Failed to create Merkle tree for round %s: %v", roundNum, err)
 		return
 	}
+	merkleRoot := tree.Root()
+	log.Printf("Merkle root for round %s: %s", roundNum, merkleRoot.Hex())
 
-	submitMerkleRoot(roundNum, merkleRoot)
+	submitMerkleRoot(drb, roundNum, merkleRoot[:], filteredOperators, leaves)
 }
 
-func submitMerkleRoot(roundNum string, merkleRoot []byte) {
-	var merkleRootBytes32 [32]byte
-	copy(merkleRootBytes32[:], merkleRoot)
-
-	ethRPCURL := os.Getenv("ETH_RPC_URL")
-	if ethRPCURL == "" {
-		log.Fatal("ETH_RPC_URL is not set in environment variables.")
-	}
-
-	client, err := ethclient.Dial(ethRPCURL)
-	if err != nil {
-		log.Printf("Failed to connect to Ethereum client: %v", err)
-		return
-	}
-
-	contractAddressStr := os.Getenv("CONTRACT_ADDRESS")
-	if contractAddressStr == "" {
-		log.Fatal("CONTRACT_ADDRESS is not set in environment variables.")
-	}
-
-	contractAddress := common.HexToAddress(contractAddressStr)
-	parsedABI, err := utils.LoadContractABI("contract/abi/Commit2RevealDRB.json")
-	if err != nil {
-		log.Printf("Failed to load contract ABI: %v", err)
-		return
-	}
+// maxMerkleRootAttempts is how often the Merkle root of a round is sent while
+// it fails for a reason that may be transient.
+const maxMerkleRootAttempts = 3
 
-	roundNumInt, err := strconv.ParseInt(roundNum, 10, 64)
-	if err != nil {
-		log.Printf("Failed to parse roundNum: %v", err)
-		return
-	}
+// merkleRootRetryDelay is the wait before the first retry; it doubles with every retry.
+const merkleRootRetryDelay = 15 * time.Second
 
-	privateKeyHex := os.Getenv("LEADER_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("LEADER_PRIVATE_KEY is not set in environment variables.")
-	}
+// submitMerkleRoot submits the Merkle root of a round and records it with the
+// operators and CVS leaves it was built from. A submission the contract
+// rejects fails the round; other failures are retried.
+func submitMerkleRoot(drb *eth.DRBContract, roundNum string, merkleRoot []byte, operators []string, leaves [][]byte) {
+	var merkleRootBytes32 [32]byte
+	copy(merkleRootBytes32[:], merkleRoot)
 
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		log.Printf("Failed to decode leader private key: %v", err)
+	roundNumInt, ok := new(big.Int).SetString(roundNum, 10)
+	if !ok {
+		log.Printf("Failed to parse roundNum: %s", roundNum)
 		return
 	}
 
-	clientUtils := &utils.Client{
-		Client:          client,
-		ContractAddress: contractAddress,
-		PrivateKey:      privateKey,
-		ContractABI:     parsedABI,
-	}
+	delay := merkleRootRetryDelay
+	for attempt := 1; ; attempt++ {
+		_, err := drb.SubmitMerkleRoot(context.Background(), roundNumInt, merkleRootBytes32)
+		if err == nil {
+			break
+		}
 
-	_, _, err = eth.ExecuteTransaction(
-		context.Background(),
-		clientUtils,
-		"submitMerkleRoot",
-		big.NewInt(0),
-		big.NewInt(roundNumInt),
-		merkleRootBytes32,
-	)
-	if err != nil {
-		log.Printf("Failed to submit Merkle root for round %s: %v", roundNum, err)
-		return
+		failure := eth.ClassifyTxError(err)
+		log.Printf("Failed to submit Merkle root for round %s (attempt %d, %s): %v", roundNum, attempt, failure, err)
+		if failure == eth.TxAbort {
+			state, loadErr := utils.LoadRoundState(roundNum)
+			if loadErr != nil {
+				log.Printf("Failed to load round state for round %s: %v", roundNum, loadErr)
+				return
+			}
+			failRound(state, time.Time{}, fmt.Sprintf("submitMerkleRoot rejected: %v", err), nil)
+			return
+		}
+		if attempt == maxMerkleRootAttempts {
+			return
+		}
+		time.Sleep(delay)
+		delay *= 2
 	}
 
 	log.Printf("Successfully submitted Merkle root for round %s", roundNum)
-	updateCommitDataAfterSubmit(roundNum)
-}
-
-func updateCommitDataAfterSubmit(roundNum string) {
-	commitMu.Lock()
-	defer commitMu.Unlock()
-
-	roundMap, exists := committedNodes[roundNum]
-	if !exists {
-		return
-	}
-
-	for eoaAddress, data := range roundMap {
-		data.SubmitMerkleRootDone = true
-		log.Printf("Setting submit_merkle_root_done = true for key: %s+%s", roundNum, eoaAddress.Hex())
-
-		if err := utils.SaveLeaderCommitData(data); err != nil {
-			log.Printf("Failed to save updated commit data for %s in round %s: %v", eoaAddress.Hex(), roundNum, err)
-		} else {
-			roundMap[eoaAddress] = data
+	_, err := utils.UpdateRoundState(roundNum, func(state *utils.RoundState) error {
+		state.MerkleRoot = common.BytesToHash(merkleRoot).Hex()
+		state.MerkleOperators = operators
+		state.MerkleLeaves = nil
+		for _, leaf := range leaves {
+			state.MerkleLeaves = append(state.MerkleLeaves, hex.EncodeToString(leaf))
 		}
+		return state.Transition(utils.PhaseMerkleSubmitted)
+	})
+	if err != nil {
+		log.Printf("Failed to record Merkle root submission for round %s: %v", roundNum, err)
 	}
 }
 
-func isEOAActivatedForRound(roundNum string, eoaAddress common.Address) bool {
-	roundInt, err := strconv.Atoi(roundNum)
-	if err != nil {
-		log.Printf("Invalid round number %s: %v", roundNum, err)
+func isEOAActivatedForRound(cfg *config.Config, drb *eth.DRBContract, roundNum string, eoaAddress common.Address) bool {
+	roundInt, ok := new(big.Int).SetString(roundNum, 10)
+	if !ok {
+		log.Printf("Invalid round number %s", roundNum)
 		return false
 	}
 
-	subGraphURL := os.Getenv("SUBGRAPH_URL")
-	if subGraphURL == "" {
-		log.Fatal("SUBGRAPH_URL is not set in environment variables.")
+	// Rounds seen by processRounds already carry their activated operators.
+	commitMu.Lock()
+	ops, known := activatedOperators[roundNum]
+	activated := ops[eoaAddress]
+	commitMu.Unlock()
+	if known {
+		if !activated {
+			log.Printf("EOA address %s is NOT activated for round %d", eoaAddress.Hex(), roundInt)
+		}
+		return activated
 	}
 
-	client := graphql.NewClient(subGraphURL)
-	req := utils.GetActivatedOperatorsAtRoundRequest(roundInt)
-
-	var resp map[string]interface{}
-	ctx := context.Background()
-	err = client.Run(ctx, req, &resp)
+	operators, err := leaderNode_helper.FetchActivatedOperators(cfg, drb, roundNum)
 	if err != nil {
-		log.Printf("Failed to execute GraphQL request for activated operators in round %d: %v", roundInt, err)
-		return false
-	}
-
-	activatedOperatorsData, ok := resp["randomNumberRequesteds"].([]interface{})
-	if !ok || len(activatedOperatorsData) == 0 {
-		log.Printf("No activated operators found for round %d", roundInt)
+		log.Printf("Failed to fetch activated operators for round %d: %v", roundInt, err)
 		return false
 	}
 
-	activated := activatedOperatorsData[0].(map[string]interface{})["activatedOperators"].([]interface{})
-	for _, operator := range activated {
-		operatorAddress := common.HexToAddress(operator.(string))
-		if operatorAddress == eoaAddress {
+	for _, operator := range operators {
+		if common.HexToAddress(operator) == eoaAddress {
 			log.Printf("EOA address %s is activated for round %d", eoaAddress.Hex(), roundInt)
 			return true
 		}
@@ -513,7 +699,7 @@ func isEOAActivatedForRound(roundNum string, eoaAddress common.Address) bool {
 	return false
 }
 
-func processRounds(roundsData *GraphQLResponse) {
+func processRounds(cfg *config.Config, drb *eth.DRBContract, roundsData *GraphQLResponse) {
 	for _, round := range roundsData.Rounds {
 		roundNum, ok := round.Round.(string)
 		if !ok {
@@ -521,22 +707,33 @@ func processRounds(roundsData *GraphQLResponse) {
 			continue
 		}
 
-		if len(round.RandomNumberRequested.ActivatedOperators) > 0 {
-			commitMu.Lock()
-			if _, exists := activatedOperators[roundNum]; !exists {
-				activatedOperators[roundNum] = make(map[common.Address]bool)
-			}
-			for _, op := range round.RandomNumberRequested.ActivatedOperators {
-				opAddr := common.HexToAddress(op)
-				if opAddr == common.HexToAddress("0x0000000000000000000000000000000000000000") {
-					continue
-				}
-				activatedOperators[roundNum][opAddr] = true
-			}
-			commitMu.Unlock()
+		operators := filterZeroAddresses(round.RandomNumberRequested.ActivatedOperators)
+		if len(operators) == 0 {
+			continue
+		}
+
+		state, err := utils.EnsureRoundState(roundNum, operators)
+		if err != nil {
+			log.Printf("Failed to load round state for round %s: %v", roundNum, err)
+			continue
+		}
+
+		commitMu.Lock()
+		trackRoundUnlocked(state)
+		commitMu.Unlock()
+
+		if state.Terminal() {
+			continue
+		}
+
+		// A Merkle root on-chain that the state does not know about, or whose
+		// leaves it does not hold, was submitted before the leader last stopped.
+		if merkleRoot, ok := round.MerkleRootSubmitted.MerkleRoot.(string); ok && (!state.Reached(utils.PhaseMerkleSubmitted) || len(state.MerkleLeaves) == 0) {
+			recoverMerkleRoot(state, merkleRoot)
+			continue
 		}
 
-		if round.MerkleRootSubmitted.MerkleRoot == nil && round.RandomNumberGenerated.RandomNumber == nil {
+		if !state.Reached(utils.PhaseMerkleSubmitted) {
 			log.Printf("Round %s is still waiting for commits", roundNum)
 
 			commitMu.Lock()
@@ -545,7 +742,7 @@ func processRounds(roundsData *GraphQLResponse) {
 
 			if ready {
 				log.Printf("All CVS received for round %s. Generating Merkle root...", roundNum)
-				generateMerkleRoot(roundNum)
+				generateMerkleRoot(cfg, drb, roundNum)
 			} else {
 				log.Printf("Not all CVS received for round %s. Waiting for remaining commits.", roundNum)
 			}
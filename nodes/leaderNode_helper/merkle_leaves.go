@@ -0,0 +1,78 @@
+package leaderNode_helper
+
+import (
+	"encoding/json"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// HandleMerkleLeavesRequest answers an operator of a round with the operators
+// and CVS leaves of the Merkle root submitted for it, on the same stream.
+func HandleMerkleLeavesRequest(cfg *config.Config, s network.Stream) {
+	defer s.Close()
+
+	var req utils.Request
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		log.Printf("Rejected Merkle leaves request from peer %s: %v", s.Conn().RemotePeer(), err)
+		Reply(cfg, s, "", "", utils.StatusInvalid, "the request could not be verified")
+		return
+	}
+	if env.Round != req.Round || !env.SentBy(req.EOAAddress) {
+		log.Printf("Envelope of %s for round %s does not match Merkle leaves request for round %s from %s", env.Sender, env.Round, req.Round, req.EOAAddress)
+		Reply(cfg, s, env.Round, env.Sender, utils.StatusInvalid, "the request does not match its envelope")
+		return
+	}
+	reply := func(status utils.Status, reason string) {
+		Reply(cfg, s, req.Round, req.EOAAddress, status, reason)
+	}
+
+	state, err := utils.LoadRoundState(req.Round)
+	if err != nil {
+		log.Printf("Failed to load round state for round %s: %v", req.Round, err)
+		reply(utils.StatusUnavailable, "the round state could not be loaded")
+		return
+	}
+	if state.MerkleRoot == "" || len(state.MerkleLeaves) == 0 {
+		log.Printf("No Merkle leaves recorded for round %s", req.Round)
+		reply(utils.StatusTooEarly, "no Merkle leaves are recorded for the round")
+		return
+	}
+
+	isOperator := false
+	for _, op := range state.MerkleOperators {
+		if common.HexToAddress(op) == common.HexToAddress(req.EOAAddress) {
+			isOperator = true
+			break
+		}
+	}
+	if !isOperator {
+		log.Printf("Rejected Merkle leaves request for round %s from %s: not an operator of the round", req.Round, req.EOAAddress)
+		reply(utils.StatusNotActivated, "not an operator of the Merkle tree of the round")
+		return
+	}
+
+	leaves, err := json.Marshal(utils.MerkleLeaves{
+		Round:     req.Round,
+		Operators: state.MerkleOperators,
+		Leaves:    state.MerkleLeaves,
+	})
+	if err != nil {
+		log.Printf("Failed to encode Merkle leaves of round %s: %v", req.Round, err)
+		reply(utils.StatusUnavailable, "the Merkle leaves could not be encoded")
+		return
+	}
+	resp := &utils.Response{
+		Round:  req.Round,
+		Status: utils.StatusOK,
+		State:  OperatorState(req.Round, req.EOAAddress),
+		Data:   leaves,
+	}
+	if err := utils.WriteResponse(s, cfg.Signer, resp); err != nil {
+		log.Printf("Failed to send Merkle leaves of round %s to %s: %v", req.Round, req.EOAAddress, err)
+	}
+}
@@ -2,59 +2,70 @@ package leaderNode_helper
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"log"
 	"math/big"
-	"os"
 	"strconv"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/machinebox/graphql"
+	"github.com/tokamak-network/DRB-node/config"
 	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/sensitive"
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
 // MonitorCommits continuously checks for rounds where all EOAs have submitted their secret values.
-func MonitorCommits(h host.Host) {
+func MonitorCommits(h host.Host, cfg *config.Config, drb *eth.DRBContract) {
     for {
-        checkRoundsForCompletion(h)
+        checkRoundsForCompletion(h, cfg, drb)
         time.Sleep(10 * time.Second) // Adjust the interval as needed
     }
 }
 
-func checkRoundsForCompletion(h host.Host) {
-    // Fetch EOAs for each round
-    eoasForRounds := getEOAsForRounds()
+func checkRoundsForCompletion(h host.Host, cfg *config.Config, drb *eth.DRBContract) {
+    states, err := utils.LoadRoundStates()
+    if err != nil {
+        log.Printf("Failed to load round states: %v", err)
+        return
+    }
 
-    for round := range eoasForRounds {
-        // Load the leader commits for the round
-        leaderCommits, err := loadLeaderCommits("leader_commits.json")
-        if err != nil {
-            log.Printf("Failed to load leader commits: %v", err)
+    for round, state := range states {
+        // Only rounds that are revealing or generating still need secret values
+        if state.Terminal() || !state.Reached(utils.PhaseRevealing) {
             continue
         }
 
-        // Check if the round has already generated a random number
-        if isRoundCompleted(leaderCommits, round) {
-            continue
+        // A generation transaction sent before a restart may already have landed
+        if state.Phase == utils.PhaseGenerating {
+            generated, err := isRandomNumberGeneratedOnChain(drb, round)
+            if err != nil {
+                log.Printf("Failed to read round info for round %s: %v", round, err)
+                continue
+            }
+            if generated {
+                completeRound(round)
+                continue
+            }
         }
 
-        // Check if the Merkle root has been submitted
-        if !isMerkleRootSubmitted(leaderCommits, round) {
-            log.Printf("Merkle root not submitted for round %s. Skipping random number generation.", round)
+        // Load the leader commits for the round
+        leaderCommits, err := utils.LoadAllLeaderCommitData()
+        if err != nil {
+            log.Printf("Failed to load leader commits: %v", err)
             continue
         }
 
-        // Fetch activated operators for the round
-        activatedOperators, err := FetchActivatedOperators(round)
-        if err != nil {
-            log.Printf("Failed to fetch activated operators for round %s: %v", round, err)
-            continue
+        // Use the activated operators recorded with the round, in on-chain order
+        activatedOperators := state.Operators()
+        if len(activatedOperators) == 0 {
+            activatedOperators, err = FetchActivatedOperators(cfg, drb, round)
+            if err != nil {
+                log.Printf("Failed to fetch activated operators for round %s: %v", round, err)
+                continue
+            }
         }
 
         // Filter out the `0x0000000000000000000000000000000000000000` address
@@ -75,7 +86,7 @@ func checkRoundsForCompletion(h host.Host) {
         allEOAsSubmitted := true
         for _, operator := range operatorAddresses {
             commitData, exists := leaderCommits[round+"+"+operator.Hex()]
-            if !exists || commitData.SecretValue == [32]byte{} {
+            if !exists || !commitData.HasSecret() {
                 log.Printf("EOA %s has not submitted a secret value for round %s. Initiating request.", operator.Hex(), round)
 
                 // Initiate a request for the missing secret value
@@ -85,7 +96,7 @@ func checkRoundsForCompletion(h host.Host) {
                     continue
                 }
 
-                sendSecretValueRequestToNode(h, round, operator.Hex(), nodeInfo)
+                sendSecretValueRequestToNode(h, cfg, round, operator.Hex(), nodeInfo)
                 allEOAsSubmitted = false
                 break
             }
@@ -106,6 +117,11 @@ func checkRoundsForCompletion(h host.Host) {
                 break
             }
 
+            if err := utils.UnsealLeaderCommitData(&commitData); err != nil {
+                log.Printf("Failed to decrypt the secret value of EOA %s in round %s: %v", operator.Hex(), round, err)
+                allEOAsSubmitted = false
+                break
+            }
             secrets = append(secrets, commitData.SecretValue[:])
             vs = append(vs, uint8(vValue))
             rs = append(rs, common.HexToHash(commitData.Sign["r"]))
@@ -114,30 +130,34 @@ func checkRoundsForCompletion(h host.Host) {
 
         // If all EOAs have submitted, trigger the random number generation transaction
         if allEOAsSubmitted {
+            if _, err := utils.TransitionRound(round, utils.PhaseGenerating); err != nil {
+                log.Printf("Failed to move round %s to the generating phase: %v", round, err)
+                continue
+            }
+
             log.Printf("All EOAs have submitted for round %s. Initiating random number generation.", round)
-            err := generateRandomNumberTransaction(round, secrets, vs, rs, ss, operatorAddresses)
+            err := generateRandomNumberTransaction(drb, round, secrets, vs, rs, ss, operatorAddresses)
             if err != nil {
-                log.Printf("Failed to execute random number generation transaction for round %s: %v", round, err)
+                // A rejected generation is not sent again; anything else is retried on the next check
+                failure := eth.ClassifyTxError(err)
+                log.Printf("Failed to execute random number generation transaction for round %s (%s): %v", round, failure, err)
+                if failure == eth.TxAbort {
+                    abortRound(round, fmt.Sprintf("generateRandomNumber rejected: %v", err))
+                }
             } else {
-                markRoundCompleted(leaderCommits, round)
+                completeRound(round)
             }
         }
-    }
-}
 
-// isMerkleRootSubmitted checks if the Merkle root has been submitted for a given round.
-func isMerkleRootSubmitted(leaderCommits map[string]utils.LeaderCommitData, round string) bool {
-    for _, commitData := range leaderCommits {
-        if commitData.Round == round {
-            return commitData.SubmitMerkleRootDone
+        // Zero the secret values collected for the round
+        for _, secret := range secrets {
+            sensitive.Zero(secret)
         }
     }
-    return false
 }
 
 func fetchNodeInfo(eoa string) (NodeInfo, error) {
-    filePath := "registered_nodes.json"
-    nodes, err := LoadRegisteredNodes(filePath)
+    nodes, err := LoadRegisteredNodes()
     if err != nil {
         return NodeInfo{}, fmt.Errorf("failed to load registered nodes: %v", err)
     }
@@ -162,12 +182,21 @@ func filterOperators(operators []string) []string {
 	return filtered
 }
 
-// Fetch activated operators for a specific round
-func FetchActivatedOperators(round string) ([]string, error) {
-    subGraphURL := os.Getenv("SUBGRAPH_URL")
+// FetchActivatedOperators returns the activated operators of a round in on-chain order.
+// It reads getActivatedOperatorsAtRound from the contract and falls back to the
+// configured subgraph.
+func FetchActivatedOperators(cfg *config.Config, drb *eth.DRBContract, round string) ([]string, error) {
+	operators, err := fetchActivatedOperatorsOnChain(drb, round)
+	if err == nil {
+		return operators, nil
+	}
+
+	subGraphURL := cfg.Ethereum.SubgraphURL
 	if subGraphURL == "" {
-		log.Fatal("SUBGRAPH_URL is not set in environment variables.")
+		return nil, err
 	}
+	log.Printf("Failed to read activated operators on-chain for round %s, using subgraph: %v", round, err)
+
 	client := graphql.NewClient(subGraphURL)
 	req := utils.GetActivatedOperatorsAtRoundRequest(roundToInt(round))
 
@@ -177,7 +206,7 @@ func FetchActivatedOperators(round string) ([]string, error) {
 		} `json:"randomNumberRequesteds"`
 	}
 
-	err := client.Run(context.Background(), req, &resp)
+	err = client.Run(context.Background(), req, &resp)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch activated operators: %v", err)
 	}
@@ -189,6 +218,68 @@ func FetchActivatedOperators(round string) ([]string, error) {
 	return resp.RandomNumberRequesteds[0].ActivatedOperators, nil
 }
 
+// abortRound fails a round whose generateRandomNumber transaction the contract rejects.
+func abortRound(round, reason string) {
+    if _, err := utils.FailRound(round, reason, nil); err != nil {
+        log.Printf("Failed to mark round %s as failed: %v", round, err)
+        return
+    }
+
+    report := utils.RoundReport{Round: round, Phase: utils.PhaseGenerating, Reason: reason, CreatedAt: time.Now()}
+    log.Printf("Round report: %s", report)
+    if err := utils.SaveRoundReport(report); err != nil {
+        log.Printf("%v", err)
+    }
+}
+
+// completeRound marks a round whose random number is generated as completed
+// and purges the secret values collected for it.
+func completeRound(round string) {
+    if _, err := utils.TransitionRound(round, utils.PhaseCompleted); err != nil {
+        log.Printf("Failed to mark round %s as completed: %v", round, err)
+        return
+    }
+    if err := utils.PurgeRoundSecrets(round); err != nil {
+        log.Printf("Failed to purge the secrets of round %s: %v", round, err)
+    }
+}
+
+// fetchActivatedOperatorsOnChain calls getActivatedOperatorsAtRound on the DRB contract.
+func fetchActivatedOperatorsOnChain(drb *eth.DRBContract, round string) ([]string, error) {
+	roundNum, ok := new(big.Int).SetString(round, 10)
+	if !ok {
+		return nil, fmt.Errorf("invalid round number: %s", round)
+	}
+
+	addresses, err := drb.ActivatedOperatorsAtRound(context.Background(), roundNum)
+	if err != nil {
+		return nil, err
+	}
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("no activated operators found for round %s", round)
+	}
+
+	var operators []string
+	for _, addr := range addresses {
+		operators = append(operators, addr.Hex())
+	}
+	return operators, nil
+}
+
+// isRandomNumberGeneratedOnChain reads s_roundInfo to check whether a round already has a random number.
+func isRandomNumberGeneratedOnChain(drb *eth.DRBContract, round string) (bool, error) {
+	roundNum, ok := new(big.Int).SetString(round, 10)
+	if !ok {
+		return false, fmt.Errorf("invalid round number: %s", round)
+	}
+
+	info, err := drb.RoundInfo(context.Background(), roundNum)
+	if err != nil {
+		return false, err
+	}
+	return info.RandomNumber != nil && info.RandomNumber.Sign() != 0, nil
+}
+
 // Helper: Convert round string to int
 func roundToInt(round string) int {
 	roundInt, _ := strconv.Atoi(round)
@@ -196,16 +287,22 @@ func roundToInt(round string) int {
 }
 
 // generateRandomNumberTransaction sends a transaction to generate a random number for a round.
-func generateRandomNumberTransaction(round string, secrets [][]byte, vs []uint8, rs []common.Hash, ss []common.Hash, eoas []common.Address) error {
+func generateRandomNumberTransaction(drb *eth.DRBContract, round string, secrets [][]byte, vs []uint8, rs []common.Hash, ss []common.Hash, eoas []common.Address) error {
     log.Printf("Preparing to execute generateRandomNumber...")
 
-    // Convert `secrets` from [][]byte to []common.Hash
-    var secretsHashes []common.Hash
+    // Convert `secrets`, `rs` and `ss` to the bytes32 arrays of the binding
+    var secretsHashes [][32]byte
     for _, secret := range secrets {
-        var secretHash common.Hash
+        var secretHash [32]byte
         copy(secretHash[:], secret)
         secretsHashes = append(secretsHashes, secretHash)
     }
+    defer sensitive.ZeroAll(secretsHashes)
+    var rsBytes, ssBytes [][32]byte
+    for i := range rs {
+        rsBytes = append(rsBytes, rs[i])
+        ssBytes = append(ssBytes, ss[i])
+    }
 
     // Check if secretsHashes, vs, rs, or ss are empty
     if len(secretsHashes) == 0 || len(vs) == 0 || len(rs) == 0 || len(ss) == 0 {
@@ -223,175 +320,16 @@ func generateRandomNumberTransaction(round string, secrets [][]byte, vs []uint8,
         return fmt.Errorf("invalid round number: %s", round)
     }
 
-    // Load Ethereum client and private key
-    ethRPCURL := os.Getenv("ETH_RPC_URL")
-	if ethRPCURL == "" {
-		log.Fatal("ETH_RPC_URL is not set in environment variables.")
-	}
-    client, err := ethclient.Dial(ethRPCURL)
-    if err != nil {
-        return fmt.Errorf("failed to connect to Ethereum client: %v", err)
-    }
-    defer client.Close()
-
-    privateKeyHex := os.Getenv("LEADER_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("LEADER_PRIVATE_KEY is not set in environment variables.")
-	}
-    privateKey, err := crypto.HexToECDSA(privateKeyHex)
-    if err != nil {
-        return fmt.Errorf("failed to load leader private key: %v", err)
-    }
-
-    contractAddressStr := os.Getenv("CONTRACT_ADDRESS")
-	if contractAddressStr == "" {
-		log.Fatal("CONTRACT_ADDRESS is not set in environment variables.")
-	}
-    contractAddress := common.HexToAddress(contractAddressStr)
-    parsedABI, err := utils.LoadContractABI("contract/abi/Commit2RevealDRB.json")
-    if err != nil {
-        return fmt.Errorf("failed to load contract ABI: %v", err)
-    }
-
-    clientUtils := &utils.Client{
-        Client:          client,
-        ContractAddress: contractAddress,
-        PrivateKey:      privateKey,
-        ContractABI:     parsedABI,
+    // Debugging: Log all inputs before executing the transaction, redacted unless revealed
+    for i := range secretsHashes {
+        log.Printf("Secret %d: %v, v=%d, r=%v, s=%v", i+1, sensitive.Bytes32(secretsHashes[i]), vs[i], sensitive.Bytes32(rs[i]), sensitive.Bytes32(ss[i]))
     }
 
-    // Debugging: Log all inputs before executing the transaction
-    log.Printf("Secrets: %v", secretsHashes)
-    log.Printf("VS: %v", vs)
-    log.Printf("RS: %v", rs)
-    log.Printf("SS: %v", ss)
-
-    // Prepare the function call to generateRandomNumber
-    tx, _, err := eth.ExecuteTransaction(
-        context.Background(),
-        clientUtils,
-        "generateRandomNumber",
-        big.NewInt(0),        // No Ether value
-        roundNum,             // uint256 round
-        secretsHashes,        // bytes32[] secrets
-        vs,                   // uint8[] vs
-        rs,                   // bytes32[] rs
-        ss,                   // bytes32[] ss
-    )
-
+    receipt, err := drb.GenerateRandomNumber(context.Background(), roundNum, secretsHashes, vs, rsBytes, ssBytes)
     if err != nil {
         return err
     }
 
-    log.Printf("Transaction submitted. TX Hash: %s", tx.Hash().Hex())
+    log.Printf("Transaction submitted. TX Hash: %s", receipt.TxHash.Hex())
     return nil
 }
-
-// markRoundCompleted updates the leader_commits.json file to mark a round as completed.
-func markRoundCompleted(leaderCommits map[string]utils.LeaderCommitData, round string) {
-	for key, commitData := range leaderCommits {
-		if commitData.Round == round {
-			commitData.RandomNumberGenerated = true
-			leaderCommits[key] = commitData
-		}
-	}
-
-	err := saveLeaderCommits("leader_commits.json", leaderCommits)
-	if err != nil {
-		log.Printf("Failed to save updated leader commits: %v", err)
-	}
-}
-
-// isRoundCompleted checks if a round is already completed.
-func isRoundCompleted(leaderCommits map[string]utils.LeaderCommitData, round string) bool {
-	for _, commitData := range leaderCommits {
-		if commitData.Round == round {
-			return commitData.RandomNumberGenerated
-		}
-	}
-	return false
-}
-
-// getEOAsForRounds fetches all EOAs for each round from leader_commits.json.
-func getEOAsForRounds() map[string][]common.Address {
-	eoasForRounds := make(map[string][]common.Address)
-
-	leaderCommits, err := loadLeaderCommits("leader_commits.json")
-	if err != nil {
-		log.Printf("Failed to load leader commits: %v", err)
-		return eoasForRounds
-	}
-
-	// Populate EOAs from leader commits
-	for key := range leaderCommits {
-		round, eoa := parseLeaderCommitKey(key)
-		if round == "" || eoa == "" {
-			continue
-		}
-		eoasForRounds[round] = appendIfNotExists(eoasForRounds[round], common.HexToAddress(eoa))
-	}
-
-	return eoasForRounds
-}
-
-// Helper: Parse leader commit key into round and EOA
-func parseLeaderCommitKey(key string) (string, string) {
-	split := len(key)
-	for i := len(key) - 1; i >= 0; i-- {
-		if key[i] == '+' {
-			split = i
-			break
-		}
-	}
-	if split == len(key) {
-		return "", "" // Invalid key format
-	}
-	return key[:split], key[split+1:]
-}
-
-// Helper: Append EOA to a slice only if it doesn't already exist
-func appendIfNotExists(slice []common.Address, eoa common.Address) []common.Address {
-	for _, addr := range slice {
-		if addr == eoa {
-			return slice
-		}
-	}
-	return append(slice, eoa)
-}
-
-// Helper: Load leader commits
-func loadLeaderCommits(filePath string) (map[string]utils.LeaderCommitData, error) {
-	file, err := os.Open(filePath)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("leader commit file not found")
-		}
-		return nil, fmt.Errorf("failed to open leader commit file: %v", err)
-	}
-	defer file.Close()
-
-	var data map[string]utils.LeaderCommitData
-	err = json.NewDecoder(file).Decode(&data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode leader commit file: %v", err)
-	}
-
-	return data, nil
-}
-
-// Helper: Save leader commits
-func saveLeaderCommits(filePath string, data map[string]utils.LeaderCommitData) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create leader commit file: %v", err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	err = encoder.Encode(data)
-	if err != nil {
-		return fmt.Errorf("failed to save leader commits: %v", err)
-	}
-
-	return nil
-}
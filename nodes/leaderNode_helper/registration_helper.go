@@ -3,16 +3,14 @@ package leaderNode_helper
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"math/big"
-	"os"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/tokamak-network/DRB-node/config"
 	"github.com/tokamak-network/DRB-node/eth"
 	"github.com/tokamak-network/DRB-node/utils"
 )
@@ -24,54 +22,53 @@ type NodeInfo struct {
 	PeerID  string `json:"peer_id"`
 }
 
-// LoadRegisteredNodes loads the registered nodes from a JSON file.
-func LoadRegisteredNodes(filePath string) (map[string]NodeInfo, error) {
-	file, err := os.Open(filePath)
+// LoadRegisteredNodes loads the registered nodes from the store, keyed by EOA address.
+func LoadRegisteredNodes() (map[string]NodeInfo, error) {
+	entries, err := utils.DefaultStore().List(utils.BucketRegisteredNodes)
 	if err != nil {
-		if os.IsNotExist(err) {
-			// Return an empty map if the file doesn't exist
-			return make(map[string]NodeInfo), nil
-		}
-		return nil, fmt.Errorf("failed to open registered nodes file: %v", err)
+		return nil, fmt.Errorf("failed to load registered nodes: %v", err)
 	}
-	defer file.Close()
 
-	var data map[string]NodeInfo
-	err = json.NewDecoder(file).Decode(&data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to decode registered nodes file: %v", err)
+	data := make(map[string]NodeInfo, len(entries))
+	for eoa, raw := range entries {
+		var nodeInfo NodeInfo
+		if err := json.Unmarshal(raw, &nodeInfo); err != nil {
+			return nil, fmt.Errorf("failed to decode registered node %s: %v", eoa, err)
+		}
+		data[eoa] = nodeInfo
 	}
 
 	return data, nil
 }
 
-// SaveRegisteredNodes saves the registered nodes to a JSON file.
-func SaveRegisteredNodes(filePath string, data map[string]NodeInfo) error {
-	file, err := os.Create(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to create registered nodes file: %v", err)
-	}
-	defer file.Close()
-
-	encoder := json.NewEncoder(file)
-	err = encoder.Encode(data)
-	if err != nil {
-		return fmt.Errorf("failed to write registered nodes to file: %v", err)
+// SaveRegisteredNode stores or replaces the information of a single registered node.
+func SaveRegisteredNode(eoaAddress string, nodeInfo NodeInfo) error {
+	if err := utils.DefaultStore().Put(utils.BucketRegisteredNodes, eoaAddress, nodeInfo); err != nil {
+		return fmt.Errorf("failed to write registered node %s: %v", eoaAddress, err)
 	}
 
 	return nil
 }
 
-// RegisterNode handles both saving node information and activating the node on-chain.
-func RegisterNode(s network.Stream, filePath, abiFilePath string) error {
+// ErrInsufficientDeposit is returned by ActivateOnChain for an operator whose
+// deposit is below the activation threshold.
+var ErrInsufficientDeposit = errors.New("deposit amount is insufficient")
+
+// RegisterNode handles both saving node information and activating the node
+// on-chain, and answers the request with the outcome.
+func RegisterNode(cfg *config.Config, drb *eth.DRBContract, s network.Stream) error {
 	var req utils.RegistrationRequest
-	if err := json.NewDecoder(s).Decode(&req); err != nil {
-		return fmt.Errorf("failed to decode registration request: %v", err)
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		Reply(cfg, s, "", "", utils.StatusInvalid, "the request could not be verified")
+		return fmt.Errorf("rejected registration request from peer %s: %v", s.Conn().RemotePeer(), err)
 	}
 
-	if !utils.VerifySignature(req) {
-		return fmt.Errorf("failed to verify signature for PeerID: %s", req.PeerID)
+	if !env.SentBy(req.EOAAddress) || req.PeerID != env.PeerID {
+		Reply(cfg, s, "", "", utils.StatusInvalid, "the request is not signed by its EOA from its peer ID")
+		return fmt.Errorf("registration of EOA %s PeerID %s was signed by %s from PeerID %s", req.EOAAddress, req.PeerID, env.Sender, env.PeerID)
 	}
+	req.EOAAddress = common.HexToAddress(req.EOAAddress).Hex()
 
 	log.Printf("Verified registration for PeerID: %s", req.PeerID)
 
@@ -79,75 +76,53 @@ func RegisterNode(s network.Stream, filePath, abiFilePath string) error {
 	remoteAddr := s.Conn().RemoteMultiaddr().String()
 	parts := strings.Split(remoteAddr, "/")
 	if len(parts) < 5 {
+		Reply(cfg, s, "", req.EOAAddress, utils.StatusInvalid, "unsupported remote address")
 		return fmt.Errorf("invalid remote address format: %s", remoteAddr)
 	}
 
 	ip := parts[2]  // Extract IP
 	port := parts[4] // Extract port
 
-	// Load existing nodes
-	nodes, err := LoadRegisteredNodes(filePath)
-	if err != nil {
-		return fmt.Errorf("failed to load registered nodes: %v", err)
-	}
-
 	// Update or add the node information
-	nodes[req.EOAAddress] = NodeInfo{
+	err = SaveRegisteredNode(req.EOAAddress, NodeInfo{
 		IP:     ip,
 		Port:   port,
 		PeerID: req.PeerID,
-	}
-
-	// Save updated nodes
-	err = SaveRegisteredNodes(filePath, nodes)
+	})
 	if err != nil {
+		Reply(cfg, s, "", req.EOAAddress, utils.StatusUnavailable, "the registration could not be stored")
 		return fmt.Errorf("failed to save registered nodes: %v", err)
 	}
 
 	log.Printf("Successfully registered or updated EOA %s with NodeInfo: IP=%s, Port=%s, PeerID=%s.", req.EOAAddress, ip, port, req.PeerID)
 
 	// Perform on-chain activation
-	err = ActivateOnChain(req.EOAAddress, abiFilePath)
+	err = ActivateOnChain(drb, req.EOAAddress)
+	if errors.Is(err, ErrInsufficientDeposit) {
+		Reply(cfg, s, "", req.EOAAddress, utils.StatusNotActivated, err.Error())
+		return fmt.Errorf("failed to activate EOA %s on-chain: %v", req.EOAAddress, err)
+	}
 	if err != nil {
+		Reply(cfg, s, "", req.EOAAddress, utils.StatusUnavailable, "registered, but the on-chain activation failed")
 		return fmt.Errorf("failed to activate EOA %s on-chain: %v", req.EOAAddress, err)
 	}
 
 	log.Printf("Successfully activated EOA %s on-chain.", req.EOAAddress)
+	Reply(cfg, s, "", req.EOAAddress, utils.StatusOK, "")
 	return nil
 }
 
 // ActivateOnChain handles the on-chain activation of the node.
-func ActivateOnChain(eoaAddress, abiFilePath string) error {
-	ethRPCURL := os.Getenv("ETH_RPC_URL")
-	if ethRPCURL == "" {
-		log.Fatal("ETH_RPC_URL is not set in the environment variables")
-	}
-
-	client, err := ethclient.Dial(ethRPCURL)
-	if err != nil {
-		return fmt.Errorf("failed to connect to Ethereum client: %v", err)
-	}
-
-	contractAddressStr := os.Getenv("CONTRACT_ADDRESS")
-	if contractAddressStr == "" {
-		log.Fatal("CONTRACT_ADDRESS is not set in environment variables.")
-	}
-
-	contractAddress := common.HexToAddress(contractAddressStr)
-	parsedABI, err := utils.LoadContractABI(abiFilePath)
-	if err != nil {
-		return fmt.Errorf("failed to load contract ABI: %v", err)
-	}
-
+func ActivateOnChain(drb *eth.DRBContract, eoaAddress string) error {
+	ctx := context.Background()
 	operatorAddress := common.HexToAddress(eoaAddress)
 
 	// Verify if the operator is activated
-	activatedOperatorsResult, err := eth.CallSmartContract(client, parsedABI, "getActivatedOperators", contractAddress)
+	activatedOperators, err := drb.ActivatedOperators(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to call getActivatedOperators: %v", err)
+		return err
 	}
 
-	activatedOperators := activatedOperatorsResult.([]common.Address)
 	for _, operator := range activatedOperators {
 		if operator == operatorAddress {
 			log.Printf("Operator %s is already activated.", eoaAddress)
@@ -156,47 +131,22 @@ func ActivateOnChain(eoaAddress, abiFilePath string) error {
 	}
 
 	// Check deposit amount and activation threshold
-	depositAmountResult, err := eth.CallSmartContract(client, parsedABI, "s_depositAmount", contractAddress, operatorAddress)
+	depositAmount, err := drb.DepositAmount(ctx, operatorAddress)
 	if err != nil {
-		return fmt.Errorf("failed to call s_depositAmount: %v", err)
+		return err
 	}
-	depositAmount := depositAmountResult.(*big.Int)
 
-	activationThresholdResult, err := eth.CallSmartContract(client, parsedABI, "s_activationThreshold", contractAddress)
+	activationThreshold, err := drb.ActivationThreshold(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to call s_activationThreshold: %v", err)
+		return err
 	}
-	activationThreshold := activationThresholdResult.(*big.Int)
 
 	if depositAmount.Cmp(activationThreshold) < 0 {
-		return fmt.Errorf("deposit amount is insufficient. Deposit: %s, Threshold: %s", depositAmount, activationThreshold)
+		return fmt.Errorf("%w. Deposit: %s, Threshold: %s", ErrInsufficientDeposit, depositAmount, activationThreshold)
 	}
 
 	// Activate the operator
-	privateKeyHex := os.Getenv("LEADER_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("LEADER_PRIVATE_KEY is not set in environment variables.")
-	}
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		return fmt.Errorf("failed to decode leader private key: %v", err)
-	}
-
-	clientUtils := &utils.Client{
-		Client:          client,
-		ContractAddress: contractAddress,
-		PrivateKey:      privateKey,
-		ContractABI:     parsedABI,
-	}
-
-	_, _, err = eth.ExecuteTransaction(
-		context.Background(),
-		clientUtils,
-		"activate",
-		big.NewInt(0),
-		operatorAddress,
-	)
-	if err != nil {
+	if _, err := drb.Activate(ctx, operatorAddress); err != nil {
 		return fmt.Errorf("failed to activate operator: %v", err)
 	}
 
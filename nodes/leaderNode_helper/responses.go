@@ -0,0 +1,57 @@
+package leaderNode_helper
+
+import (
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// OperatorState returns the leader's view of eoaAddress in a round, as far as
+// it is known.
+func OperatorState(roundNum, eoaAddress string) *utils.OperatorState {
+	eoa := common.HexToAddress(eoaAddress).Hex()
+	var view utils.OperatorState
+
+	if nodes, err := LoadRegisteredNodes(); err == nil {
+		_, view.Registered = nodes[eoa]
+	}
+	if roundNum == "" {
+		return &view
+	}
+
+	if state, err := utils.LoadRoundState(roundNum); err == nil {
+		view.Phase = state.Phase
+		view.Participant = contains(state.Operators(), eoa)
+	}
+	if commitData, err := utils.LoadLeaderCommitData(roundNum, eoa); err == nil {
+		view.CvsReceived = commitData.Cvs != [32]byte{}
+		view.CosReceived = commitData.Cos != [32]byte{}
+		view.SecretReceived = commitData.HasSecret()
+	}
+	return &view
+}
+
+// IsRegistered reports whether eoaAddress is registered from the libp2p peer peerID.
+func IsRegistered(eoaAddress, peerID string) (bool, error) {
+	nodes, err := LoadRegisteredNodes()
+	if err != nil {
+		return false, err
+	}
+	nodeInfo, ok := nodes[common.HexToAddress(eoaAddress).Hex()]
+	return ok && nodeInfo.PeerID == peerID, nil
+}
+
+// Reply answers a request of eoaAddress for a round on stream s, with the
+// leader's view of the operator. eoaAddress is empty when the sender is unknown.
+func Reply(cfg *config.Config, s network.Stream, roundNum, eoaAddress string, status utils.Status, reason string) {
+	resp := &utils.Response{Round: roundNum, Status: status, Reason: reason}
+	if eoaAddress != "" {
+		resp.State = OperatorState(roundNum, eoaAddress)
+	}
+	if err := utils.WriteResponse(s, cfg.Signer, resp); err != nil {
+		log.Printf("Failed to answer %s request for round %s: %v", s.Protocol(), roundNum, err)
+	}
+}
@@ -1,139 +1,214 @@
 package leaderNode_helper
 
 import (
+	"encoding/hex"
+	"fmt"
 	"log"
-	"os"
+	"time"
 
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
-	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
-// Tracks EOAs that have been sent requests per round
-var revealRequestStatus = make(map[string][]string)
+// regularResponseTimeout is how long the leader waits for a regular node to
+// answer a request.
+const regularResponseTimeout = time.Minute
 
-// StartSecretValueRequests initializes the secret value request process for a given round
-func StartSecretValueRequests(h host.Host, roundNum string) {
-	// Load reveal order for the round
-	revealData, err := commitreveal2.LoadRevealOrders("reveal_orders.json")
+// StartSecretValueRequests records the reveal order of a round, moves it to the
+// Revealing phase, publishes the order to the participants and requests the
+// secret value of the first node in the order.
+func StartSecretValueRequests(h host.Host, cfg *config.Config, roundNum string) {
+	order, err := utils.LoadRevealOrder(roundNum)
 	if err != nil {
-		log.Printf("Failed to load reveal orders: %v", err)
+		log.Printf("%v", err)
 		return
 	}
+	orderedNodes := order.OrderedNodes
 
-	roundRevealData, exists := revealData[roundNum].(map[string]interface{})
-	if !exists {
-		log.Printf("No reveal order found for round %s.", roundNum)
-		return
-	}
-
-	orderedNodes, ok := roundRevealData["ordered_nodes"].([]interface{})
-	if !ok {
-		log.Printf("Reveal order is invalid or missing for round %s.", roundNum)
+	_, err = utils.UpdateRoundState(roundNum, func(state *utils.RoundState) error {
+		state.RevealOrder = orderedNodes
+		return state.Transition(utils.PhaseRevealing)
+	})
+	if err != nil {
+		log.Printf("Failed to move round %s to the revealing phase: %v", roundNum, err)
 		return
 	}
 
 	// Load registered nodes
-	filePath := "registered_nodes.json"
-	nodes, err := LoadRegisteredNodes(filePath)
+	nodes, err := LoadRegisteredNodes()
 	if err != nil {
 		log.Printf("Failed to load registered nodes: %v", err)
 		return
 	}
 
-	// Initialize reveal request status for the round if not already done
-	if _, exists := revealRequestStatus[roundNum]; !exists {
-		revealRequestStatus[roundNum] = []string{}
-	}
+	publishRevealOrder(h, cfg, order, nodes)
 
 	// Send the request to the first node in the reveal order
-	for _, node := range orderedNodes {
-		eoa := node.(string)
+	for _, eoa := range orderedNodes {
 		nodeInfo, exists := nodes[eoa]
 		if !exists {
 			log.Printf("Node info for EOA %s not found in registered nodes.", eoa)
 			continue
 		}
 
-		sendSecretValueRequestToNode(h, roundNum, eoa, nodeInfo)
+		sendSecretValueRequestToNode(h, cfg, roundNum, eoa, nodeInfo)
 		break
 	}
 }
 
-func sendSecretValueRequestToNode(h host.Host, roundNum string, eoa string, nodeInfo NodeInfo) {
-	// Load private key from environment variable
-	privateKeyHex := os.Getenv("LEADER_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("LEADER_PRIVATE_KEY is not set in environment variables.")
+// ResumeSecretValueRequests continues a round in the Revealing phase after a
+// restart by requesting the secret value of the first node in the reveal order
+// that has not delivered one yet.
+func ResumeSecretValueRequests(h host.Host, cfg *config.Config, roundNum string) {
+	state, err := utils.LoadRoundState(roundNum)
+	if err != nil {
+		log.Printf("Failed to load round state for round %s: %v", roundNum, err)
+		return
 	}
 
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	nodes, err := LoadRegisteredNodes()
 	if err != nil {
-		log.Printf("Failed to decode leader private key: %v", err)
+		log.Printf("Failed to load registered nodes: %v", err)
 		return
 	}
 
-	eoaAddress := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
-	log.Printf("EOA Address: %s", eoaAddress)
+	if order, err := utils.LoadRevealOrder(roundNum); err == nil {
+		publishRevealOrder(h, cfg, order, nodes)
+	} else {
+		log.Printf("%v", err)
+	}
 
-	// Sign the round number
-	signature := utils.SignData(eoaAddress, privateKey)
+	for _, eoa := range state.RevealOrder {
+		commitData, err := utils.LoadLeaderCommitData(roundNum, eoa)
+		if err == nil && commitData.HasSecret() {
+			continue
+		}
 
-	// Create the secret value request
-	req := utils.SecretValueRequest{
-		EOAAddress: eoaAddress, // Leader's EOA
-		Round:      roundNum,                // Round number
-		Signature:  signature,               // Signed round number
+		nodeInfo, exists := nodes[eoa]
+		if !exists {
+			log.Printf("Node info for EOA %s not found in registered nodes.", eoa)
+			return
+		}
+		log.Printf("Resuming secret value requests for round %s at EOA %s", roundNum, eoa)
+		sendSecretValueRequestToNode(h, cfg, roundNum, eoa, nodeInfo)
+		return
 	}
+}
 
-	// Send the request
-	err = sendToRegularNode(h, nodeInfo, "/sendSecretValue", req)
-	if err != nil {
-		log.Printf("Failed to send secret value request to EOA %s for round %s: %v", eoa, roundNum, err)
-	} else {
-		log.Printf("Secret value request sent to EOA %s for round %s", eoa, roundNum)
+// publishRevealOrder sends the reveal order of a round, with the COS values it
+// was computed from, to every operator in it so they can check it. The answers
+// are awaited in the background, as each node checks the order on-chain first.
+func publishRevealOrder(h host.Host, cfg *config.Config, order *utils.RevealOrder, nodes map[string]NodeInfo) {
+	for _, eoa := range order.Operators {
+		nodeInfo, exists := nodes[eoa]
+		if !exists {
+			log.Printf("Node info for EOA %s not found in registered nodes.", eoa)
+			continue
+		}
+		go func(eoa string, nodeInfo NodeInfo) {
+			resp, err := sendToRegularNode(h, cfg, eoa, nodeInfo, utils.ProtocolRevealOrder, order.Round, order, nil)
+			if err != nil {
+				log.Printf("Failed to send reveal order of round %s to EOA %s: %v", order.Round, eoa, err)
+			} else if resp.Status == utils.StatusRefused {
+				log.Printf("ALERT: EOA %s refused the reveal order of round %s: %s", eoa, order.Round, resp.Reason)
+			} else if !resp.Status.Accepted() {
+				log.Printf("EOA %s did not accept the reveal order of round %s: %v", eoa, order.Round, resp)
+			}
+		}(eoa, nodeInfo)
+	}
+}
 
-		// Mark this EOA as requested
-		revealRequestStatus[roundNum] = append(revealRequestStatus[roundNum], eoa)
+// previousSecrets returns the hex encoded secret values of the nodes before eoa
+// in the reveal order. A node only reveals once all of them are known.
+func previousSecrets(order *utils.RevealOrder, eoa string) ([]string, error) {
+	var secrets []string
+	for _, node := range order.OrderedNodes {
+		if node == eoa {
+			return secrets, nil
+		}
+		commitData, err := utils.LoadLeaderCommitData(order.Round, node)
+		if err != nil || !commitData.HasSecret() {
+			return nil, fmt.Errorf("secret value of EOA %s is not received yet", node)
+		}
+		if err := utils.UnsealLeaderCommitData(commitData); err != nil {
+			return nil, err
+		}
+		secrets = append(secrets, hex.EncodeToString(commitData.SecretValue[:]))
 	}
+	return nil, fmt.Errorf("EOA %s is not in the reveal order", eoa)
 }
 
-// handleSecretValueResponse processes a response and sends the next request if applicable
-func HandleSecretValueResponse(h host.Host, roundNum string, eoa string) {
-	log.Printf("Secret value received for round %s from EOA %s", roundNum, eoa)
+func sendSecretValueRequestToNode(h host.Host, cfg *config.Config, roundNum string, eoa string, nodeInfo NodeInfo) {
+	eoaAddress := cfg.Address().Hex()
+	log.Printf("EOA Address: %s", eoaAddress)
 
-	// Load reveal order for the round
-	revealData, err := commitreveal2.LoadRevealOrders("reveal_orders.json")
+	order, err := utils.LoadRevealOrder(roundNum)
 	if err != nil {
-		log.Printf("Failed to load reveal orders: %v", err)
+		log.Printf("%v", err)
 		return
 	}
-
-	roundRevealData, exists := revealData[roundNum].(map[string]interface{})
-	if !exists {
-		log.Printf("No reveal order found for round %s.", roundNum)
+	secrets, err := previousSecrets(order, eoa)
+	if err != nil {
+		log.Printf("Not requesting the secret value of EOA %s for round %s: %v", eoa, roundNum, err)
 		return
 	}
 
-	orderedNodes, ok := roundRevealData["ordered_nodes"].([]interface{})
-	if !ok {
-		log.Printf("Reveal order is invalid or missing for round %s.", roundNum)
+	// Create the secret value request, with the order the node checks it against
+	req := utils.SecretValueRequest{
+		EOAAddress:      eoaAddress, // Leader's EOA
+		Round:           roundNum,   // Round number
+		RevealOrder:     order,
+		PreviousSecrets: secrets,
+	}
+
+	// Send the request, signed in an envelope, and mark this EOA as requested
+	// before the node answers, as its secret value may arrive first
+	requested := func() {
+		_, err := utils.UpdateRoundState(roundNum, func(state *utils.RoundState) error {
+			if !contains(state.RevealRequested, eoa) {
+				state.RevealRequested = append(state.RevealRequested, eoa)
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Failed to record secret value request to EOA %s for round %s: %v", eoa, roundNum, err)
+		}
+	}
+	resp, err := sendToRegularNode(h, cfg, eoa, nodeInfo, utils.ProtocolRevealRequest, roundNum, req, requested)
+	switch {
+	case err != nil:
+		log.Printf("Failed to send secret value request to EOA %s for round %s: %v", eoa, roundNum, err)
+	case resp.Status.Accepted():
+		log.Printf("Secret value request sent to EOA %s for round %s", eoa, roundNum)
+	case resp.Status == utils.StatusRefused:
+		log.Printf("ALERT: EOA %s refused to reveal its secret value for round %s: %s", eoa, roundNum, resp.Reason)
+	default:
+		// Secret values still missing are requested again while the round lasts
+		log.Printf("EOA %s did not reveal its secret value for round %s: %v", eoa, roundNum, resp)
+	}
+}
+
+// handleSecretValueResponse processes a response and sends the next request if applicable
+func HandleSecretValueResponse(h host.Host, cfg *config.Config, roundNum string, eoa string) {
+	log.Printf("Secret value received for round %s from EOA %s", roundNum, eoa)
+
+	state, err := utils.LoadRoundState(roundNum)
+	if err != nil {
+		log.Printf("Failed to load round state for round %s: %v", roundNum, err)
 		return
 	}
 
 	// Load registered nodes
-	filePath := "registered_nodes.json"
-	nodes, err := LoadRegisteredNodes(filePath)
+	nodes, err := LoadRegisteredNodes()
 	if err != nil {
 		log.Printf("Failed to load registered nodes: %v", err)
 		return
 	}
 
 	// Check which node is next in the reveal order
-	for _, node := range orderedNodes {
-		nodeEOA := node.(string)
-		if !contains(revealRequestStatus[roundNum], nodeEOA) {
+	for _, nodeEOA := range state.RevealOrder {
+		if !contains(state.RevealRequested, nodeEOA) {
 			nodeInfo, exists := nodes[nodeEOA]
 			if !exists {
 				log.Printf("Node info for EOA %s not found in registered nodes.", nodeEOA)
@@ -141,7 +216,7 @@ func HandleSecretValueResponse(h host.Host, roundNum string, eoa string) {
 			}
 
 			// Send secret value request to the next node
-			sendSecretValueRequestToNode(h, roundNum, nodeEOA, nodeInfo)
+			sendSecretValueRequestToNode(h, cfg, roundNum, nodeEOA, nodeInfo)
 			return
 		}
 	}
@@ -149,20 +224,33 @@ func HandleSecretValueResponse(h host.Host, roundNum string, eoa string) {
 	log.Printf("All nodes processed for round %s.", roundNum)
 }
 
-// sendToRegularNode sends a request to a specific regular node
-func sendToRegularNode(h host.Host, nodeInfo NodeInfo, protocol string, data interface{}) error {
+// sendToRegularNode sends data for a round to the registered node of eoa in an
+// envelope signed with the leader key, and returns the node's response, which
+// must be signed by eoa. sent, if not nil, is called once the request is written.
+func sendToRegularNode(h host.Host, cfg *config.Config, eoa string, nodeInfo NodeInfo, protocol string, roundNum string, data interface{}, sent func()) (*utils.Response, error) {
 	stream, err := utils.CreateStream(h, utils.NodeInfo{
 		IP:     nodeInfo.IP,
 		Port:   nodeInfo.Port,
 		PeerID: nodeInfo.PeerID,
 	}, protocol)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	defer stream.Close()
 
-	// Send the encoded data
-	return utils.SendDataOverStream(stream, data)
+	// The node checks the request on-chain before it answers
+	stream.SetDeadline(time.Now().Add(regularResponseTimeout))
+
+	// Send the signed data
+	if err := utils.WriteEnvelope(stream, cfg.Signer, roundNum, data); err != nil {
+		return nil, err
+	}
+	if sent != nil {
+		sent()
+	}
+
+	resp, _, err := utils.ReadResponse(stream, roundNum, eoa)
+	return resp, err
 }
 
 // contains checks if an item exists in a slice
@@ -1,64 +1,84 @@
 package leaderNode_helper
 
 import (
-	"encoding/hex"
-	"encoding/json"
+	"bytes"
 	"log"
 
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/tokamak-network/DRB-node/config"
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
-// AcceptSecretValue processes and stores secret values sent by regular nodes.
-func AcceptSecretValue(h host.Host, s network.Stream) {
+// AcceptSecretValue processes and stores secret values sent by regular nodes,
+// and answers each with the outcome.
+func AcceptSecretValue(h host.Host, cfg *config.Config, s network.Stream) {
 	defer s.Close()
 
-	// Decode the incoming request
+	// Decode and verify the incoming request
 	var req utils.SecretValueRequest
-	if err := json.NewDecoder(s).Decode(&req); err != nil {
-		log.Printf("Failed to decode secret value request: %v", err)
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		log.Printf("Rejected secret value from peer %s: %v", s.Conn().RemotePeer(), err)
+		Reply(cfg, s, "", "", utils.StatusInvalid, "the request could not be verified")
 		return
 	}
+	defer req.SecretValue.Zero()
 
-	// Verify the EOA signature
-	verifyReq := utils.RegistrationRequest{
-		EOAAddress: req.EOAAddress,
-		Signature:  req.Signature,
-	}
-
-	if !utils.VerifySignature(verifyReq) {
-		log.Printf("Signature verification failed for secret value request from EOA: %s", req.EOAAddress)
+	if env.Round != req.Round || !env.SentBy(req.EOAAddress) {
+		log.Printf("Envelope of %s for round %s does not match secret value for round %s from EOA %s", env.Sender, env.Round, req.Round, req.EOAAddress)
+		Reply(cfg, s, env.Round, env.Sender, utils.StatusInvalid, "the request does not match its envelope")
 		return
 	}
+	req.EOAAddress = common.HexToAddress(req.EOAAddress).Hex()
+	reply := func(status utils.Status, reason string) {
+		Reply(cfg, s, req.Round, req.EOAAddress, status, reason)
+	}
 
 	log.Printf("Successfully verified signature for EOA: %s", req.EOAAddress)
 
-	// Fetch or initialize the leader commit data for the given round and EOA
+	// The secret value must open the COS the operator sent for the round
 	commitData, err := utils.LoadLeaderCommitData(req.Round, req.EOAAddress)
-	if err != nil {
-		log.Printf("Commit data not found, initializing new entry for round %s and EOA %s", req.Round, req.EOAAddress)
-		commitData = &utils.LeaderCommitData{
-			Round:      req.Round,
-			EOAAddress: req.EOAAddress,
-		}
+	if err != nil || commitData.Cos == [32]byte{} {
+		log.Printf("No COS found for round %s EOA %s, rejecting secret value.", req.Round, req.EOAAddress)
+		reply(utils.StatusConflict, "no COS was received from this operator for the round")
+		return
+	}
+	if len(req.SecretValue) != 32 {
+		log.Printf("Rejected secret value of %d bytes for round %s EOA %s", len(req.SecretValue), req.Round, req.EOAAddress)
+		reply(utils.StatusInvalid, "the secret value is not 32 bytes")
+		return
+	}
+	if !bytes.Equal(crypto.Keccak256(req.SecretValue), commitData.Cos[:]) {
+		log.Printf("Secret value hash mismatch for round %s EOA %s. Rejecting secret value.", req.Round, req.EOAAddress)
+		reply(utils.StatusConflict, "the hash of the secret value does not match the COS")
+		return
+	}
+	if commitData.HasSecret() {
+		log.Printf("Secret value already received for round %s EOA %s. Skipping.", req.Round, req.EOAAddress)
+		reply(utils.StatusDuplicate, "")
+		return
 	}
 
-	// Store the secret value in both byte array and hex string formats
-	copy(commitData.SecretValue[:], req.SecretValue[:])
-	commitData.SecretValueHex = hex.EncodeToString(req.SecretValue[:])
+	// Store the secret value; saving moves it into the secret store
+	copy(commitData.SecretValue[:], req.SecretValue)
 
-	log.Printf("Received and stored secret value for round %s and EOA %s: byte=%x, hex=%s",
-		req.Round, req.EOAAddress, commitData.SecretValue, commitData.SecretValueHex)
+	log.Printf("Received secret value for round %s and EOA %s: %v", req.Round, req.EOAAddress, commitData.SecretValue)
 
 	// Save the updated commit data
-	if err := utils.SaveLeaderCommitData(*commitData); err != nil {
+	err = utils.SaveLeaderCommitData(*commitData)
+	commitData.SecretValue.Zero()
+	if err != nil {
 		log.Printf("Failed to save leader commit data for round %s and EOA %s: %v", req.Round, req.EOAAddress, err)
+		reply(utils.StatusUnavailable, "the secret value could not be stored")
 		return
 	}
 
 	log.Printf("Successfully saved secret value for round %s and EOA %s", req.Round, req.EOAAddress)
+	reply(utils.StatusOK, "")
 
 	// Continue requesting secret values from remaining nodes in the reveal order
-	HandleSecretValueResponse(h, req.Round, req.EOAAddress)
+	HandleSecretValueResponse(h, cfg, req.Round, req.EOAAddress)
 }
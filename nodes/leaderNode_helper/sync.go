@@ -0,0 +1,152 @@
+package leaderNode_helper
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// HandleSyncRequest answers a registered operator with the leader's records of
+// it in the rounds of the request, on the same stream.
+func HandleSyncRequest(cfg *config.Config, s network.Stream) {
+	defer s.Close()
+
+	var req utils.SyncRequest
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		log.Printf("Rejected sync request from peer %s: %v", s.Conn().RemotePeer(), err)
+		Reply(cfg, s, "", "", utils.StatusInvalid, "the request could not be verified")
+		return
+	}
+	if env.Round != "" || !env.SentBy(req.EOAAddress) {
+		log.Printf("Envelope of %s for round %s does not match sync request from %s", env.Sender, env.Round, req.EOAAddress)
+		Reply(cfg, s, env.Round, env.Sender, utils.StatusInvalid, "the request does not match its envelope")
+		return
+	}
+	eoaAddress := common.HexToAddress(req.EOAAddress).Hex()
+	reply := func(status utils.Status, reason string) {
+		Reply(cfg, s, "", eoaAddress, status, reason)
+	}
+
+	registered, err := IsRegistered(eoaAddress, env.PeerID)
+	if err != nil {
+		log.Printf("Failed to check the registration of EOA %s: %v", eoaAddress, err)
+		reply(utils.StatusUnavailable, "the registration could not be checked")
+		return
+	}
+	if !registered {
+		reply(utils.StatusNotRegistered, fmt.Sprintf("EOA %s is not registered from peer %s", eoaAddress, env.PeerID))
+		return
+	}
+
+	records := make([]utils.RoundSync, 0, len(req.Rounds))
+	for _, round := range req.Rounds {
+		record := roundRecord(round.Round, eoaAddress)
+		if round.Cvs != "" && record.Cvs != "" && round.Cvs != record.Cvs {
+			log.Printf("ALERT: EOA %s holds another CVS for round %s than the leader", eoaAddress, round.Round)
+		}
+		records = append(records, record)
+	}
+
+	data, err := json.Marshal(records)
+	if err != nil {
+		log.Printf("Failed to encode the records of EOA %s: %v", eoaAddress, err)
+		reply(utils.StatusUnavailable, "the records could not be encoded")
+		return
+	}
+	resp := &utils.Response{
+		Status: utils.StatusOK,
+		State:  OperatorState("", eoaAddress),
+		Data:   data,
+	}
+	if err := utils.WriteResponse(s, cfg.Signer, resp); err != nil {
+		log.Printf("Failed to send the records of %d rounds to EOA %s: %v", len(records), eoaAddress, err)
+		return
+	}
+	log.Printf("Sent the records of %d rounds to EOA %s", len(records), eoaAddress)
+}
+
+// RequestSync asks every registered operator that has not delivered the CVS
+// or COS an open round is waiting for to send it again. The leader calls it
+// once it knows the open rounds after a start.
+func RequestSync(h host.Host, cfg *config.Config) {
+	states, err := utils.LoadRoundStates()
+	if err != nil {
+		log.Printf("Failed to load round states: %v", err)
+		return
+	}
+
+	nodes, err := LoadRegisteredNodes()
+	if err != nil {
+		log.Printf("Failed to load registered nodes: %v", err)
+		return
+	}
+
+	// Collect the rounds each operator is missing from
+	missing := make(map[string][]utils.RoundSync)
+	for round, state := range states {
+		var waitingForCos bool
+		switch state.Phase {
+		case utils.PhaseRequested, utils.PhaseCollectingCVS:
+		case utils.PhaseMerkleSubmitted, utils.PhaseCollectingCOS:
+			waitingForCos = true
+		default:
+			continue
+		}
+
+		for _, op := range state.Operators() {
+			eoa := common.HexToAddress(op).Hex()
+			record := roundRecord(round, eoa)
+			if (waitingForCos && record.State.CosReceived) || (!waitingForCos && record.State.CvsReceived) {
+				continue
+			}
+			missing[eoa] = append(missing[eoa], record)
+		}
+	}
+
+	for eoa, rounds := range missing {
+		nodeInfo, exists := nodes[eoa]
+		if !exists {
+			log.Printf("Node info for EOA %s not found in registered nodes.", eoa)
+			continue
+		}
+		go requestSyncFromNode(h, cfg, eoa, nodeInfo, rounds)
+	}
+}
+
+// requestSyncFromNode sends the leader's records of eoa in some rounds to its
+// node, which sends again what the leader is missing.
+func requestSyncFromNode(h host.Host, cfg *config.Config, eoa string, nodeInfo NodeInfo, rounds []utils.RoundSync) {
+	req := utils.SyncRequest{
+		EOAAddress: cfg.Address().Hex(), // Leader's EOA
+		Rounds:     rounds,
+	}
+
+	resp, err := sendToRegularNode(h, cfg, eoa, nodeInfo, utils.ProtocolSync, "", req, nil)
+	switch {
+	case err != nil:
+		log.Printf("Failed to send sync request to EOA %s: %v", eoa, err)
+	case resp.Status.Accepted():
+		log.Printf("EOA %s is reconciling %d rounds with the leader", eoa, len(rounds))
+	case resp.Status == utils.StatusRefused:
+		log.Printf("ALERT: EOA %s refused the sync request: %s", eoa, resp.Reason)
+	default:
+		log.Printf("EOA %s did not accept the sync request: %v", eoa, resp)
+	}
+}
+
+// roundRecord returns what the leader holds of eoaAddress in a round.
+func roundRecord(roundNum, eoaAddress string) utils.RoundSync {
+	record := utils.RoundSync{Round: roundNum, State: OperatorState(roundNum, eoaAddress)}
+	if commitData, err := utils.LoadLeaderCommitData(roundNum, eoaAddress); err == nil && commitData.Cvs != [32]byte{} {
+		record.Cvs = hex.EncodeToString(commitData.Cvs[:])
+	}
+	return record
+}
@@ -2,77 +2,53 @@ package nodes
 
 import (
 	"context"
-	"crypto/ecdsa"
-	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
-	"os"
 	"time"
 
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	core "github.com/libp2p/go-libp2p/core"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
-	"github.com/libp2p/go-libp2p/core/peerstore"
 	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
 	"github.com/tokamak-network/DRB-node/libp2putils"
 	"github.com/tokamak-network/DRB-node/nodes/regularNode_helper"
-	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/signer"
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
-const abiFilePath = "contract/abi/Commit2RevealDRB.json"
-
-// RunRegularNode handles the behavior for a regular node
-func RunRegularNode() {
+// RunRegularNode handles the behavior for a regular node with a validated
+// configuration. drb is the process-wide contract client.
+func RunRegularNode(cfg *config.Config, drb *eth.DRBContract) {
 	ctx := context.Background()
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		log.Fatal("PORT not set in environment variables.")
-	}
-	h, peerID, err := libp2putils.CreateHost(port)
+	port := cfg.Regular.Port
+	h, peerID, err := libp2putils.CreateHost(port, cfg.IdentityPassphrase())
 	if err != nil {
 		log.Fatalf("Error creating host: %v", err)
 	}
 
 	defer h.Close()
 
-	h.SetStreamHandler("/sendSecretValue", func(s network.Stream) {
-		regularNode_helper.HandleSecretValueRequest(h, s)
-	})
-
-	// Get leader's multiaddress
-	leaderIP := os.Getenv("LEADER_IP")
-	if leaderIP == "" {
-		log.Fatal("LEADER_IP is not set in environment variables.")
-	}
-
-	leaderPort := os.Getenv("LEADER_PORT")
-	if leaderPort == "" {
-		log.Fatal("LEADER_PORT is not set in environment variables.")
-	}
-
-	leaderPeerID := os.Getenv("LEADER_PEER_ID")
-	if leaderPeerID == "" {
-		log.Fatal("LEADER_PEER_ID is not set in environment variables.")
-	}
-
-	privateKeyHex := os.Getenv("EOA_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("EOA_PRIVATE_KEY is not set in the environment variables")
-	}
-
-	// The Ethereum private key is used separately for Ethereum transactions
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		log.Fatalf("Failed to decode Ethereum private key: %v", err)
+	// Secret values are derived from the master seed when one is configured
+	var seed *commitreveal2.SecretSeed
+	if seedBytes, err := cfg.SecretSeed(); err != nil {
+		log.Fatalf("Error reading secret seed: %v", err)
+	} else if seedBytes != nil {
+		seed, err = commitreveal2.NewSecretSeed(seedBytes, cfg.ChainID(), cfg.ContractAddress())
+		if err != nil {
+			log.Fatalf("Error reading secret seed: %v", err)
+		}
+		log.Println("Secret values are derived from the secret seed.")
 	}
 
-	eoaAddress := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
+	// The Ethereum key signs transactions and the requests sent to the leader
+	eoaSigner := cfg.Signer
+	eoaAddress := cfg.Address().Hex()
 	log.Printf("EOA Address: %s", eoaAddress)
 
 	// Get the local IP address of the node
@@ -91,57 +67,65 @@ func RunRegularNode() {
 	}
 
 	// Connect to the leader
-	leaderInfo, err := libp2putils.ConnectToPeer(h, leaderIP, leaderPort, leaderPeerID)
+	leaderInfo, err := libp2putils.ConnectToPeer(h, cfg.Leader.IP, cfg.Leader.Port, cfg.Leader.PeerID)
 	if err != nil {
 		log.Fatalf("Error connecting to leader: %v", err)
 	}
 
-	ethRPCURL := os.Getenv("ETH_RPC_URL")
-	if ethRPCURL == "" {
-		log.Fatal("ETH_RPC_URL is not set in the environment variables")
-	}
+	// Messages for the leader are queued in the outbox, which sends them until
+	// they are accepted, including those left from before a restart
+	outbox := regularNode_helper.NewOutbox(h, cfg, drb, leaderInfo.ID)
 
-	client, err := ethclient.Dial(ethRPCURL)
-	if err != nil {
-		log.Fatalf("Failed to connect to Ethereum client: %v", err)
-	}
+	// The handlers are set before the handshake, which announces them. Every
+	// protocol is also served on the IDs of its previous versions
+	libp2putils.HandleProtocol(h, utils.ProtocolHandshake, func(s network.Stream) {
+		libp2putils.HandleHandshake(h, cfg, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolRevealRequest, func(s network.Stream) {
+		regularNode_helper.HandleSecretValueRequest(cfg, drb, seed, outbox, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolRevealOrder, func(s network.Stream) {
+		regularNode_helper.HandleRevealOrder(cfg, drb, s)
+	})
+	libp2putils.HandleProtocol(h, utils.ProtocolSync, func(s network.Stream) {
+		regularNode_helper.HandleSyncRequest(cfg, drb, seed, outbox, s)
+	})
 
-	contractAddressStr := os.Getenv("CONTRACT_ADDRESS")
-	if contractAddressStr == "" {
-		log.Fatal("CONTRACT_ADDRESS is not set in environment variables.")
+	// Check the leader serves the same contract, and which protocols it handles
+	leaderHello, err := libp2putils.Handshake(ctx, h, cfg, leaderInfo.ID)
+	switch {
+	case errors.Is(err, libp2putils.ErrNoHandshake):
+		log.Println("Leader runs a version without the handshake, using the unversioned protocols.")
+	case err != nil:
+		log.Fatalf("Handshake with leader failed: %v", err)
+	default:
+		log.Printf("Leader runs version %s", leaderHello.Version)
 	}
-	contractAddress := common.HexToAddress(contractAddressStr)
 
-	parsedABI, err := utils.LoadContractABI(abiFilePath)
-	if err != nil {
-		log.Fatalf("Failed to load contract ABI: %v", err)
-	}
+	go outbox.Run(ctx)
 
-	clientUtils := &utils.Client{
-		Client:          client,
-		ContractAddress: contractAddress,
-		PrivateKey:      privateKey,
-		ContractABI:     parsedABI,
-	}
+	watcher := startRoundWatcher(ctx, cfg, drb)
 
+	// A leader without the reconciliation protocol is not asked
+	synced := leaderHello == nil || !leaderHello.Supports(utils.ProtocolSync)
 	for {
 		// Fetch round data
-		roundsData, err := fetchRoundsData()
+		roundsData, err := fetchRounds(cfg, watcher)
 		if err != nil {
 			log.Printf("Error fetching rounds data: %v", err)
-			time.Sleep(30 * time.Second)
+			waitForRoundUpdate(watcher)
 			continue
 		}
 
 		// Check activation status
-		isActivated := checkActivationStatus(clientUtils, eoaAddress)
+		isActivated := CheckActivationStatus(drb, eoaAddress)
 		if isActivated {
 			log.Println("Node is activated. No further action required.")
 		} else {
 			log.Println("Node is not activated. Checking deposit amount...")
 
 			// Check and ensure deposit is sufficient
-			depositSufficient, err := checkDepositAmount(clientUtils, eoaAddress)
+			depositSufficient, err := CheckDepositAmount(drb, eoaAddress)
 			if err != nil {
 				log.Printf("Error checking deposit amount: %v", err)
 				time.Sleep(30 * time.Second)
@@ -150,7 +134,7 @@ func RunRegularNode() {
 
 			if !depositSufficient {
 				log.Println("Deposit insufficient. Initiating deposit transaction...")
-				txSent, err := depositAndCheckActivation(ctx, eoaAddress, privateKey)
+				txSent, err := DepositAndCheckActivation(ctx, drb, eoaAddress)
 				if err != nil {
 					log.Printf("Error during deposit transaction: %v", err)
 					time.Sleep(30 * time.Second)
@@ -165,7 +149,12 @@ func RunRegularNode() {
 
 			// Send registration request to leader
 			log.Println("Deposit sufficient. Sending registration request to leader...")
-			sendRegistrationRequestToLeader(ctx, h, leaderInfo.ID, eoaAddress, privateKey)
+			sendRegistrationRequestToLeader(ctx, h, leaderInfo.ID, cfg.Leader.EOA, eoaSigner)
+		}
+
+		// Reconcile the open rounds with the leader once after the start
+		if !synced {
+			synced = syncWithLeader(ctx, h, cfg, drb, seed, outbox, leaderInfo.ID, roundsData, eoaAddress)
 		}
 
 		for _, round := range roundsData.Rounds {
@@ -175,6 +164,16 @@ func RunRegularNode() {
 			if round.MerkleRootSubmitted.MerkleRoot != nil && round.RandomNumberGenerated.RandomNumber != nil {
 				// If both MerkleRoot and RandomNumber are generated, skip this round
 				log.Printf("Round %s already has Merkle Root AND Random Number generated. Skipping commit generation.", round.Round)
+
+				// The secret is public now; drop the encrypted copy and anything left for the leader
+				if roundNum, ok := round.Round.(string); ok {
+					if err := utils.PurgeRoundSecrets(roundNum); err != nil {
+						log.Printf("Failed to purge the secrets of round %s: %v", roundNum, err)
+					}
+					if err := utils.PurgeRoundOutbox(roundNum); err != nil {
+						log.Printf("Failed to purge the outbox of round %s: %v", roundNum, err)
+					}
+				}
 				continue
 			}
 
@@ -191,13 +190,26 @@ func RunRegularNode() {
 
 				// Check if this round has already been committed (store it locally)
 				commitData, err := utils.LoadCommitData(roundNum)
-				if err != nil && err.Error() != "commit not found" {
+				if err != nil && !errors.Is(err, utils.ErrNotFound) {
 					log.Printf("Error loading commit data: %v", err)
 					continue
 				}
 
+				// Regenerate lost commit data from the secret seed once the Merkle root is in
+				if commitData == nil && seed != nil && round.MerkleRootSubmitted.MerkleRoot != nil && round.RandomNumberGenerated.RandomNumber == nil {
+					commitData, err = regularNode_helper.RecoverCommitData(ctx, cfg, drb, seed, roundNum, false)
+					if err != nil {
+						log.Printf("Error recovering commit data: %v", err)
+						continue
+					}
+				}
+
 				// If commitData exists, we should only skip the round if both MerkleRoot and RandomNumber are nil
 				if commitData != nil && round.MerkleRootSubmitted.MerkleRoot == nil && round.RandomNumberGenerated.RandomNumber == nil {
+					// Queue the CVS again if it was lost before the leader accepted it
+					if !commitData.SendToLeader {
+						queueCommitForLeader(ctx, cfg, drb, outbox, *commitData)
+					}
 					log.Printf("Commit data already exists for round %s, but both Merkle Root and Random Number are nil. Skipping commit generation.", roundNum)
 					continue
 				}
@@ -205,7 +217,7 @@ func RunRegularNode() {
 				// If Merkle Root and Random Number are nil, generate commit
 				if round.MerkleRootSubmitted.MerkleRoot == nil && round.RandomNumberGenerated.RandomNumber == nil {
 					// Generate commit
-					secretValue, cos, cvs, err := commitreveal2.GenerateCommit(roundNum, eoaAddress)
+					secretValue, cos, cvs, err := commitreveal2.GenerateCommit(roundNum, eoaAddress, seed)
 					if err != nil {
 						log.Printf("Error generating commit: %v", err)
 						continue
@@ -217,37 +229,49 @@ func RunRegularNode() {
 						SecretValue:     secretValue,
 						Cos:             cos,
 						Cvs:             cvs,
-						SendToLeader:    true,  // Mark commit to be sent to leader
-						SendCosToLeader: false, // Initially false, to allow sending COS
+						SendToLeader:    false, // Set once the leader accepts the CVS
+						SendCosToLeader: false, // Set once the leader accepts the COS
 					}
 
+					secretValue.Zero()
+					cos.Zero()
+
 					// Save commit data locally to prevent resending
 					err = utils.SaveCommitData(commitData)
+					commitData.ZeroSecrets()
 					if err != nil {
 						log.Printf("Error saving commit data: %v", err)
 						continue
 					}
 
-					// Send commit to leader
-					sendCommitToLeader(ctx, h, leaderInfo.ID, commitData, eoaAddress)
+					// Queue the commit for the leader
+					queueCommitForLeader(ctx, cfg, drb, outbox, commitData)
 				}
 
 				// If commit data exists and SendCosToLeader is false, send COS to leader
 				if commitData != nil && !commitData.SendCosToLeader {
 					// If Merkle Root is set but Random Number is nil, check and send COS
 					if round.MerkleRootSubmitted.MerkleRoot != nil && round.RandomNumberGenerated.RandomNumber == nil {
+						queued, err := utils.HasOutboxMessage(roundNum, utils.OutboxCOS)
+						if err != nil {
+							log.Printf("%v", err)
+							continue
+						}
+						if queued {
+							log.Printf("COS for round %s is waiting in the outbox.", roundNum)
+							continue
+						}
 						log.Printf("Merkle Root is set but Random Number is not. Sending COS for round %s.", roundNum)
 
-						// Send COS to leader
-						sendCosToLeader(ctx, h, leaderInfo.ID, *commitData, eoaAddress, privateKey)
-
-						// Update SendCosToLeader flag
-						commitData.SendCosToLeader = true
+						// Withhold the COS unless the submitted root commits to this node's CVS
+						if err := regularNode_helper.VerifyMerkleInclusion(ctx, h, cfg, drb, leaderInfo.ID, commitData); err != nil {
+							log.Printf("Withholding COS for round %s: %v", roundNum, err)
+							continue
+						}
 
-						// Save updated commit data to prevent re-sending COS
-						err := utils.SaveCommitData(*commitData)
-						if err != nil {
-							log.Printf("Error saving updated commit data after sending COS: %v", err)
+						// Queue the COS; SendCosToLeader is set once the leader accepts it
+						if err := outbox.Send(roundNum, utils.OutboxCOS); err != nil {
+							log.Printf("Failed to queue the COS of round %s: %v", roundNum, err)
 						}
 					}
 					continue
@@ -255,39 +279,8 @@ func RunRegularNode() {
 			}
 		}
 
-		// Wait before rechecking activation status
-		time.Sleep(30 * time.Second)
-	}
-}
-
-// sendCOSToLeader sends the COS to the leader node
-func sendCosToLeader(ctx context.Context, h core.Host, leaderID peer.ID, commitData utils.CommitData, eoaAddress string, privateKey *ecdsa.PrivateKey) {
-	// Create commit request structure with signed COS and round data
-	req := utils.CosRequest{
-		Round:      commitData.Round,
-		Cos:        commitData.Cos,
-		EOAAddress: eoaAddress, // Include EOA address to verify
-	}
-
-	// Sign the request (just the round value here)
-	signedRequest := utils.SignData(eoaAddress, privateKey)
-
-	// Send the COS commit to leader with the signed request
-	req.Signature = signedRequest
-
-	// Send the commit to leader
-	s, err := h.NewStream(ctx, leaderID, "/cos")
-	if err != nil {
-		log.Printf("Failed to create stream to leader: %v", err)
-		return
-	}
-	defer s.Close()
-
-	// Encode and send the commit request
-	if err := json.NewEncoder(s).Encode(req); err != nil {
-		log.Printf("Failed to send COS commit to leader: %v", err)
-	} else {
-		log.Printf("COS commit sent to leader for round %s", commitData.Round)
+		// Wait for new round data before rechecking activation status
+		waitForRoundUpdate(watcher)
 	}
 }
 
@@ -309,16 +302,39 @@ func isEOAActivated(round RoundData, eoaAddress string) bool {
 	return false
 }
 
-func checkActivationStatus(client *utils.Client, eoaAddress string) bool {
-	activatedOperatorsResult, err := eth.CallSmartContract(client.Client, client.ContractABI, "getActivatedOperators", client.ContractAddress)
+// syncWithLeader reconciles the open rounds this node takes part in with the
+// leader, and reports whether it succeeded.
+func syncWithLeader(ctx context.Context, h core.Host, cfg *config.Config, drb *eth.DRBContract, seed *commitreveal2.SecretSeed, outbox *regularNode_helper.Outbox, leaderID peer.ID, roundsData *GraphQLResponse, eoaAddress string) bool {
+	var rounds []string
+	for _, round := range roundsData.Rounds {
+		roundNum, ok := round.Round.(string)
+		if !ok || round.RandomNumberGenerated.RandomNumber != nil || !isEOAActivated(round, eoaAddress) {
+			continue
+		}
+		rounds = append(rounds, roundNum)
+	}
+	if len(rounds) == 0 {
+		return true
+	}
+
+	if err := regularNode_helper.SyncWithLeader(ctx, h, cfg, drb, seed, outbox, leaderID, rounds); err != nil {
+		log.Printf("Failed to reconcile open rounds with the leader: %v", err)
+		return false
+	}
+	log.Printf("Reconciled %d open rounds with the leader", len(rounds))
+	return true
+}
+
+// CheckActivationStatus reports whether eoaAddress is a currently activated operator.
+func CheckActivationStatus(drb *eth.DRBContract, eoaAddress string) bool {
+	activatedOperators, err := drb.ActivatedOperators(context.Background())
 	if err != nil {
-		log.Printf("Failed to call getActivatedOperators: %v", err)
+		log.Printf("Failed to read activated operators: %v", err)
 		return false
 	}
 
-	activatedOperators := activatedOperatorsResult.([]common.Address)
 	for _, operator := range activatedOperators {
-		if operator.Hex() == eoaAddress {
+		if operator == common.HexToAddress(eoaAddress) {
 			return true
 		}
 	}
@@ -327,64 +343,34 @@ func checkActivationStatus(client *utils.Client, eoaAddress string) bool {
 }
 
 // sendRegistrationRequestToLeader sends the registration request to the leader node
-func sendRegistrationRequestToLeader(ctx context.Context, h core.Host, leaderID peer.ID, eoaAddress string, privateKey *ecdsa.PrivateKey) {
-	req := utils.RegistrationRequest{
-		EOAAddress: eoaAddress,
-		Signature:  utils.SignData(eoaAddress, privateKey),
-		PeerID:     h.ID().String(),
-	}
-
-	s, err := h.NewStream(ctx, leaderID, "/register")
-	if err != nil {
-		log.Printf("Failed to create stream to leader: %v", err)
-		h.Peerstore().AddAddrs(leaderID, h.Peerstore().Addrs(leaderID), peerstore.PermanentAddrTTL)
-		return
-	}
-	defer s.Close()
-
-	if err := json.NewEncoder(s).Encode(req); err != nil {
+func sendRegistrationRequestToLeader(ctx context.Context, h core.Host, leaderID peer.ID, leaderEOA string, eoaSigner signer.Signer) {
+	resp, err := regularNode_helper.RegisterWithLeader(ctx, h, leaderID, leaderEOA, eoaSigner)
+	switch {
+	case err != nil:
 		log.Printf("Failed to send registration request: %v", err)
-	} else {
-		log.Println("Registration request sent to leader.")
+	case resp.Status.Accepted():
+		log.Println("Leader registered this node.")
+	case resp.Status.Retryable():
+		log.Printf("Leader could not complete the registration, it is sent again later: %v", resp)
+	default:
+		log.Printf("ALERT: Leader rejected the registration: %v (leader's view: %v)", resp, resp.State)
 	}
 }
 
-func depositAndCheckActivation(ctx context.Context, eoaAddress string, privateKey *ecdsa.PrivateKey) (bool, error) {
-	ethRPCURL := os.Getenv("ETH_RPC_URL")
-	if ethRPCURL == "" {
-		log.Fatal("ETH_RPC_URL is not set in environment variables.")
-	}
-
-	client, err := ethclient.Dial(ethRPCURL)
-	if err != nil {
-		return false, fmt.Errorf("failed to connect to Ethereum client: %v", err)
-	}
-
-	contractAddressStr := os.Getenv("CONTRACT_ADDRESS")
-	if contractAddressStr == "" {
-		log.Fatal("CONTRACT_ADDRESS is not set in environment variables.")
-	}
-
-	contractAddress := common.HexToAddress(contractAddressStr)
-
-	parsedABI, err := utils.LoadContractABI(abiFilePath)
-	if err != nil {
-		return false, fmt.Errorf("failed to load contract ABI: %v", err)
-	}
-
+// DepositAndCheckActivation deposits the amount eoaAddress is missing to reach the
+// activation threshold. It reports whether a deposit transaction was sent.
+func DepositAndCheckActivation(ctx context.Context, drb *eth.DRBContract, eoaAddress string) (bool, error) {
 	// Fetch deposit amount
-	depositAmountResult, err := eth.CallSmartContract(client, parsedABI, "s_depositAmount", contractAddress, common.HexToAddress(eoaAddress))
+	depositAmount, err := drb.DepositAmount(ctx, common.HexToAddress(eoaAddress))
 	if err != nil {
-		return false, fmt.Errorf("failed to call s_depositAmount: %v", err)
+		return false, err
 	}
-	depositAmount := depositAmountResult.(*big.Int)
 
 	// Fetch activation threshold
-	activationThresholdResult, err := eth.CallSmartContract(client, parsedABI, "s_activationThreshold", contractAddress)
+	activationThreshold, err := drb.ActivationThreshold(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to call s_activationThreshold: %v", err)
+		return false, err
 	}
-	activationThreshold := activationThresholdResult.(*big.Int)
 
 	// If deposit is insufficient, we calculate the remaining amount and proceed with the deposit
 	if depositAmount.Cmp(activationThreshold) < 0 {
@@ -392,7 +378,7 @@ func depositAndCheckActivation(ctx context.Context, eoaAddress string, privateKe
 		log.Printf("Deposit insufficient. Adding remaining: %s", remaining.String())
 
 		// Check account balance
-		balance, err := client.BalanceAt(ctx, common.HexToAddress(eoaAddress), nil)
+		balance, err := drb.Client().BalanceAt(ctx, common.HexToAddress(eoaAddress), nil)
 		if err != nil {
 			return false, fmt.Errorf("failed to fetch account balance: %v", err)
 		}
@@ -403,18 +389,7 @@ func depositAndCheckActivation(ctx context.Context, eoaAddress string, privateKe
 		}
 
 		// Create and send deposit transaction
-		_, _, err = eth.ExecuteTransaction(
-			ctx,
-			&utils.Client{
-				Client:          client,
-				ContractAddress: contractAddress,
-				PrivateKey:      privateKey,
-				ContractABI:     parsedABI,
-			},
-			"deposit",
-			remaining,
-		)
-		if err != nil {
+		if _, err := drb.Deposit(ctx, remaining); err != nil {
 			return false, fmt.Errorf("failed to send deposit transaction: %v", err)
 		}
 
@@ -426,20 +401,21 @@ func depositAndCheckActivation(ctx context.Context, eoaAddress string, privateKe
 	return false, nil
 }
 
-func checkDepositAmount(client *utils.Client, eoaAddress string) (bool, error) {
+// CheckDepositAmount reports whether the deposit of eoaAddress reaches the activation threshold.
+func CheckDepositAmount(drb *eth.DRBContract, eoaAddress string) (bool, error) {
+	ctx := context.Background()
+
 	// Fetch deposit amount
-	depositAmountResult, err := eth.CallSmartContract(client.Client, client.ContractABI, "s_depositAmount", client.ContractAddress, common.HexToAddress(eoaAddress))
+	depositAmount, err := drb.DepositAmount(ctx, common.HexToAddress(eoaAddress))
 	if err != nil {
-		return false, fmt.Errorf("failed to call s_depositAmount: %v", err)
+		return false, err
 	}
-	depositAmount := depositAmountResult.(*big.Int)
 
 	// Fetch activation threshold
-	activationThresholdResult, err := eth.CallSmartContract(client.Client, client.ContractABI, "s_activationThreshold", client.ContractAddress)
+	activationThreshold, err := drb.ActivationThreshold(ctx)
 	if err != nil {
-		return false, fmt.Errorf("failed to call s_activationThreshold: %v", err)
+		return false, err
 	}
-	activationThreshold := activationThresholdResult.(*big.Int)
 
 	log.Printf("Deposit amount: %s, Activation threshold: %s", depositAmount.String(), activationThreshold.String())
 
@@ -451,64 +427,46 @@ func checkDepositAmount(client *utils.Client, eoaAddress string) (bool, error) {
 	return false, nil
 }
 
-// sendCommitToLeader sends the generated commit to the leader node
-func sendCommitToLeader(ctx context.Context, h core.Host, leaderID peer.ID, commitData utils.CommitData, eoaAddress string) {
-	// Create commit request structure with signed round value and CVS
-	req := utils.CommitRequest{
-		Round:      commitData.Round,
-		Cvs:        commitData.Cvs,
-		EOAAddress: eoaAddress,
-	}
-
-	privateKeyHex := os.Getenv("EOA_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("EOA_PRIVATE_KEY is not set in the environment variables")
-	}
-	
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+// queueCommitForLeader signs the CVS of commitData, unless it is signed
+// already, and queues it for the leader.
+func queueCommitForLeader(ctx context.Context, cfg *config.Config, drb *eth.DRBContract, outbox *regularNode_helper.Outbox, commitData utils.CommitData) {
+	queued, err := utils.HasOutboxMessage(commitData.Round, utils.OutboxCVS)
 	if err != nil {
-		log.Printf("Failed to decode leader private key: %v", err)
+		log.Printf("%v", err)
 		return
 	}
-
-	// Sign the request (round + EOA address)
-	signedRequest := utils.SignData(eoaAddress, privateKey)
-
-	req.Signature = signedRequest
-
-	// Generate v, r, s for the CVS using the helper function
-	v, r, s, err := regularNode_helper.GenerateCvsSignature(req.Round, req.Cvs)
-	if err != nil {
-		log.Printf("Failed to generate v, r, s for CVS: %v", err)
+	if queued {
 		return
 	}
 
-	// Add signature values to the commit request
-	req.Sign = map[string]string{
-		"v": fmt.Sprintf("%d", v),
-		"r": r,
-		"s": s,
-	}
+	if len(commitData.Sign) == 0 {
+		// Generate v, r, s for the CVS using the helper function
+		domain, err := drb.EIP712Domain(ctx)
+		if err != nil {
+			log.Printf("Failed to load the EIP-712 domain: %v", err)
+			return
+		}
+		v, r, s, err := regularNode_helper.GenerateCvsSignature(commitData.Round, commitData.Cvs, cfg.Signer, domain)
+		if err != nil {
+			log.Printf("Failed to generate v, r, s for CVS: %v", err)
+			return
+		}
 
-	// Save commit data locally with v, r, s
-	commitData.Sign = req.Sign
-	if err := utils.SaveCommitData(commitData); err != nil {
-		log.Printf("Failed to save commit data locally: %v", err)
-		return
+		// Save commit data locally with v, r, s
+		commitData.Sign = map[string]string{
+			"v": fmt.Sprintf("%d", v),
+			"r": r,
+			"s": s,
+		}
+		if err := utils.SaveCommitData(commitData); err != nil {
+			log.Printf("Failed to save commit data locally: %v", err)
+			return
+		}
 	}
 
-	// Send the commit to the leader
-	send, err := h.NewStream(ctx, leaderID, "/cvs")
-	if err != nil {
-		log.Printf("Failed to create stream to leader: %v", err)
+	if err := outbox.Send(commitData.Round, utils.OutboxCVS); err != nil {
+		log.Printf("Failed to queue the commit of round %s: %v", commitData.Round, err)
 		return
 	}
-	defer send.Close()
-
-	// Encode and send the commit request
-	if err := json.NewEncoder(send).Encode(req); err != nil {
-		log.Printf("Failed to send commit to leader for round %s: %v", req.Round, err)
-	} else {
-		log.Printf("Commit successfully sent to leader for round %s", req.Round)
-	}
+	log.Printf("Commit for round %s queued for the leader", commitData.Round)
 }
@@ -0,0 +1,55 @@
+package regularNode_helper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// RecoverCommitData regenerates and stores the commit of a round from the
+// secret seed after the stored commit data was lost. cosSent records whether
+// the COS is known to have reached the leader.
+func RecoverCommitData(ctx context.Context, cfg *config.Config, drb *eth.DRBContract, seed *commitreveal2.SecretSeed, round string, cosSent bool) (*utils.CommitData, error) {
+	if seed == nil {
+		return nil, errors.New("commit not found and no secret seed is configured")
+	}
+
+	secretValue, cos, cvs, err := commitreveal2.GenerateCommit(round, cfg.Address().Hex(), seed)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := drb.EIP712Domain(ctx)
+	if err != nil {
+		return nil, err
+	}
+	v, r, s, err := GenerateCvsSignature(round, cvs, cfg.Signer, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	commitData := utils.CommitData{
+		Round:           round,
+		SecretValue:     secretValue,
+		Cos:             cos,
+		Cvs:             cvs,
+		SendToLeader:    true,
+		SendCosToLeader: cosSent,
+		Sign: map[string]string{
+			"v": fmt.Sprintf("%d", v),
+			"r": r,
+			"s": s,
+		},
+	}
+	if err := utils.SaveCommitData(commitData); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Recovered commit data for round %s from the secret seed", round)
+	return &commitData, nil
+}
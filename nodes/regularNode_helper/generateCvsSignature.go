@@ -5,42 +5,16 @@ import (
 	"fmt"
 	"log"
 	"math/big"
-	"os"
-	"strings"
 
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/tokamak-network/DRB-node/eip712"
+	"github.com/tokamak-network/DRB-node/sensitive"
+	"github.com/tokamak-network/DRB-node/signer"
 )
 
-// GenerateCvsSignature generates the EIP-712 signature components (v, r, s) for a given round and CVS value.
-func GenerateCvsSignature(roundNum string, cvs [32]byte) (uint8, string, string, error) {
-	// Convert CVS to string for internal usage (optional, depending on use case)
-	cvsString := hex.EncodeToString(cvs[:])
+// GenerateCvsSignature generates the EIP-712 signature components (v, r, s) for a given round and CVS value,
+// in the EIP-712 domain of the DRB contract.
+func GenerateCvsSignature(roundNum string, cvs [32]byte, s signer.Signer, domain eip712.Domain) (uint8, string, string, error) {
 	log.Printf("Received CVS as [32]byte: %x", cvs)
-	log.Printf("Converted CVS to String: %s", cvsString)
-
-	// Define constants for EIP-712
-	name := "Tokamak DRB"
-	version := "1"
-
-	// Fetch contract address and chain ID dynamically from the .env file
-	contractAddressEnv := os.Getenv("CONTRACT_ADDRESS")
-	if contractAddressEnv == "" {
-		log.Fatal("CONTRACT_ADDRESS is not set in environment variables.")
-	}
-	chainIDEnv := os.Getenv("CHAIN_ID")
-	if chainIDEnv == "" {
-		log.Fatal("CHAIN_ID is not set in environment variables.")
-	}
-	contractAddressEnv = strings.TrimPrefix(contractAddressEnv, "0x")
-	contractAddress := common.HexToAddress(contractAddressEnv)
-
-
-	chainID := new(big.Int)
-	chainID, okays := chainID.SetString(chainIDEnv, 10)
-	if !okays {
-		return 0, "", "", fmt.Errorf("invalid chain ID: %s", chainIDEnv)
-	}
 
 	// Parse roundNum as *big.Int
 	round := new(big.Int)
@@ -49,88 +23,30 @@ func GenerateCvsSignature(roundNum string, cvs [32]byte) (uint8, string, string,
 		return 0, "", "", fmt.Errorf("invalid round number: %s", roundNum)
 	}
 
-	// Load the private key
-	privateKeyHex := os.Getenv("EOA_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("EOA_PRIVATE_KEY is not set in the environment variables")
-	}
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
+	// Message(uint256 round,bytes32 cv) in the contract's domain
+	typedData := domain.Message(round, cvs)
+
+	typedDataHash, err := eip712.Hash(typedData)
 	if err != nil {
-		return 0, "", "", fmt.Errorf("failed to decode private key: %v", err)
+		return 0, "", "", err
 	}
+	log.Printf("Typed Data Hash: %x", typedDataHash)
 
-	// Step 1: Compute domain separator
-	domainTypeHash := crypto.Keccak256Hash([]byte("EIP712Domain(string name,string version,uint256 chainId,address verifyingContract)"))
-	nameHash := crypto.Keccak256Hash([]byte(name))
-	versionHash := crypto.Keccak256Hash([]byte(version))
-
-	domainSeparator := crypto.Keccak256Hash(
-		abiEncode(
-			domainTypeHash.Bytes(),
-			nameHash.Bytes(),
-			versionHash.Bytes(),
-			intToBytes(chainID),
-			contractAddress.Bytes(),
-		),
-	)
-	log.Printf("Domain Separator: %s", domainSeparator.Hex())
-
-	// Step 2: Compute message hash
-	messageTypeHash := crypto.Keccak256Hash([]byte("Message(uint256 round,bytes32 cv)"))
-
-	messageHash := crypto.Keccak256Hash(
-		abiEncode(
-			messageTypeHash.Bytes(),
-			intToBytes(round), // uint256 round
-			cvs[:],            // bytes32 CVS as [32]byte
-		),
-	)
-	log.Printf("Message Hash: %s", messageHash.Hex())
-
-	// Step 3: Compute the final typed data hash
-	typedDataHash := crypto.Keccak256Hash(
-		abiEncodePacked(
-			[]byte{0x19, 0x01}, // EIP-712 prefix
-			domainSeparator.Bytes(),
-			messageHash.Bytes(),
-		),
-	)
-	log.Printf("Typed Data Hash: %s", typedDataHash.Hex())
-
-	// Step 4: Sign the typed data hash
-	signature, err := crypto.Sign(typedDataHash.Bytes(), privateKey)
+	signature, err := s.SignTypedData(typedData)
 	if err != nil {
 		return 0, "", "", fmt.Errorf("failed to sign typed data: %v", err)
 	}
 
-	// Split the signature into r, s, and v
-	r := hex.EncodeToString(signature[:32])
-	s := hex.EncodeToString(signature[32:64])
-	v := uint8(signature[64]) + 27 // Adjust for Ethereum recovery ID
-
-	log.Printf("Generated EIP-712 signature: v=%d, r=%s, s=%s", v, r, s)
-	return v, r, s, nil
-}
-
-// Helper: abiEncode replicates Solidity's `abi.encode` behavior with 32-byte padding.
-func abiEncode(elements ...[]byte) []byte {
-	var encoded []byte
-	for _, e := range elements {
-		encoded = append(encoded, common.LeftPadBytes(e, 32)...)
-	}
-	return encoded
-}
-
-// Helper: abiEncodePacked replicates Solidity's `abi.encodePacked` behavior.
-func abiEncodePacked(elements ...[]byte) []byte {
-	var packed []byte
-	for _, e := range elements {
-		packed = append(packed, e...)
+	// Split the signature into r, s, and v; v is already 27 or 28
+	split, err := eip712.SplitSignature(signature)
+	sensitive.Zero(signature)
+	if err != nil {
+		return 0, "", "", err
 	}
-	return packed
-}
+	r := hex.EncodeToString(split.R[:])
+	sig := hex.EncodeToString(split.S[:])
+	v := split.V
 
-// Helper: intToBytes converts a *big.Int to its padded big-endian byte representation.
-func intToBytes(n *big.Int) []byte {
-	return common.LeftPadBytes(n.Bytes(), 32)
+	log.Printf("Generated EIP-712 signature: v=%d, r=%v, s=%v", v, sensitive.String(r), sensitive.String(sig))
+	return v, r, sig, nil
 }
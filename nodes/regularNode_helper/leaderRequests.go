@@ -0,0 +1,123 @@
+package regularNode_helper
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/peerstore"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/signer"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// maxLeaderAttempts is how often a request is sent while the leader cannot
+// process it.
+const maxLeaderAttempts = 3
+
+// leaderRetryDelay is the wait before the first retry; it doubles with every retry.
+const leaderRetryDelay = 5 * time.Second
+
+// registrationTimeout bounds a registration, which the leader answers once the
+// operator is activated on-chain.
+const registrationTimeout = 2 * time.Minute
+
+// RegisterWithLeader sends the registration request of this node, signed with
+// s, and returns the leader's response, signed by leaderEOA.
+func RegisterWithLeader(ctx context.Context, h host.Host, leaderID peer.ID, leaderEOA string, s signer.Signer) (*utils.Response, error) {
+	req := utils.RegistrationRequest{
+		EOAAddress: s.Address().Hex(),
+		PeerID:     h.ID().String(),
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, registrationTimeout)
+	defer cancel()
+
+	stream, err := h.NewStream(ctx, leaderID, utils.ProtocolIDs(utils.ProtocolRegister)...)
+	if err != nil {
+		h.Peerstore().AddAddrs(leaderID, h.Peerstore().Addrs(leaderID), peerstore.PermanentAddrTTL)
+		return nil, fmt.Errorf("failed to create stream to leader: %v", err)
+	}
+	defer stream.Close()
+
+	resp, _, err := utils.RoundTrip(ctx, stream, s, "", leaderEOA, req)
+	return resp, err
+}
+
+// RequestLeader sends req for a round to the leader on protocol proto, signed
+// with s, and returns the response signed by leaderEOA and its envelope. A request the
+// leader could not process is sent again after a delay; when the leader does
+// not know this node, the node registers again first.
+func RequestLeader(ctx context.Context, h host.Host, leaderID peer.ID, leaderEOA string, s signer.Signer, proto, round string, req interface{}) (*utils.Response, *utils.Envelope, error) {
+	delay := leaderRetryDelay
+	for attempt := 1; ; attempt++ {
+		resp, env, err := requestLeaderOnce(ctx, h, leaderID, leaderEOA, s, proto, round, req)
+
+		retry := err != nil || resp.Status.Retryable()
+		if err == nil && resp.Status == utils.StatusNotRegistered {
+			log.Printf("Leader does not know this node (%s), registering again", resp.Reason)
+			if regResp, regErr := RegisterWithLeader(ctx, h, leaderID, leaderEOA, s); regErr != nil {
+				log.Printf("Failed to register with the leader: %v", regErr)
+			} else if !regResp.Status.Accepted() {
+				log.Printf("Leader did not register this node: %v", regResp)
+			}
+			retry = true
+		}
+		if !retry || attempt == maxLeaderAttempts {
+			return resp, env, err
+		}
+
+		if err != nil {
+			log.Printf("Request %s for round %s failed (attempt %d): %v", proto, round, attempt, err)
+		} else {
+			log.Printf("Leader could not process request %s for round %s (attempt %d): %v", proto, round, attempt, resp)
+		}
+		select {
+		case <-ctx.Done():
+			return resp, env, err
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// requestLeaderOnce sends req on a new stream to the leader and reads the response.
+func requestLeaderOnce(ctx context.Context, h host.Host, leaderID peer.ID, leaderEOA string, s signer.Signer, proto, round string, req interface{}) (*utils.Response, *utils.Envelope, error) {
+	stream, err := h.NewStream(ctx, leaderID, utils.ProtocolIDs(proto)...)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create stream to leader: %v", err)
+	}
+	defer stream.Close()
+
+	return utils.RoundTrip(ctx, stream, s, round, leaderEOA, req)
+}
+
+// LeaderAccepted logs the outcome of a request to the leader about a round and
+// reports whether the leader accepted it. Rejections that sending the request
+// again cannot fix are logged as alerts.
+func LeaderAccepted(what, round string, resp *utils.Response, err error) bool {
+	switch {
+	case err != nil:
+		log.Printf("No answer from leader to the %s of round %s: %v", what, round, err)
+	case resp.Status.Accepted():
+		log.Printf("Leader accepted the %s of round %s", what, round)
+		return true
+	case resp.Status.Retryable():
+		log.Printf("Leader could not take the %s of round %s: %v (leader's view: %v)", what, round, resp, resp.State)
+	default:
+		log.Printf("ALERT: Leader rejected the %s of round %s: %v (leader's view: %v)", what, round, resp, resp.State)
+	}
+	return false
+}
+
+// replyToLeader answers a request of the leader for a round on stream s.
+func replyToLeader(cfg *config.Config, s network.Stream, round string, status utils.Status, reason string) {
+	resp := &utils.Response{Round: round, Status: status, Reason: reason}
+	if err := utils.WriteResponse(s, cfg.Signer, resp); err != nil {
+		log.Printf("Failed to answer %s request for round %s: %v", s.Protocol(), round, err)
+	}
+}
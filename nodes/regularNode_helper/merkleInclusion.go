@@ -0,0 +1,157 @@
+package regularNode_helper
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// VerifyMerkleInclusion checks that the Merkle root submitted on-chain for the
+// round of commitData contains this node's CVS at its position. The leaves are
+// fetched from the leader and hashed with the contract's getMerkleRoot. If the
+// root does not commit to the CVS, an alert is logged and evidence recorded.
+func VerifyMerkleInclusion(ctx context.Context, h host.Host, cfg *config.Config, drb *eth.DRBContract, leaderID peer.ID, commitData *utils.CommitData) error {
+	round := commitData.Round
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	failed, err := utils.HasEvidence(round, utils.EvidenceMerkleRoot)
+	if err != nil {
+		return err
+	}
+	if failed {
+		return errors.New("the Merkle root of the round does not include the CVS of this node")
+	}
+
+	leaves, env, err := fetchMerkleLeaves(ctx, h, cfg, leaderID, round)
+	if err != nil {
+		return err
+	}
+	if err := checkLeader(ctx, cfg, drb, env); err != nil {
+		return err
+	}
+
+	reason, err := checkMerkleInclusion(ctx, cfg, drb, round, leaves, commitData.Cvs)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		log.Printf("ALERT: Merkle root of round %s does not include the CVS of this node: %s", round, reason)
+		recordEvidence(env, utils.EvidenceMerkleRoot, reason)
+		return errors.New(reason)
+	}
+
+	log.Printf("Verified that the Merkle root of round %s includes the CVS of this node", round)
+	return nil
+}
+
+// fetchMerkleLeaves asks the leader for the Merkle leaves of a round.
+func fetchMerkleLeaves(ctx context.Context, h host.Host, cfg *config.Config, leaderID peer.ID, round string) (*utils.MerkleLeaves, *utils.Envelope, error) {
+	req := utils.Request{Round: round, EOAAddress: cfg.Address().Hex()}
+	resp, env, err := RequestLeader(ctx, h, leaderID, cfg.Leader.EOA, cfg.Signer, utils.ProtocolMerkleLeaves, round, req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read Merkle leaves from leader: %v", err)
+	}
+	if resp.Status != utils.StatusOK {
+		return nil, nil, fmt.Errorf("leader did not send the Merkle leaves: %v", resp)
+	}
+
+	var leaves utils.MerkleLeaves
+	if err := json.Unmarshal(resp.Data, &leaves); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode Merkle leaves from leader: %v", err)
+	}
+	return &leaves, env, nil
+}
+
+// checkMerkleInclusion returns why leaves do not show that the on-chain Merkle
+// root of round includes cvs at this node's position, or an empty reason if
+// they do. An error means the inclusion could not be checked.
+func checkMerkleInclusion(ctx context.Context, cfg *config.Config, drb *eth.DRBContract, round string, leaves *utils.MerkleLeaves, cvs [32]byte) (string, error) {
+	if leaves.Round != round {
+		return fmt.Sprintf("Merkle leaves are for round %s, not %s", leaves.Round, round), nil
+	}
+	if len(leaves.Leaves) != len(leaves.Operators) {
+		return fmt.Sprintf("%d Merkle leaves for %d operators", len(leaves.Leaves), len(leaves.Operators)), nil
+	}
+
+	var hashes [][32]byte
+	for i, leafHex := range leaves.Leaves {
+		leaf, err := hex.DecodeString(leafHex)
+		if err != nil || len(leaf) != 32 {
+			return fmt.Sprintf("invalid Merkle leaf %q at index %d", leafHex, i), nil
+		}
+		hashes = append(hashes, common.BytesToHash(leaf))
+	}
+
+	roundNum, ok := new(big.Int).SetString(round, 10)
+	if !ok {
+		return "", fmt.Errorf("invalid round number %q", round)
+	}
+	activated, err := drb.ActivatedOperatorsAtRound(ctx, roundNum)
+	if err != nil {
+		return "", err
+	}
+	if !inOnChainOrder(leaves.Operators, activated) {
+		return fmt.Sprintf("operators %v are not activated operators of the round in on-chain order", leaves.Operators), nil
+	}
+
+	position := indexOf(leaves.Operators, cfg.Address().Hex())
+	if position < 0 {
+		return "this node is not among the operators of the Merkle tree", nil
+	}
+	if hashes[position] != cvs {
+		for i, leaf := range hashes {
+			if leaf == cvs {
+				return fmt.Sprintf("the CVS of this node is at leaf %d instead of %d", i, position), nil
+			}
+		}
+		return "the CVS of this node is missing from the Merkle leaves", nil
+	}
+
+	info, err := drb.RoundInfo(ctx, roundNum)
+	if err != nil {
+		return "", err
+	}
+	if info.MerkleRoot == (common.Hash{}) {
+		return "", errors.New("no Merkle root is submitted on-chain")
+	}
+	root, err := drb.MerkleRoot(ctx, hashes)
+	if err != nil {
+		return "", err
+	}
+	if root != info.MerkleRoot {
+		return fmt.Sprintf("the Merkle leaves hash to %s, the submitted root is %s", root.Hex(), info.MerkleRoot.Hex()), nil
+	}
+
+	// The contract agrees; check the local tree as well so proofs built from
+	// it can be trusted.
+	var raw [][]byte
+	for _, leaf := range hashes {
+		raw = append(raw, common.CopyBytes(leaf[:]))
+	}
+	tree, err := commitreveal2.NewMerkleTree(raw)
+	if err != nil {
+		return "", err
+	}
+	proof, err := tree.Proof(position)
+	if err != nil {
+		return "", err
+	}
+	if !commitreveal2.VerifyMerkleProof(root, cvs, proof) {
+		return "", fmt.Errorf("local Merkle tree of round %s does not match the contract's getMerkleRoot", round)
+	}
+	return "", nil
+}
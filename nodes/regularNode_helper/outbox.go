@@ -0,0 +1,257 @@
+package regularNode_helper
+
+import (
+	"context"
+	"log"
+	"math/big"
+	"math/rand/v2"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/sensitive"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// Backoff of messages the leader did not accept: the delay doubles with every
+// attempt up to outboxMaxDelay, and a random part of it is dropped so the
+// nodes of a round do not retry in step.
+const (
+	outboxBaseDelay = 2 * time.Second
+	outboxMaxDelay  = 5 * time.Minute
+)
+
+// outboxIdleInterval is how often the outbox is read when no message is due.
+const outboxIdleInterval = time.Minute
+
+// outboxRequests maps each kind of outbox message to its protocol and the
+// name used in logs.
+var outboxRequests = map[string]struct{ protocol, name string }{
+	utils.OutboxCVS:    {utils.ProtocolCVS, "commit"},
+	utils.OutboxCOS:    {utils.ProtocolCOS, "COS"},
+	utils.OutboxSecret: {utils.ProtocolSecretValue, "secret value"},
+}
+
+// Outbox delivers the CVS, COS and secret values of this node to the leader
+// until the leader accepts them or their round ends. Pending messages are
+// stored, so they are sent again after a restart.
+type Outbox struct {
+	h        host.Host
+	cfg      *config.Config
+	drb      *eth.DRBContract
+	leaderID peer.ID
+	wake     chan struct{}
+}
+
+// NewOutbox returns the outbox of a regular node; Run delivers its messages.
+func NewOutbox(h host.Host, cfg *config.Config, drb *eth.DRBContract, leaderID peer.ID) *Outbox {
+	return &Outbox{h: h, cfg: cfg, drb: drb, leaderID: leaderID, wake: make(chan struct{}, 1)}
+}
+
+// Send queues a message of kind for round and wakes the delivery loop.
+func (o *Outbox) Send(round, kind string) error {
+	if err := utils.QueueOutboxMessage(round, kind); err != nil {
+		return err
+	}
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Run delivers due messages until ctx is done, starting with those left by
+// an earlier run.
+func (o *Outbox) Run(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case <-o.wake:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+		timer.Reset(o.deliverDue(ctx))
+	}
+}
+
+// deliverDue sends every due message once and returns the wait until the
+// next one is due.
+func (o *Outbox) deliverDue(ctx context.Context) time.Duration {
+	messages, err := utils.LoadOutbox()
+	if err != nil {
+		log.Printf("%v", err)
+		return outboxIdleInterval
+	}
+
+	wait := outboxIdleInterval
+	for _, msg := range messages {
+		if d := time.Until(msg.NextAttempt); d > 0 {
+			wait = min(wait, d)
+			continue
+		}
+		if next := o.deliver(ctx, msg); next != nil {
+			wait = min(wait, time.Until(next.NextAttempt))
+		}
+	}
+	return max(wait, 0)
+}
+
+// deliver sends msg to the leader once. It returns the message rescheduled
+// with backoff, or nil once it was accepted or given up.
+func (o *Outbox) deliver(ctx context.Context, msg utils.OutboxMessage) *utils.OutboxMessage {
+	ctx, cancel := context.WithTimeout(ctx, utils.ResponseTimeout)
+	defer cancel()
+
+	request, ok := outboxRequests[msg.Kind]
+	if !ok {
+		log.Printf("Dropping outbox message of unknown kind %q for round %s", msg.Kind, msg.Round)
+		o.drop(msg)
+		return nil
+	}
+	if o.roundEnded(ctx, msg.Round) {
+		log.Printf("Round %s has ended, dropping the %s queued for the leader", msg.Round, request.name)
+		o.drop(msg)
+		return nil
+	}
+
+	req, zero, err := o.request(msg)
+	if err != nil {
+		log.Printf("Dropping the %s of round %s queued for the leader: %v", request.name, msg.Round, err)
+		o.drop(msg)
+		return nil
+	}
+	resp, _, err := requestLeaderOnce(ctx, o.h, o.leaderID, o.cfg.Leader.EOA, o.cfg.Signer, request.protocol, msg.Round, req)
+	zero()
+
+	switch {
+	case err == nil && resp.Status == utils.StatusNotRegistered:
+		log.Printf("Leader does not know this node (%s), registering again", resp.Reason)
+		if regResp, regErr := RegisterWithLeader(ctx, o.h, o.leaderID, o.cfg.Leader.EOA, o.cfg.Signer); regErr != nil {
+			log.Printf("Failed to register with the leader: %v", regErr)
+		} else if !regResp.Status.Accepted() {
+			log.Printf("Leader did not register this node: %v", regResp)
+		}
+	case LeaderAccepted(request.name, msg.Round, resp, err):
+		o.accepted(msg)
+		o.drop(msg)
+		return nil
+	case err == nil && !resp.Status.Retryable():
+		// Sending the same message again cannot succeed
+		o.drop(msg)
+		return nil
+	}
+
+	msg.Attempts++
+	if err != nil {
+		msg.LastError = err.Error()
+	} else {
+		msg.LastError = resp.Error()
+	}
+	msg.NextAttempt = time.Now().Add(outboxBackoff(msg.Attempts))
+	if err := utils.SaveOutboxMessage(msg); err != nil {
+		log.Printf("%v", err)
+	}
+	log.Printf("The %s of round %s is sent again at %s (attempt %d)", request.name, msg.Round, msg.NextAttempt.Format(time.RFC3339), msg.Attempts+1)
+	return &msg
+}
+
+// request builds the request of msg from the stored commit data. zero clears
+// any secret in it once it is sent.
+func (o *Outbox) request(msg utils.OutboxMessage) (interface{}, func(), error) {
+	commitData, err := utils.LoadCommitData(msg.Round)
+	if err != nil {
+		return nil, nil, err
+	}
+	eoaAddress := o.cfg.Address().Hex()
+
+	switch msg.Kind {
+	case utils.OutboxCVS:
+		req := utils.CommitRequest{
+			Round:      msg.Round,
+			Cvs:        commitData.Cvs,
+			EOAAddress: eoaAddress,
+			Sign:       commitData.Sign,
+		}
+		return req, func() {}, nil
+	case utils.OutboxCOS:
+		if err := utils.UnsealCommitData(commitData); err != nil {
+			return nil, nil, err
+		}
+		req := &utils.CosRequest{
+			Round:      msg.Round,
+			Cos:        commitData.Cos,
+			EOAAddress: eoaAddress,
+		}
+		commitData.ZeroSecrets()
+		return req, func() { sensitive.Zero(req.Cos[:]) }, nil
+	default:
+		if err := utils.UnsealCommitData(commitData); err != nil {
+			return nil, nil, err
+		}
+		req := &utils.SecretValueRequest{
+			EOAAddress:  eoaAddress,
+			Round:       msg.Round,
+			SecretValue: commitData.SecretValue[:],
+		}
+		return req, commitData.ZeroSecrets, nil
+	}
+}
+
+// accepted records in the commit data that the leader accepted msg.
+func (o *Outbox) accepted(msg utils.OutboxMessage) {
+	if msg.Kind == utils.OutboxSecret {
+		return
+	}
+	commitData, err := utils.LoadCommitData(msg.Round)
+	if err != nil {
+		log.Printf("Error loading commit data: %v", err)
+		return
+	}
+	if msg.Kind == utils.OutboxCVS {
+		commitData.SendToLeader = true
+	} else {
+		commitData.SendCosToLeader = true
+	}
+	if err := utils.SaveCommitData(*commitData); err != nil {
+		log.Printf("Error saving commit data: %v", err)
+	}
+}
+
+// roundEnded reports whether the random number of a round was generated.
+func (o *Outbox) roundEnded(ctx context.Context, round string) bool {
+	roundNum, ok := new(big.Int).SetString(round, 10)
+	if !ok {
+		return true
+	}
+	info, err := o.drb.RoundInfo(ctx, roundNum)
+	if err != nil {
+		log.Printf("Failed to read round %s: %v", round, err)
+		return false
+	}
+	return info.FulfillSucceeded
+}
+
+func (o *Outbox) drop(msg utils.OutboxMessage) {
+	if err := utils.DeleteOutboxMessage(msg.Round, msg.Kind); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// outboxBackoff returns the wait after a number of failed attempts.
+func outboxBackoff(attempts int) time.Duration {
+	delay := outboxMaxDelay
+	if attempts < 16 {
+		delay = min(outboxBaseDelay<<(attempts-1), outboxMaxDelay)
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
@@ -0,0 +1,197 @@
+package regularNode_helper
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// HandleRevealOrder processes the reveal order the leader publishes for a round.
+// The order is recomputed from the on-chain operators and the published COS
+// values; a wrong order is recorded as evidence and refused.
+func HandleRevealOrder(cfg *config.Config, drb *eth.DRBContract, s network.Stream) {
+	defer s.Close()
+
+	var order utils.RevealOrder
+	env, err := utils.ReadEnvelope(s, &order)
+	if err != nil {
+		log.Printf("Rejected reveal order from peer %s: %v", s.Conn().RemotePeer(), err)
+		replyToLeader(cfg, s, "", utils.StatusInvalid, "the request could not be verified")
+		return
+	}
+	reply := func(status utils.Status, reason string) {
+		replyToLeader(cfg, s, env.Round, status, reason)
+	}
+
+	leaderPeerID, err := peer.Decode(cfg.Leader.PeerID)
+	if err != nil {
+		log.Printf("Failed to decode leader peer ID: %v", err)
+		reply(utils.StatusUnavailable, "the leader peer ID is misconfigured")
+		return
+	}
+	if s.Conn().RemotePeer() != leaderPeerID {
+		log.Printf("Rejected reveal order for round %s from peer %s, the leader is %s", env.Round, s.Conn().RemotePeer(), leaderPeerID)
+		reply(utils.StatusRefused, "the request was not sent by the leader")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := checkLeader(ctx, cfg, drb, env); err != nil {
+		log.Printf("Rejected reveal order for round %s: %v", env.Round, err)
+		reply(utils.StatusRefused, err.Error())
+		return
+	}
+
+	if err := verifyRevealOrder(ctx, cfg, drb, env, &order); err != nil {
+		log.Printf("Rejected reveal order for round %s: %v", env.Round, err)
+		reply(utils.StatusRefused, err.Error())
+		return
+	}
+	log.Printf("Verified reveal order for round %s: %v", order.Round, order.OrderedNodes)
+	reply(utils.StatusOK, "")
+}
+
+// verifyRevealOrder checks a reveal order received from the leader in env and
+// stores it once verified. If the order is wrong, evidence is recorded.
+func verifyRevealOrder(ctx context.Context, cfg *config.Config, drb *eth.DRBContract, env *utils.Envelope, order *utils.RevealOrder) error {
+	reason, err := checkRevealOrder(ctx, cfg, drb, env.Round, order)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		recordEvidence(env, utils.EvidenceRevealOrder, reason)
+		return errors.New(reason)
+	}
+	return utils.SaveVerifiedRevealOrder(order)
+}
+
+// checkRevealOrder returns why order is not the correct reveal order of round,
+// or an empty reason if it is. An error means the order could not be checked.
+func checkRevealOrder(ctx context.Context, cfg *config.Config, drb *eth.DRBContract, round string, order *utils.RevealOrder) (string, error) {
+	if order.Round != round {
+		return fmt.Sprintf("reveal order is for round %s, not %s", order.Round, round), nil
+	}
+
+	verified, err := utils.LoadVerifiedRevealOrder(round)
+	if err == nil {
+		if !verified.Equal(order) {
+			return "reveal order differs from the one already received", nil
+		}
+		return "", nil
+	} else if !errors.Is(err, utils.ErrNotFound) {
+		return "", err
+	}
+
+	roundNum, ok := new(big.Int).SetString(round, 10)
+	if !ok {
+		return fmt.Sprintf("invalid round number %q", round), nil
+	}
+	activated, err := drb.ActivatedOperatorsAtRound(ctx, roundNum)
+	if err != nil {
+		return "", err
+	}
+	if !inOnChainOrder(order.Operators, activated) {
+		return fmt.Sprintf("operators %v are not activated operators of the round in on-chain order", order.Operators), nil
+	}
+
+	self := indexOf(order.Operators, cfg.Address().Hex())
+	if self < 0 {
+		return "this node is not among the operators of the reveal order", nil
+	}
+	commitData, err := utils.LoadCommitData(round)
+	if err != nil {
+		return "", err
+	}
+	if err := utils.UnsealCommitData(commitData); err != nil {
+		return "", err
+	}
+	if len(order.Cos) != len(order.Operators) || order.Cos[self] != hex.EncodeToString(commitData.Cos[:]) {
+		return "the COS of this node in the reveal order is not the one it sent", nil
+	}
+
+	if err := commitreveal2.VerifyRevealOrder(order); err != nil {
+		return err.Error(), nil
+	}
+	return "", nil
+}
+
+// checkRevealTiming checks that the leader asks for this node's secret value
+// at its turn: after the secret of every node before it in the order, whose
+// values must match the COS values they committed to.
+func checkRevealTiming(cfg *config.Config, order *utils.RevealOrder, previousSecrets []string) string {
+	position := indexOf(order.OrderedNodes, cfg.Address().Hex())
+	if len(previousSecrets) != position {
+		return fmt.Sprintf("secret value requested after %d reveals, this node is at position %d", len(previousSecrets), position)
+	}
+
+	for i, secretHex := range previousSecrets {
+		secret, err := hex.DecodeString(secretHex)
+		if err != nil {
+			return fmt.Sprintf("invalid secret value of %s", order.OrderedNodes[i])
+		}
+		cos, err := hex.DecodeString(order.Cos[order.Indexes[i]])
+		if err != nil || !bytes.Equal(crypto.Keccak256(secret), cos) {
+			return fmt.Sprintf("secret value of %s does not match its COS", order.OrderedNodes[i])
+		}
+	}
+	return ""
+}
+
+// recordEvidence stores the leader message env as evidence of misbehaviour.
+func recordEvidence(env *utils.Envelope, kind, reason string) {
+	err := utils.SaveEvidence(utils.Evidence{
+		Round:   env.Round,
+		Kind:    kind,
+		Reason:  reason,
+		Leader:  env.Sender,
+		Message: env,
+	})
+	if err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// inOnChainOrder reports whether operators are distinct activated operators,
+// listed in the order of activated.
+func inOnChainOrder(operators []string, activated []common.Address) bool {
+	next := 0
+	for _, op := range operators {
+		if !common.IsHexAddress(op) {
+			return false
+		}
+		for next < len(activated) && activated[next] != common.HexToAddress(op) {
+			next++
+		}
+		if next == len(activated) {
+			return false
+		}
+		next++
+	}
+	return true
+}
+
+// indexOf returns the index of address in addresses, or -1.
+func indexOf(addresses []string, address string) int {
+	for i, a := range addresses {
+		if common.HexToAddress(a) == common.HexToAddress(address) {
+			return i
+		}
+	}
+	return -1
+}
@@ -2,44 +2,65 @@ package regularNode_helper
 
 import (
 	"context"
-	"encoding/json"
+	"errors"
+	"fmt"
 	"log"
-	"os"
+	"math/big"
+	"time"
 
-	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
 	"github.com/tokamak-network/DRB-node/utils"
 )
 
-// HandleSecretValueRequest processes secret value requests from the leader node
-func HandleSecretValueRequest(h host.Host, s network.Stream) {
+// HandleSecretValueRequest processes secret value requests from the leader node.
+// The secret is only revealed to the authenticated leader, for a round whose
+// Merkle root is on-chain and whose COS this node has already sent, at this
+// node's turn in the verified reveal order. The leader is told whether the
+// secret value follows, or why it does not.
+func HandleSecretValueRequest(cfg *config.Config, drb *eth.DRBContract, seed *commitreveal2.SecretSeed, outbox *Outbox, s network.Stream) {
 	defer s.Close()
 
-	// Decode the request
+	// Decode and verify the request
 	var req utils.SecretValueRequest
-	if err := json.NewDecoder(s).Decode(&req); err != nil {
-		log.Printf("Failed to decode secret value request: %v", err)
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		log.Printf("Rejected secret value request from peer %s: %v", s.Conn().RemotePeer(), err)
+		replyToLeader(cfg, s, "", utils.StatusInvalid, "the request could not be verified")
 		return
 	}
 
-	// Fetch the leader's EOA address from the environment variables
-	leaderEOA := os.Getenv("LEADER_EOA")
-	if leaderEOA == "" {
-		log.Println("LEADER_EOA is not set in the environment variables")
+	if env.Round != req.Round || !env.SentBy(req.EOAAddress) {
+		log.Printf("Envelope of %s for round %s does not match secret value request for round %s from %s", env.Sender, env.Round, req.Round, req.EOAAddress)
+		replyToLeader(cfg, s, env.Round, utils.StatusInvalid, "the request does not match its envelope")
 		return
 	}
+	reply := func(status utils.Status, reason string) {
+		replyToLeader(cfg, s, req.Round, status, reason)
+	}
 
-	// Use the existing signature verification mechanism
-	verifyReq := utils.RegistrationRequest{
-		EOAAddress: req.EOAAddress, // Sender's address
-		Signature:  req.Signature,  // Signature
+	leaderPeerID, err := peer.Decode(cfg.Leader.PeerID)
+	if err != nil {
+		log.Printf("Failed to decode leader peer ID: %v", err)
+		reply(utils.StatusUnavailable, "the leader peer ID is misconfigured")
+		return
+	}
+	if s.Conn().RemotePeer() != leaderPeerID {
+		log.Printf("Rejected secret value request for round %s from peer %s, the leader is %s", req.Round, s.Conn().RemotePeer(), leaderPeerID)
+		reply(utils.StatusRefused, "the request was not sent by the leader")
+		return
 	}
 
-	// Verify the signature
-	if !utils.VerifySignature(verifyReq) {
-		log.Printf("Signature verification failed for secret value request: expected %s, got %s", leaderEOA, req.EOAAddress)
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := checkLeader(ctx, cfg, drb, env); err != nil {
+		log.Printf("Rejected secret value request for round %s: %v", req.Round, err)
+		reply(utils.StatusRefused, err.Error())
 		return
 	}
 
@@ -48,79 +69,105 @@ func HandleSecretValueRequest(h host.Host, s network.Stream) {
 
 	// Fetch the secret value for the specified round
 	commitData, err := utils.LoadCommitData(req.Round)
-	if err != nil {
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
 		log.Printf("Failed to load commit data for round %s: %v", req.Round, err)
+		reply(utils.StatusUnavailable, "the commit data of this node could not be loaded")
 		return
 	}
+	if err != nil {
+		// Whether the COS reached the leader was lost with the commit data. The
+		// round loop sends the COS again and only the leader's acceptance marks
+		// it as sent, so until then the request below is answered too_early.
+		commitData, err = RecoverCommitData(ctx, cfg, drb, seed, req.Round, false)
+		if err != nil {
+			log.Printf("Failed to load commit data for round %s: %v", req.Round, err)
+			reply(utils.StatusUnavailable, "no commit of this node is stored for the round")
+			return
+		}
+	}
 
 	// Check if the secret value exists
-	if commitData.SecretValue == [32]byte{} {
-		log.Printf("No secret value found for round %s", req.Round)
+	if err := utils.UnsealCommitData(commitData); err != nil {
+		log.Printf("No secret value found for round %s: %v", req.Round, err)
+		reply(utils.StatusUnavailable, "the secret value of this node is not available for the round")
 		return
 	}
+	defer commitData.ZeroSecrets()
 
-	// Send the secret value back to the leader
-	leaderPeerIDStr := os.Getenv("LEADER_PEER_ID")
-	if leaderPeerIDStr == "" {
-		log.Fatal("LEADER_PEER_ID is not set in environment variables.")
-	}
-	leaderPeerID, err := peer.Decode(leaderPeerIDStr)
-	if err != nil {
-		log.Printf("Failed to decode leader peer ID: %v", err)
+	if status, err := checkRevealPhase(ctx, drb, commitData); err != nil {
+		log.Printf("Refusing to reveal the secret value for round %s: %v", req.Round, err)
+		reply(status, err.Error())
 		return
 	}
 
-	SendSecretValue(h, leaderPeerID, req.Round)
-}
-
-// SendSecretValue sends the secret value for a round to the leader node
-func SendSecretValue(h host.Host, leaderPeerID peer.ID, roundNum string) {
-	// Load the commit data for the specified round
-	commitData, err := utils.LoadCommitData(roundNum)
-	if err != nil {
-		log.Printf("Failed to load commit data for round %s: %v", roundNum, err)
-		return
+	// Check the reveal order and that it is this node's turn
+	order := req.RevealOrder
+	if order == nil {
+		order, err = utils.LoadVerifiedRevealOrder(req.Round)
+		if err != nil {
+			recordEvidence(env, utils.EvidenceRevealTiming, "secret value requested before the reveal order was published")
+			log.Printf("Refusing to reveal the secret value for round %s: %v", req.Round, err)
+			reply(utils.StatusRefused, "no reveal order was published for the round")
+			return
+		}
 	}
-
-	// Fetch the regular node's private key
-	privateKeyHex := os.Getenv("EOA_PRIVATE_KEY")
-	if privateKeyHex == "" {
-		log.Fatal("EOA_PRIVATE_KEY is not set in the environment variables")
+	if err := verifyRevealOrder(ctx, cfg, drb, env, order); err != nil {
+		log.Printf("Refusing to reveal the secret value for round %s: %v", req.Round, err)
+		reply(utils.StatusRefused, err.Error())
+		return
 	}
-	privateKey, err := crypto.HexToECDSA(privateKeyHex)
-	if err != nil {
-		log.Printf("Failed to decode regular node private key: %v", err)
+	if reason := checkRevealTiming(cfg, order, req.PreviousSecrets); reason != "" {
+		recordEvidence(env, utils.EvidenceRevealTiming, reason)
+		log.Printf("Refusing to reveal the secret value for round %s: %s", req.Round, reason)
+		reply(utils.StatusRefused, reason)
 		return
 	}
 
-	eoaAddress := crypto.PubkeyToAddress(privateKey.PublicKey).Hex()
-	log.Printf("EOA Address: %s", eoaAddress)
+	// Tell the leader the secret value follows, then queue it until the leader accepts it
+	reply(utils.StatusOK, "")
 
-	// Sign the round number using the regular node's private key
-	signature := utils.SignData(eoaAddress, privateKey)
-
-	// Create the secret value request
-	req := utils.SecretValueRequest{
-		EOAAddress:  eoaAddress, // Regular node's Ethereum address
-		Signature:   signature,
-		SecretValue: commitData.SecretValue[:],
-		Round:       roundNum,
+	if err := outbox.Send(req.Round, utils.OutboxSecret); err != nil {
+		log.Printf("Failed to queue the secret value for round %s: %v", req.Round, err)
 	}
+}
 
-	// Open a stream to the leader node
-	stream, err := h.NewStream(context.Background(), leaderPeerID, "/secretValue")
+// checkLeader checks that a request was signed by the configured leader EOA or
+// by the owner of the DRB contract.
+func checkLeader(ctx context.Context, cfg *config.Config, drb *eth.DRBContract, env *utils.Envelope) error {
+	if env.SentBy(cfg.Leader.EOA) {
+		return nil
+	}
+	owner, err := drb.Owner(ctx)
 	if err != nil {
-		log.Printf("Failed to create stream to leader node: %v", err)
-		return
+		return err
 	}
-	defer stream.Close()
+	if env.SentBy(owner.Hex()) {
+		return nil
+	}
+	return fmt.Errorf("request was signed by %s, expected the leader %s or the contract owner %s", env.Sender, cfg.Leader.EOA, owner.Hex())
+}
 
-	// Send the request
-	encoder := json.NewEncoder(stream)
-	if err := encoder.Encode(req); err != nil {
-		log.Printf("Failed to send secret value request: %v", err)
-		return
+// checkRevealPhase checks that a round is in its reveal phase: this node has
+// sent its COS, the Merkle root is on-chain and no random number was generated
+// yet. A failed check comes with the status to answer the leader with.
+func checkRevealPhase(ctx context.Context, drb *eth.DRBContract, commitData *utils.CommitData) (utils.Status, error) {
+	if !commitData.SendCosToLeader {
+		return utils.StatusTooEarly, errors.New("the COS of this node has not been sent yet")
 	}
 
-	log.Printf("Secret value sent for round %s to leader node", roundNum)
+	round, ok := new(big.Int).SetString(commitData.Round, 10)
+	if !ok {
+		return utils.StatusInvalid, fmt.Errorf("invalid round number %q", commitData.Round)
+	}
+	info, err := drb.RoundInfo(ctx, round)
+	if err != nil {
+		return utils.StatusUnavailable, err
+	}
+	if info.MerkleRoot == (common.Hash{}) {
+		return utils.StatusTooEarly, errors.New("no Merkle root is submitted on-chain")
+	}
+	if info.FulfillSucceeded {
+		return utils.StatusTooLate, errors.New("the random number is already generated")
+	}
+	return utils.StatusOK, nil
 }
@@ -0,0 +1,179 @@
+package regularNode_helper
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/host"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// SyncWithLeader sends this node's records of some open rounds to the leader
+// and reconciles its commits with the leader's records in the answer.
+func SyncWithLeader(ctx context.Context, h host.Host, cfg *config.Config, drb *eth.DRBContract, seed *commitreveal2.SecretSeed, outbox *Outbox, leaderID peer.ID, rounds []string) error {
+	req := utils.SyncRequest{EOAAddress: cfg.Address().Hex()}
+	for _, round := range rounds {
+		record := utils.RoundSync{Round: round}
+		if commitData, err := utils.LoadCommitData(round); err == nil {
+			record.Cvs = hex.EncodeToString(commitData.Cvs[:])
+		}
+		req.Rounds = append(req.Rounds, record)
+	}
+
+	resp, _, err := RequestLeader(ctx, h, leaderID, cfg.Leader.EOA, cfg.Signer, utils.ProtocolSync, "", req)
+	if err != nil {
+		return err
+	}
+	if !resp.Status.Accepted() {
+		return fmt.Errorf("leader rejected the sync request: %v", resp)
+	}
+
+	var records []utils.RoundSync
+	if err := json.Unmarshal(resp.Data, &records); err != nil {
+		return fmt.Errorf("failed to decode the leader's records: %v", err)
+	}
+	for _, record := range records {
+		reconcileRound(ctx, cfg, drb, seed, outbox, record)
+	}
+	return nil
+}
+
+// HandleSyncRequest reconciles this node's commits with the records the leader
+// sends after it started, and queues what the leader is missing.
+func HandleSyncRequest(cfg *config.Config, drb *eth.DRBContract, seed *commitreveal2.SecretSeed, outbox *Outbox, s network.Stream) {
+	defer s.Close()
+
+	var req utils.SyncRequest
+	env, err := utils.ReadEnvelope(s, &req)
+	if err != nil {
+		log.Printf("Rejected sync request from peer %s: %v", s.Conn().RemotePeer(), err)
+		replyToLeader(cfg, s, "", utils.StatusInvalid, "the request could not be verified")
+		return
+	}
+	reply := func(status utils.Status, reason string) {
+		replyToLeader(cfg, s, "", status, reason)
+	}
+	if env.Round != "" || !env.SentBy(req.EOAAddress) {
+		log.Printf("Envelope of %s for round %s does not match sync request from %s", env.Sender, env.Round, req.EOAAddress)
+		reply(utils.StatusInvalid, "the request does not match its envelope")
+		return
+	}
+
+	leaderPeerID, err := peer.Decode(cfg.Leader.PeerID)
+	if err != nil {
+		log.Printf("Failed to decode leader peer ID: %v", err)
+		reply(utils.StatusUnavailable, "the leader peer ID is misconfigured")
+		return
+	}
+	if s.Conn().RemotePeer() != leaderPeerID {
+		log.Printf("Rejected sync request from peer %s, the leader is %s", s.Conn().RemotePeer(), leaderPeerID)
+		reply(utils.StatusRefused, "the request was not sent by the leader")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := checkLeader(ctx, cfg, drb, env); err != nil {
+		log.Printf("Rejected sync request: %v", err)
+		reply(utils.StatusRefused, err.Error())
+		return
+	}
+	reply(utils.StatusOK, "")
+
+	for _, record := range req.Rounds {
+		reconcileRound(ctx, cfg, drb, seed, outbox, record)
+	}
+}
+
+// reconcileRound brings this node's commit of a round in line with the
+// leader's record of it, and queues the CVS or COS the leader is missing.
+func reconcileRound(ctx context.Context, cfg *config.Config, drb *eth.DRBContract, seed *commitreveal2.SecretSeed, outbox *Outbox, leader utils.RoundSync) {
+	view := leader.State
+	if view == nil {
+		return
+	}
+	round := leader.Round
+
+	commitData, err := utils.LoadCommitData(round)
+	if err != nil && !errors.Is(err, utils.ErrNotFound) {
+		log.Printf("Failed to load the commit of round %s: %v", round, err)
+		return
+	}
+	if err != nil {
+		// A commit the leader does not hold is generated by the round loop
+		if !view.CvsReceived {
+			return
+		}
+		commitData, err = RecoverCommitData(ctx, cfg, drb, seed, round, view.CosReceived)
+		if err != nil {
+			log.Printf("Failed to recover the commit of round %s held by the leader: %v", round, err)
+			return
+		}
+	}
+	defer commitData.ZeroSecrets()
+
+	if leader.Cvs != "" && leader.Cvs != hex.EncodeToString(commitData.Cvs[:]) {
+		log.Printf("ALERT: Leader holds another CVS for round %s than this node", round)
+		return
+	}
+
+	var queue []string
+	changed := false
+	switch {
+	case view.CvsReceived && !commitData.SendToLeader:
+		commitData.SendToLeader, changed = true, true
+	case !view.CvsReceived && collectingCvs(view.Phase):
+		if commitData.SendToLeader {
+			commitData.SendToLeader, changed = false, true
+		}
+		// An unsigned CVS is signed and queued by the round loop
+		if len(commitData.Sign) > 0 {
+			queue = append(queue, utils.OutboxCVS)
+		}
+	}
+	switch {
+	case view.CosReceived && !commitData.SendCosToLeader:
+		commitData.SendCosToLeader, changed = true, true
+	case !view.CosReceived && commitData.SendCosToLeader && collectingCos(view.Phase):
+		// The Merkle inclusion was checked before the COS was first sent
+		commitData.SendCosToLeader, changed = false, true
+		queue = append(queue, utils.OutboxCOS)
+	}
+
+	if changed {
+		if err := utils.SaveCommitData(*commitData); err != nil {
+			log.Printf("Failed to save commit data for round %s: %v", round, err)
+			return
+		}
+	}
+	for _, kind := range queue {
+		if err := outbox.Send(round, kind); err != nil {
+			log.Printf("Failed to queue the %s of round %s: %v", outboxRequests[kind].name, round, err)
+			continue
+		}
+		log.Printf("Leader is missing the %s of round %s, sending it again", outboxRequests[kind].name, round)
+	}
+	log.Printf("Reconciled round %s with the leader (leader's view: %v)", round, view)
+}
+
+// collectingCvs reports whether the leader accepts CVS in a round in phase.
+// The empty phase is a round the leader does not know yet.
+func collectingCvs(phase utils.RoundPhase) bool {
+	return phase == "" || phase == utils.PhaseRequested || phase == utils.PhaseCollectingCVS
+}
+
+// collectingCos reports whether the leader accepts COS in a round in phase.
+func collectingCos(phase utils.RoundPhase) bool {
+	return phase == utils.PhaseMerkleSubmitted || phase == utils.PhaseCollectingCOS
+}
@@ -0,0 +1,194 @@
+package nodes
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// minParticipants is the smallest number of operators the contract accepts in
+// generateRandomNumber (NotEnoughParticipatedOperators below it).
+const minParticipants = 2
+
+// deadlineCheckInterval is how often the leader checks round deadlines.
+const deadlineCheckInterval = 5 * time.Second
+
+// monitorDeadlines checks every open round against the phase deadlines in cfg.Deadlines.
+func monitorDeadlines(cfg *config.Config, drb *eth.DRBContract) {
+	for {
+		checkDeadlines(cfg, drb)
+		time.Sleep(deadlineCheckInterval)
+	}
+}
+
+func checkDeadlines(cfg *config.Config, drb *eth.DRBContract) {
+	deadlines := cfg.Deadlines
+	states, err := utils.LoadRoundStates()
+	if err != nil {
+		log.Printf("Failed to load round states: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, state := range states {
+		switch state.Phase {
+		case utils.PhaseRequested, utils.PhaseCollectingCVS:
+			if deadline, expired := phaseDeadline(state, utils.PhaseRequested, deadlines.Commit, now); expired {
+				handleCommitTimeout(cfg, drb, state, deadline)
+			}
+		case utils.PhaseMerkleSubmitted, utils.PhaseCollectingCOS:
+			if deadline, expired := phaseDeadline(state, utils.PhaseMerkleSubmitted, deadlines.Cos, now); expired {
+				missing := operatorsMissing(state, func(data utils.LeaderCommitData) bool { return data.Cos != [32]byte{} })
+				failRound(state, deadline, "COS deadline passed", missing)
+			}
+		case utils.PhaseRevealing:
+			if deadline, expired := phaseDeadline(state, utils.PhaseRevealing, deadlines.Reveal, now); expired {
+				missing := operatorsMissing(state, func(data utils.LeaderCommitData) bool { return data.HasSecret() })
+				failRound(state, deadline, "reveal deadline passed", missing)
+			}
+		}
+	}
+}
+
+// phaseDeadline returns the deadline of a phase measured from when the round
+// entered it, and whether it has passed.
+func phaseDeadline(state *utils.RoundState, from utils.RoundPhase, timeout time.Duration, now time.Time) (time.Time, bool) {
+	if timeout <= 0 {
+		return time.Time{}, false
+	}
+	entered, ok := state.EnteredAt(from)
+	if !ok {
+		return time.Time{}, false
+	}
+	deadline := entered.Add(timeout)
+	return deadline, now.After(deadline)
+}
+
+// handleCommitTimeout continues the round with the operators that sent a CVS
+// when the contract allows it, and fails the round otherwise. The Merkle root
+// is submitted in the background, as its retries would hold up the deadlines
+// of the other rounds.
+func handleCommitTimeout(cfg *config.Config, drb *eth.DRBContract, state *utils.RoundState, deadline time.Time) {
+	roundNum := state.Round
+
+	// The round already continues without the missing operators; retry the Merkle root.
+	if len(state.Participants) > 0 {
+		go generateMerkleRoot(cfg, drb, roundNum)
+		return
+	}
+
+	hasCvs := func(data utils.LeaderCommitData) bool { return data.Cvs != [32]byte{} }
+	missing := operatorsMissing(state, hasCvs)
+	participants := operatorsWith(state.ActivatedOperators, roundNum, hasCvs)
+	if len(participants) < minParticipants {
+		failRound(state, deadline, "commit deadline passed", missing)
+		return
+	}
+
+	_, err := utils.UpdateRoundState(roundNum, func(state *utils.RoundState) error {
+		state.Participants = participants
+		state.MissingOperators = missing
+		return state.AdvanceTo(utils.PhaseCollectingCVS)
+	})
+	if err != nil {
+		log.Printf("Failed to record participants for round %s: %v", roundNum, err)
+		return
+	}
+
+	report := utils.RoundReport{
+		Round:            roundNum,
+		Phase:            utils.PhaseCollectingCVS,
+		Reason:           "commit deadline passed",
+		MissingOperators: missing,
+		Participants:     participants,
+		Recovery:         fmt.Sprintf("continuing with %d of %d operators", len(participants), len(state.ActivatedOperators)),
+		Deadline:         deadline,
+		CreatedAt:        time.Now(),
+	}
+	saveRoundReport(report)
+
+	commitMu.Lock()
+	setRoundOperatorsUnlocked(roundNum, participants)
+	commitMu.Unlock()
+
+	go generateMerkleRoot(cfg, drb, roundNum)
+}
+
+// failRound moves a round to the Failed phase and stores its report.
+func failRound(state *utils.RoundState, deadline time.Time, reason string, missing []string) {
+	roundNum := state.Round
+	phase := state.Phase
+
+	if _, err := utils.FailRound(roundNum, reason, missing); err != nil {
+		log.Printf("Failed to mark round %s as failed: %v", roundNum, err)
+		return
+	}
+
+	saveRoundReport(utils.RoundReport{
+		Round:            roundNum,
+		Phase:            phase,
+		Reason:           reason,
+		MissingOperators: missing,
+		Participants:     state.Participants,
+		Deadline:         deadline,
+		CreatedAt:        time.Now(),
+	})
+
+	commitMu.Lock()
+	delete(committedNodes, roundNum)
+	delete(activatedOperators, roundNum)
+	commitMu.Unlock()
+}
+
+func saveRoundReport(report utils.RoundReport) {
+	log.Printf("Round report: %s", report)
+	if err := utils.SaveRoundReport(report); err != nil {
+		log.Printf("%v", err)
+	}
+}
+
+// operatorsMissing returns the operators of a round whose commit data does not satisfy has.
+func operatorsMissing(state *utils.RoundState, has func(utils.LeaderCommitData) bool) []string {
+	present := operatorsWith(state.Operators(), state.Round, has)
+	var missing []string
+	for _, op := range state.Operators() {
+		if !containsAddress(present, op) {
+			missing = append(missing, op)
+		}
+	}
+	return missing
+}
+
+// operatorsWith returns, in the given order, the operators whose stored commit data satisfies has.
+func operatorsWith(operators []string, roundNum string, has func(utils.LeaderCommitData) bool) []string {
+	commits, err := utils.LoadAllLeaderCommitData()
+	if err != nil {
+		log.Printf("Failed to load leader commits: %v", err)
+		return nil
+	}
+
+	var result []string
+	for _, op := range operators {
+		for _, data := range commits {
+			if data.Round == roundNum && common.HexToAddress(data.EOAAddress) == common.HexToAddress(op) && has(data) {
+				result = append(result, op)
+				break
+			}
+		}
+	}
+	return result
+}
+
+func containsAddress(addresses []string, address string) bool {
+	for _, a := range addresses {
+		if common.HexToAddress(a) == common.HexToAddress(address) {
+			return true
+		}
+	}
+	return false
+}
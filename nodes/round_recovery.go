@@ -0,0 +1,210 @@
+package nodes
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/host"
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+	"github.com/tokamak-network/DRB-node/nodes/leaderNode_helper"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// restoreRounds rebuilds the in-memory round maps from the stored round states
+// and leader commits. Rounds stored by versions without round states are
+// migrated from the legacy commit flags first.
+func restoreRounds(cfg *config.Config, drb *eth.DRBContract) {
+	commits, err := utils.LoadAllLeaderCommitData()
+	if err != nil {
+		log.Fatalf("Failed to load leader commits: %v", err)
+	}
+
+	states, err := utils.LoadRoundStates()
+	if err != nil {
+		log.Fatalf("Failed to load round states: %v", err)
+	}
+
+	byRound := make(map[string][]utils.LeaderCommitData)
+	for _, commitData := range commits {
+		byRound[commitData.Round] = append(byRound[commitData.Round], commitData)
+	}
+
+	for round, roundCommits := range byRound {
+		if _, exists := states[round]; exists {
+			continue
+		}
+		state, err := migrateLegacyRound(cfg, drb, round, roundCommits)
+		if err != nil {
+			log.Printf("Failed to migrate stored commits for round %s: %v", round, err)
+			continue
+		}
+		states[round] = state
+	}
+
+	commitMu.Lock()
+	defer commitMu.Unlock()
+
+	for round, state := range states {
+		if state.Terminal() {
+			if state.Phase == utils.PhaseCompleted {
+				if err := utils.PurgeRoundSecrets(round); err != nil {
+					log.Printf("Failed to purge the secrets of round %s: %v", round, err)
+				}
+			}
+			continue
+		}
+		trackRoundUnlocked(state)
+		for _, commitData := range byRound[round] {
+			updateInMemoryData(round, common.HexToAddress(commitData.EOAAddress), commitData)
+		}
+		log.Printf("Restored round %s in phase %s", round, state.Phase)
+	}
+}
+
+// migrateLegacyRound derives a round state from the SubmitMerkleRootDone and
+// RandomNumberGenerated flags of the stored commits.
+func migrateLegacyRound(cfg *config.Config, drb *eth.DRBContract, round string, commits []utils.LeaderCommitData) (*utils.RoundState, error) {
+	var merkleDone, anyCos, anySecret, generated bool
+	for _, commitData := range commits {
+		merkleDone = merkleDone || commitData.SubmitMerkleRootDone
+		anyCos = anyCos || commitData.Cos != [32]byte{}
+		anySecret = anySecret || commitData.HasSecret()
+		generated = generated || commitData.RandomNumberGenerated
+	}
+
+	target := utils.PhaseCollectingCVS
+	switch {
+	case generated:
+		target = utils.PhaseCompleted
+	case anySecret:
+		target = utils.PhaseRevealing
+	case anyCos:
+		target = utils.PhaseCollectingCOS
+	case merkleDone:
+		target = utils.PhaseMerkleSubmitted
+	}
+
+	var operators []string
+	if target != utils.PhaseCompleted {
+		fetched, err := leaderNode_helper.FetchActivatedOperators(cfg, drb, round)
+		if err != nil {
+			return nil, err
+		}
+		operators = filterZeroAddresses(fetched)
+	}
+
+	state := utils.NewRoundState(round, operators)
+	if err := state.AdvanceTo(target); err != nil {
+		return nil, err
+	}
+	if err := utils.SaveRoundState(state); err != nil {
+		return nil, err
+	}
+	log.Printf("Migrated stored commits for round %s to phase %s", round, state.Phase)
+	return state, nil
+}
+
+// recoverMerkleRoot records a Merkle root found on-chain for a round whose
+// submission was not recorded. The operators and leaves of the root are rebuilt
+// from the stored CVS, so operators can still check their inclusion.
+func recoverMerkleRoot(state *utils.RoundState, merkleRoot string) {
+	roundNum := state.Round
+	operators, leaves, err := rebuildMerkleLeaves(state, common.HexToHash(merkleRoot))
+	if err != nil {
+		log.Printf("ALERT: Failed to rebuild the Merkle leaves of round %s, operators cannot check their inclusion: %v", roundNum, err)
+	}
+
+	_, err = utils.UpdateRoundState(roundNum, func(state *utils.RoundState) error {
+		state.MerkleRoot = merkleRoot
+		if len(leaves) > 0 {
+			state.MerkleOperators = operators
+			state.MerkleLeaves = leaves
+		}
+		return state.AdvanceTo(utils.PhaseMerkleSubmitted)
+	})
+	if err != nil {
+		log.Printf("Failed to record on-chain Merkle root for round %s: %v", roundNum, err)
+		return
+	}
+	log.Printf("Merkle root for round %s found on-chain, round moved to %s with %d leaves.", roundNum, utils.PhaseMerkleSubmitted, len(leaves))
+}
+
+// rebuildMerkleLeaves returns the operators of a round and their hex encoded
+// CVS in on-chain order, as generateMerkleRoot builds the tree, after checking
+// that they hash to root.
+func rebuildMerkleLeaves(state *utils.RoundState, root common.Hash) ([]string, []string, error) {
+	operators := state.Operators()
+
+	commitMu.Lock()
+	roundMap := committedNodes[state.Round]
+	var leaves [][]byte
+	for _, op := range operators {
+		data, ok := roundMap[common.HexToAddress(op)]
+		if !ok || data.Cvs == [32]byte{} {
+			commitMu.Unlock()
+			return nil, nil, fmt.Errorf("no CVS of operator %s is stored", op)
+		}
+		leaves = append(leaves, common.CopyBytes(data.Cvs[:]))
+	}
+	commitMu.Unlock()
+
+	tree, err := commitreveal2.NewMerkleTree(leaves)
+	if err != nil {
+		return nil, nil, err
+	}
+	if tree.Root() != root {
+		return nil, nil, fmt.Errorf("the stored CVS hash to %s, the root on-chain is %s", tree.Root().Hex(), root.Hex())
+	}
+
+	hexLeaves := make([]string, len(leaves))
+	for i, leaf := range leaves {
+		hexLeaves[i] = hex.EncodeToString(leaf)
+	}
+	return operators, hexLeaves, nil
+}
+
+// resumeRounds restarts the work of every round that was in flight when the
+// leader stopped. Rounds in the Generating phase are retried by MonitorCommits.
+func resumeRounds(h host.Host, cfg *config.Config, drb *eth.DRBContract) {
+	states, err := utils.LoadRoundStates()
+	if err != nil {
+		log.Printf("Failed to load round states: %v", err)
+		return
+	}
+
+	for round, state := range states {
+		switch state.Phase {
+		case utils.PhaseCollectingCVS:
+			commitMu.Lock()
+			ready := allCommitsReceivedUnlocked(round)
+			commitMu.Unlock()
+			if ready {
+				log.Printf("Resuming round %s: all CVS received, generating Merkle root...", round)
+				generateMerkleRoot(cfg, drb, round)
+			}
+		case utils.PhaseMerkleSubmitted, utils.PhaseCollectingCOS:
+			commitMu.Lock()
+			ready := allCosReceivedUnlocked(round)
+			commitMu.Unlock()
+			if !ready {
+				continue
+			}
+			if _, err := utils.TransitionRound(round, utils.PhaseCollectingCOS); err != nil {
+				log.Printf("Failed to resume round %s: %v", round, err)
+			} else {
+				go startReveal(h, cfg, round)
+			}
+		case utils.PhaseRevealing:
+			if len(state.RevealOrder) == 0 {
+				// The reveal order was computed but not recorded before the restart.
+				leaderNode_helper.StartSecretValueRequests(h, cfg, round)
+				continue
+			}
+			leaderNode_helper.ResumeSecretValueRequests(h, cfg, round)
+		}
+	}
+}
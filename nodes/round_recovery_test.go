@@ -0,0 +1,121 @@
+package nodes
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	commitreveal2 "github.com/tokamak-network/DRB-node/commit-reveal2"
+	"github.com/tokamak-network/DRB-node/utils"
+)
+
+// restartLeader opens a fresh store, stores a round collecting CVS with the
+// CVS of every operator, and restores the leader's in-memory maps from it as
+// a restart does. It returns the operators and their CVS in on-chain order.
+func restartLeader(t *testing.T, round string) ([]string, [][]byte) {
+	t.Helper()
+
+	store, err := utils.OpenStore("bolt", t.TempDir(), "leader")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { store.Close() })
+	utils.SetDefaultStore(store, "leader")
+
+	commitMu.Lock()
+	committedNodes = make(map[string]map[common.Address]utils.LeaderCommitData)
+	activatedOperators = make(map[string]map[common.Address]bool)
+	commitMu.Unlock()
+
+	var operators []string
+	var leaves [][]byte
+	for i := 0; i < 3; i++ {
+		op := common.BytesToAddress([]byte{byte(i + 1)}).Hex()
+		commitData := utils.LeaderCommitData{Round: round, EOAAddress: op}
+		copy(commitData.Cvs[:], crypto.Keccak256([]byte(op)))
+		if err := utils.SaveLeaderCommitData(commitData); err != nil {
+			t.Fatal(err)
+		}
+		operators = append(operators, op)
+		leaves = append(leaves, common.CopyBytes(commitData.Cvs[:]))
+	}
+
+	state := utils.NewRoundState(round, operators)
+	if err := state.Transition(utils.PhaseCollectingCVS); err != nil {
+		t.Fatal(err)
+	}
+	if err := utils.SaveRoundState(state); err != nil {
+		t.Fatal(err)
+	}
+
+	restoreRounds(nil, nil)
+	return operators, leaves
+}
+
+// onChainRound returns the round as the round source reports it with a Merkle
+// root submitted.
+func onChainRound(round string, operators []string, root common.Hash) *GraphQLResponse {
+	var data RoundData
+	data.Round = round
+	data.RandomNumberRequested.ActivatedOperators = operators
+	data.MerkleRootSubmitted.MerkleRoot = root.Hex()
+	return &GraphQLResponse{Rounds: []RoundData{data}}
+}
+
+func TestRestartRecoversMerkleLeaves(t *testing.T) {
+	const round = "7"
+	operators, leaves := restartLeader(t, round)
+
+	tree, err := commitreveal2.NewMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processRounds(nil, nil, onChainRound(round, operators, tree.Root()))
+
+	state, err := utils.LoadRoundState(round)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.Phase != utils.PhaseMerkleSubmitted {
+		t.Errorf("round is in phase %s, expected %s", state.Phase, utils.PhaseMerkleSubmitted)
+	}
+	if state.MerkleRoot != tree.Root().Hex() {
+		t.Errorf("recorded root %s, expected %s", state.MerkleRoot, tree.Root().Hex())
+	}
+	if len(state.MerkleOperators) != len(operators) || len(state.MerkleLeaves) != len(leaves) {
+		t.Fatalf("recorded %d operators and %d leaves, expected %d", len(state.MerkleOperators), len(state.MerkleLeaves), len(operators))
+	}
+	for i := range operators {
+		if state.MerkleOperators[i] != operators[i] {
+			t.Errorf("operator %d is %s, expected %s", i, state.MerkleOperators[i], operators[i])
+		}
+		if state.MerkleLeaves[i] != hex.EncodeToString(leaves[i]) {
+			t.Errorf("leaf %d is %s, expected %x", i, state.MerkleLeaves[i], leaves[i])
+		}
+	}
+}
+
+func TestRestartRejectsMismatchedMerkleRoot(t *testing.T) {
+	const round = "8"
+	operators, leaves := restartLeader(t, round)
+
+	// The on-chain root was built over the leaves in another order
+	leaves[0], leaves[1] = leaves[1], leaves[0]
+	tree, err := commitreveal2.NewMerkleTree(leaves)
+	if err != nil {
+		t.Fatal(err)
+	}
+	processRounds(nil, nil, onChainRound(round, operators, tree.Root()))
+
+	state, err := utils.LoadRoundState(round)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if state.MerkleRoot != tree.Root().Hex() || state.Phase != utils.PhaseMerkleSubmitted {
+		t.Errorf("on-chain root was not recorded: root %s, phase %s", state.MerkleRoot, state.Phase)
+	}
+	if len(state.MerkleLeaves) != 0 || len(state.MerkleOperators) != 0 {
+		t.Errorf("leaves %v that do not hash to the on-chain root were recorded", state.MerkleLeaves)
+	}
+}
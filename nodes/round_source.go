@@ -0,0 +1,70 @@
+package nodes
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/tokamak-network/DRB-node/config"
+	"github.com/tokamak-network/DRB-node/eth"
+)
+
+// roundPollFallback is how long a node waits between round checks when no
+// watcher update arrives (or when only the subgraph is available).
+const roundPollFallback = 30 * time.Second
+
+// startRoundWatcher starts the on-chain round watcher unless the round source is
+// "subgraph". A nil watcher means rounds are read from the subgraph only.
+func startRoundWatcher(ctx context.Context, cfg *config.Config, drb *eth.DRBContract) *eth.RoundWatcher {
+	if cfg.Watcher.Source == "subgraph" {
+		log.Println("Round source is subgraph, reading rounds from the subgraph only.")
+		return nil
+	}
+
+	watcher, err := eth.NewRoundWatcher(drb, cfg.WatcherConfig())
+	if err != nil {
+		if cfg.Ethereum.SubgraphURL == "" {
+			log.Fatalf("Failed to start round watcher: %v", err)
+		}
+		log.Printf("Failed to start round watcher, falling back to the subgraph: %v", err)
+		return nil
+	}
+
+	go watcher.Run(ctx)
+	return watcher
+}
+
+// fetchRounds returns the open rounds in the same shape the subgraph query produces,
+// so both sources feed the same round-processing code.
+func fetchRounds(cfg *config.Config, watcher *eth.RoundWatcher) (*GraphQLResponse, error) {
+	if watcher == nil {
+		return fetchRoundsData(cfg.Ethereum.SubgraphURL)
+	}
+
+	var resp GraphQLResponse
+	for _, r := range watcher.OpenRounds() {
+		var round RoundData
+		round.Round = r.Round
+		if r.MerkleRootSubmitted() {
+			round.MerkleRootSubmitted.MerkleRoot = r.MerkleRoot.Hex()
+		}
+		for _, op := range r.ActivatedOperators {
+			round.RandomNumberRequested.ActivatedOperators = append(round.RandomNumberRequested.ActivatedOperators, op.Hex())
+		}
+		resp.Rounds = append(resp.Rounds, round)
+	}
+	return &resp, nil
+}
+
+// waitForRoundUpdate blocks until the watcher reports new round data or the fallback interval elapses.
+func waitForRoundUpdate(watcher *eth.RoundWatcher) {
+	if watcher == nil {
+		time.Sleep(roundPollFallback)
+		return
+	}
+
+	select {
+	case <-watcher.Updates():
+	case <-time.After(roundPollFallback):
+	}
+}
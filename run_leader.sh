@@ -4,4 +4,4 @@
 export NODE_TYPE="leader"
 
 # Run the leader node in the background
-go run cmd/main.go &
+go run ./cmd run &
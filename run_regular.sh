@@ -4,4 +4,4 @@
 export NODE_TYPE="regular"
 
 # Run the regular node with the leader node's private key in the background
-go run cmd/main.go &
+go run ./cmd run &
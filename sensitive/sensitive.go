@@ -0,0 +1,146 @@
+// Package sensitive holds types for secret values, COS and signatures that
+// redact themselves when formatted, so they cannot leak into logs by accident.
+package sensitive
+
+import (
+	"encoding/hex"
+	"fmt"
+	"log"
+	"runtime"
+	"strings"
+	"sync/atomic"
+)
+
+// Redacted is printed in place of a sensitive value.
+const Redacted = "[REDACTED]"
+
+var reveal atomic.Bool
+
+// RevealInLogs makes sensitive values print in full. It is meant for debugging
+// only and is never enabled by default.
+func RevealInLogs() {
+	if !reveal.Swap(true) {
+		log.Println("WARNING: secret values, COS and signatures are written to the logs in full")
+	}
+}
+
+// Revealed reports whether RevealInLogs was called.
+func Revealed() bool {
+	return reveal.Load()
+}
+
+// secretFields are the names of structured log fields that hold secret values,
+// COS, signatures or keys.
+var secretFields = map[string]bool{
+	"secret":           true,
+	"secrets":          true,
+	"secret_value":     true,
+	"secret_values":    true,
+	"cos":              true,
+	"signature":        true,
+	"sign":             true,
+	"v":                true,
+	"r":                true,
+	"s":                true,
+	"vs":               true,
+	"rs":               true,
+	"ss":               true,
+	"seed":             true,
+	"passphrase":       true,
+	"private_key":      true,
+	"previous_secrets": true,
+}
+
+// IsSecretField reports whether a structured log field named name holds a
+// secret. Names are compared case-insensitively.
+func IsSecretField(name string) bool {
+	return secretFields[strings.ToLower(name)]
+}
+
+// Bytes32 is a secret bytes32 value such as a secret value or an unsent COS.
+type Bytes32 [32]byte
+
+// String returns Redacted, or the 0x prefixed hex value when revealed.
+func (b Bytes32) String() string {
+	return format(b[:])
+}
+
+// GoString implements fmt.GoStringer for %#v.
+func (b Bytes32) GoString() string {
+	return b.String()
+}
+
+// Format implements fmt.Formatter, so every verb is redacted.
+func (b Bytes32) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, b.String())
+}
+
+// Zero overwrites the value with zeros.
+func (b *Bytes32) Zero() {
+	Zero(b[:])
+}
+
+// Bytes is a secret byte slice such as a signature.
+type Bytes []byte
+
+// String returns Redacted, or the 0x prefixed hex value when revealed.
+func (b Bytes) String() string {
+	return format(b)
+}
+
+// GoString implements fmt.GoStringer for %#v.
+func (b Bytes) GoString() string {
+	return b.String()
+}
+
+// Format implements fmt.Formatter, so every verb is redacted.
+func (b Bytes) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, b.String())
+}
+
+// Zero overwrites the slice with zeros.
+func (b Bytes) Zero() {
+	Zero(b)
+}
+
+// String is a secret text value such as a hex encoded secret or signature.
+type String string
+
+// String returns Redacted, or the value when revealed.
+func (s String) String() string {
+	if !Revealed() {
+		return Redacted
+	}
+	return string(s)
+}
+
+// GoString implements fmt.GoStringer for %#v.
+func (s String) GoString() string {
+	return s.String()
+}
+
+// Format implements fmt.Formatter, so every verb is redacted.
+func (s String) Format(f fmt.State, verb rune) {
+	fmt.Fprint(f, s.String())
+}
+
+// Zero overwrites b with zeros. The write is kept even when b is not read
+// afterwards.
+func Zero(b []byte) {
+	clear(b)
+	runtime.KeepAlive(b)
+}
+
+// ZeroAll overwrites every value of values with zeros.
+func ZeroAll(values [][32]byte) {
+	for i := range values {
+		Zero(values[i][:])
+	}
+}
+
+func format(b []byte) string {
+	if !Revealed() {
+		return Redacted
+	}
+	return "0x" + hex.EncodeToString(b)
+}
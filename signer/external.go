@@ -0,0 +1,103 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// ExternalSigner signs through an external signer speaking the Clef API, over
+// HTTP or a local IPC socket. The key never enters the node's process.
+//
+// Clef does not sign raw hashes, so messages are signed as EIP-191 text
+// (personal_sign). VerifyMessage accepts both forms.
+type ExternalSigner struct {
+	ext     *external.ExternalSigner
+	client  *rpc.Client
+	account accounts.Account
+}
+
+// DialExternal connects to the external signer at endpoint, an http(s) URL or
+// an IPC socket path. When account is empty the first account the signer
+// lists is used.
+func DialExternal(endpoint, account string) (*ExternalSigner, error) {
+	ext, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer %s: %v", endpoint, err)
+	}
+	// The typed data endpoint is not exposed by external.ExternalSigner.
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to external signer %s: %v", endpoint, err)
+	}
+
+	s := &ExternalSigner{ext: ext, client: client}
+	if account != "" {
+		if !common.IsHexAddress(account) {
+			client.Close()
+			return nil, fmt.Errorf("invalid external signer account %q", account)
+		}
+		s.account = accounts.Account{Address: common.HexToAddress(account)}
+		return s, nil
+	}
+
+	listed := ext.Accounts()
+	if len(listed) == 0 {
+		client.Close()
+		return nil, errors.New("external signer has no accounts")
+	}
+	s.account = listed[0]
+	return s, nil
+}
+
+// Address returns the account the signer signs for.
+func (s *ExternalSigner) Address() common.Address {
+	return s.account.Address
+}
+
+// SignTx asks the external signer to sign tx.
+func (s *ExternalSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	signed, err := s.ext.SignTx(s.account, tx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("external signer: %v", err)
+	}
+	return signed, nil
+}
+
+// SignMessage asks the external signer to sign data as EIP-191 text.
+func (s *ExternalSigner) SignMessage(data []byte) ([]byte, error) {
+	signature, err := s.ext.SignText(s.account, data)
+	if err != nil {
+		return nil, fmt.Errorf("external signer: %v", err)
+	}
+	return signature, nil
+}
+
+// SignTypedData asks the external signer to sign EIP-712 typed data.
+func (s *ExternalSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	var signature hexutil.Bytes
+	address := common.NewMixedcaseAddress(s.account.Address)
+	if err := s.client.Call(&signature, "account_signTypedData", &address, typedData); err != nil {
+		return nil, fmt.Errorf("external signer: %v", err)
+	}
+	if len(signature) != 65 {
+		return nil, fmt.Errorf("external signer returned a %d byte signature", len(signature))
+	}
+	if signature[64] < 27 {
+		signature[64] += 27
+	}
+	return signature, nil
+}
+
+// Close closes the connection to the external signer.
+func (s *ExternalSigner) Close() {
+	s.client.Close()
+}
@@ -0,0 +1,35 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ReadPassphrase returns the first line of the file at path. When path is
+// empty the passphrase is prompted for on the terminal with prompt.
+func ReadPassphrase(path, prompt string) (string, error) {
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read passphrase file: %v", err)
+		}
+		passphrase, _, _ := strings.Cut(string(data), "\n")
+		return strings.TrimRight(passphrase, "\r"), nil
+	}
+
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", errors.New("no passphrase file is set and stdin is not a terminal")
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", prompt)
+	passphrase, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("failed to read passphrase: %v", err)
+	}
+	return string(passphrase), nil
+}
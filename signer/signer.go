@@ -0,0 +1,29 @@
+// Package signer holds the signing key of a node. A key is either kept in
+// memory, loaded from a raw hex private key or an encrypted Ethereum V3
+// keystore file, or held by an external signer such as Clef.
+package signer
+
+import (
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// Signer signs transactions and messages for a single account.
+type Signer interface {
+	// Address returns the account of the signer.
+	Address() common.Address
+
+	// SignTx signs tx for chainID.
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+
+	// SignMessage signs data exchanged with other nodes. The recovery ID of the
+	// returned signature is 0 or 1; it can be checked with VerifyMessage.
+	SignMessage(data []byte) ([]byte, error)
+
+	// SignTypedData signs EIP-712 typed data. The recovery ID of the returned
+	// signature is 27 or 28, as expected by ecrecover.
+	SignTypedData(typedData apitypes.TypedData) ([]byte, error)
+}
@@ -0,0 +1,23 @@
+package signer
+
+import (
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// VerifyMessage reports whether signature is a SignMessage signature of data
+// by address. Signatures over the keccak256 hash of data (local keys) and
+// EIP-191 text signatures (external signers) are both accepted.
+func VerifyMessage(data, signature []byte, address common.Address) bool {
+	if len(signature) != crypto.SignatureLength {
+		return false
+	}
+	for _, hash := range [][]byte{crypto.Keccak256(data), accounts.TextHash(data)} {
+		pubKey, err := crypto.SigToPub(hash, signature)
+		if err == nil && crypto.PubkeyToAddress(*pubKey) == address {
+			return true
+		}
+	}
+	return false
+}
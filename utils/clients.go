@@ -1,43 +0,0 @@
-package utils
-
-import (
-	"crypto/ecdsa"
-	"encoding/json"
-	"fmt"
-	"os"
-	"strings"
-
-	"github.com/ethereum/go-ethereum/accounts/abi"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
-)
-
-type Client struct {
-	Client          *ethclient.Client
-	ContractABI     abi.ABI
-	ContractAddress common.Address
-	PrivateKey      *ecdsa.PrivateKey // Explicitly use *ecdsa.PrivateKey
-}
-
-func LoadContractABI(filename string) (abi.ABI, error) {
-	abiBytes, err := os.ReadFile(filename)
-	if err != nil {
-		return abi.ABI{}, fmt.Errorf("failed to read ABI file: %v", err)
-	}
-
-	var abiObject struct {
-		ABI json.RawMessage `json:"abi"`
-	}
-
-	err = json.Unmarshal(abiBytes, &abiObject)
-	if err != nil {
-		return abi.ABI{}, fmt.Errorf("failed to unmarshal ABI JSON: %v", err)
-	}
-
-	parsedABI, err := abi.JSON(strings.NewReader(string(abiObject.ABI)))
-	if err != nil {
-		return abi.ABI{}, fmt.Errorf("failed to parse contract ABI: %v", err)
-	}
-
-	return parsedABI, nil
-}
\ No newline at end of file
@@ -0,0 +1,323 @@
+package utils
+
+import (
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+)
+
+// MaxFrameSize bounds a length-prefixed envelope read from a stream.
+const MaxFrameSize = 1 << 20
+
+// On a binary protocol an envelope is sent as a frame: its length as an
+// unsigned varint, then its fields in this order, each variable length field
+// prefixed by its length as an unsigned varint:
+//
+//	protocol, round, sender (20 bytes), peer ID, timestamp (8 bytes, big
+//	endian), nonce, payload, signature
+//
+// The payload of a request with a binary encoding below is that encoding;
+// other payloads, such as Response, stay JSON.
+
+// EncodeEnvelope writes env to w in the encoding of its protocol.
+func EncodeEnvelope(w io.Writer, env *Envelope) error {
+	if !IsBinaryProtocol(env.Protocol) {
+		return json.NewEncoder(w).Encode(env)
+	}
+
+	body := make([]byte, 0, 160+len(env.Payload)+len(env.Signature))
+	body = appendField(body, []byte(env.Protocol))
+	body = appendField(body, []byte(env.Round))
+	body = append(body, common.HexToAddress(env.Sender).Bytes()...)
+	body = appendField(body, []byte(env.PeerID))
+	body = binary.BigEndian.AppendUint64(body, uint64(env.Timestamp))
+	body = appendField(body, []byte(env.Nonce))
+	body = appendField(body, env.Payload)
+	body = appendField(body, env.Signature)
+
+	frame := binary.AppendUvarint(make([]byte, 0, len(body)+binary.MaxVarintLen64), uint64(len(body)))
+	_, err := w.Write(append(frame, body...))
+	return err
+}
+
+// DecodeEnvelope reads an envelope sent on protocol from r.
+func DecodeEnvelope(r io.Reader, protocol string) (*Envelope, error) {
+	var env Envelope
+	if !IsBinaryProtocol(protocol) {
+		if err := json.NewDecoder(r).Decode(&env); err != nil {
+			return nil, err
+		}
+		return &env, nil
+	}
+
+	size, err := binary.ReadUvarint(&byteReader{r: r})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read frame length: %v", err)
+	}
+	if size > MaxFrameSize {
+		return nil, fmt.Errorf("frame of %d bytes exceeds %d bytes", size, MaxFrameSize)
+	}
+	body := make([]byte, size)
+	if _, err := io.ReadFull(r, body); err != nil {
+		return nil, fmt.Errorf("failed to read frame: %v", err)
+	}
+
+	d := decoder{buf: body}
+	env.Protocol = string(d.field())
+	env.Round = string(d.field())
+	env.Sender = common.BytesToAddress(d.fixed(common.AddressLength)).Hex()
+	env.PeerID = string(d.field())
+	env.Timestamp = int64(d.uint64())
+	env.Nonce = string(d.field())
+	env.Payload = d.field()
+	env.Signature = d.field()
+	if err := d.done(); err != nil {
+		return nil, fmt.Errorf("malformed envelope: %v", err)
+	}
+	return &env, nil
+}
+
+// EncodePayload encodes payload for an envelope on protocol.
+func EncodePayload(protocol string, payload interface{}) ([]byte, error) {
+	if m, ok := payload.(encoding.BinaryMarshaler); ok && IsBinaryProtocol(protocol) {
+		return m.MarshalBinary()
+	}
+	return json.Marshal(payload)
+}
+
+// DecodePayload decodes the payload of the envelope into v.
+func (e *Envelope) DecodePayload(v interface{}) error {
+	if u, ok := v.(encoding.BinaryUnmarshaler); ok && IsBinaryProtocol(e.Protocol) {
+		return u.UnmarshalBinary(e.Payload)
+	}
+	return json.Unmarshal(e.Payload, v)
+}
+
+// Binary encodings of the requests. Rounds are unsigned 256-bit big endian
+// integers, as on-chain, and addresses are 20 bytes.
+
+// MarshalBinary encodes the request as round (32), cvs (32), EOA (20), then
+// 0 without a signature or 1, v (1), r (32) and s (32).
+func (r CommitRequest) MarshalBinary() ([]byte, error) {
+	buf, err := appendRound(nil, r.Round)
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, r.Cvs[:]...)
+	buf = append(buf, common.HexToAddress(r.EOAAddress).Bytes()...)
+	if len(r.Sign) == 0 {
+		return append(buf, 0), nil
+	}
+
+	v, err := strconv.ParseUint(r.Sign["v"], 10, 8)
+	if err != nil {
+		return nil, fmt.Errorf("invalid v value %q", r.Sign["v"])
+	}
+	buf = append(buf, 1, byte(v))
+	for _, name := range []string{"r", "s"} {
+		value, err := hex.DecodeString(strings.TrimPrefix(r.Sign[name], "0x"))
+		if err != nil || len(value) != 32 {
+			return nil, fmt.Errorf("invalid %s value %q", name, r.Sign[name])
+		}
+		buf = append(buf, value...)
+	}
+	return buf, nil
+}
+
+// UnmarshalBinary decodes a request encoded by MarshalBinary.
+func (r *CommitRequest) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+	r.Round = d.round()
+	copy(r.Cvs[:], d.fixed(32))
+	r.EOAAddress = common.BytesToAddress(d.fixed(common.AddressLength)).Hex()
+	r.Sign = nil
+	signed := d.fixed(1)
+	if len(signed) == 1 && signed[0] > 1 {
+		return fmt.Errorf("malformed commit request: invalid signature flag %d", signed[0])
+	}
+	if len(signed) == 1 && signed[0] == 1 {
+		v := d.fixed(1)
+		rValue, sValue := d.fixed(32), d.fixed(32)
+		if len(v) == 1 {
+			r.Sign = map[string]string{
+				"v": strconv.Itoa(int(v[0])),
+				"r": hex.EncodeToString(rValue),
+				"s": hex.EncodeToString(sValue),
+			}
+		}
+	}
+	if err := d.done(); err != nil {
+		return fmt.Errorf("malformed commit request: %v", err)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the request as round (32), cos (32) and EOA (20).
+func (r CosRequest) MarshalBinary() ([]byte, error) {
+	buf, err := appendRound(nil, r.Round)
+	if err != nil {
+		return nil, err
+	}
+	buf = append(buf, r.Cos[:]...)
+	return append(buf, common.HexToAddress(r.EOAAddress).Bytes()...), nil
+}
+
+// UnmarshalBinary decodes a request encoded by MarshalBinary.
+func (r *CosRequest) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+	r.Round = d.round()
+	copy(r.Cos[:], d.fixed(32))
+	r.EOAAddress = common.BytesToAddress(d.fixed(common.AddressLength)).Hex()
+	if err := d.done(); err != nil {
+		return fmt.Errorf("malformed COS request: %v", err)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the request as EOA (20), round (32) and the secret
+// value. Only a secret value sent to the leader has a binary encoding; the
+// leader's requests carry the reveal order and stay JSON.
+func (r SecretValueRequest) MarshalBinary() ([]byte, error) {
+	if r.RevealOrder != nil || len(r.PreviousSecrets) > 0 {
+		return nil, errors.New("secret value requests of the leader have no binary encoding")
+	}
+	buf := common.HexToAddress(r.EOAAddress).Bytes()
+	buf, err := appendRound(buf, r.Round)
+	if err != nil {
+		return nil, err
+	}
+	return appendField(buf, r.SecretValue), nil
+}
+
+// UnmarshalBinary decodes a request encoded by MarshalBinary.
+func (r *SecretValueRequest) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+	r.EOAAddress = common.BytesToAddress(d.fixed(common.AddressLength)).Hex()
+	r.Round = d.round()
+	r.SecretValue = d.field()
+	r.RevealOrder, r.PreviousSecrets = nil, nil
+	if err := d.done(); err != nil {
+		return fmt.Errorf("malformed secret value request: %v", err)
+	}
+	return nil
+}
+
+// MarshalBinary encodes the request as EOA (20) and the binary peer ID.
+func (r RegistrationRequest) MarshalBinary() ([]byte, error) {
+	id, err := peer.Decode(r.PeerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid peer ID %q: %v", r.PeerID, err)
+	}
+	buf := common.HexToAddress(r.EOAAddress).Bytes()
+	return appendField(buf, []byte(id)), nil
+}
+
+// UnmarshalBinary decodes a request encoded by MarshalBinary.
+func (r *RegistrationRequest) UnmarshalBinary(data []byte) error {
+	d := decoder{buf: data}
+	r.EOAAddress = common.BytesToAddress(d.fixed(common.AddressLength)).Hex()
+	idBytes := d.field()
+	if err := d.done(); err != nil {
+		return fmt.Errorf("malformed registration request: %v", err)
+	}
+	id, err := peer.IDFromBytes(idBytes)
+	if err != nil {
+		return fmt.Errorf("invalid peer ID: %v", err)
+	}
+	r.PeerID = id.String()
+	return nil
+}
+
+// appendField appends b prefixed by its length.
+func appendField(buf, b []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(b)))
+	return append(buf, b...)
+}
+
+// appendRound appends a decimal round number as a 32-byte integer.
+func appendRound(buf []byte, round string) ([]byte, error) {
+	n, ok := new(big.Int).SetString(round, 10)
+	if !ok || n.Sign() < 0 || n.BitLen() > 256 {
+		return nil, fmt.Errorf("invalid round number %q", round)
+	}
+	return append(buf, common.BigToHash(n).Bytes()...), nil
+}
+
+// decoder reads the fields of a binary encoding. The first error is kept and
+// returned by done; after it every read returns nil.
+type decoder struct {
+	buf []byte
+	err error
+}
+
+func (d *decoder) fixed(n int) []byte {
+	if d.err != nil {
+		return nil
+	}
+	if len(d.buf) < n {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	b := d.buf[:n]
+	d.buf = d.buf[n:]
+	return b
+}
+
+func (d *decoder) field() []byte {
+	if d.err != nil {
+		return nil
+	}
+	n, size := binary.Uvarint(d.buf)
+	if size <= 0 || n > uint64(len(d.buf)-size) {
+		d.err = io.ErrUnexpectedEOF
+		return nil
+	}
+	d.buf = d.buf[size:]
+	return d.fixed(int(n))
+}
+
+func (d *decoder) uint64() uint64 {
+	b := d.fixed(8)
+	if b == nil {
+		return 0
+	}
+	return binary.BigEndian.Uint64(b)
+}
+
+func (d *decoder) round() string {
+	b := d.fixed(32)
+	if b == nil {
+		return ""
+	}
+	return new(big.Int).SetBytes(b).String()
+}
+
+// done returns the first error, or an error if bytes are left over.
+func (d *decoder) done() error {
+	if d.err == nil && len(d.buf) > 0 {
+		d.err = fmt.Errorf("%d unexpected trailing bytes", len(d.buf))
+	}
+	return d.err
+}
+
+// byteReader reads a stream one byte at a time, so reading the frame length
+// does not consume the frame.
+type byteReader struct {
+	r io.Reader
+	b [1]byte
+}
+
+func (b *byteReader) ReadByte() (byte, error) {
+	_, err := io.ReadFull(b.r, b.b[:])
+	return b.b[0], err
+}
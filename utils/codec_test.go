@@ -0,0 +1,286 @@
+package utils
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding"
+	"encoding/binary"
+	"encoding/hex"
+	"io"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+	p2pcrypto "github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/tokamak-network/DRB-node/signer"
+)
+
+// maxRound is the largest round number, which fills all 32 bytes of a round.
+const maxRound = "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+
+// codecRequest is a request with a binary encoding and the protocol it is sent on.
+type codecRequest struct {
+	name    string
+	proto   string
+	payload interface{}
+}
+
+// testRequests returns a request of every type with a binary encoding, sent by
+// the EOA of s from peer from.
+func testRequests(t testing.TB, s signer.Signer, from peer.ID) []codecRequest {
+	t.Helper()
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+	eoa := s.Address().Hex()
+	return []codecRequest{
+		{"registration", ProtocolRegister, RegistrationRequest{EOAAddress: eoa, PeerID: from.String()}},
+		{"cvs", ProtocolCVS, CommitRequest{
+			Round:      maxRound,
+			Cvs:        crypto.Keccak256Hash(secret),
+			EOAAddress: eoa,
+			Sign: map[string]string{
+				"v": "27",
+				"r": hex.EncodeToString(crypto.Keccak256(secret, []byte("r"))),
+				"s": hex.EncodeToString(crypto.Keccak256(secret, []byte("s"))),
+			},
+		}},
+		{"cvs-unsigned", ProtocolCVS, CommitRequest{Round: "1", Cvs: crypto.Keccak256Hash(secret), EOAAddress: eoa}},
+		{"cos", ProtocolCOS, CosRequest{Round: maxRound, Cos: crypto.Keccak256Hash(secret), EOAAddress: eoa}},
+		{"secret-value", ProtocolSecretValue, SecretValueRequest{EOAAddress: eoa, Round: "0", SecretValue: secret}},
+	}
+}
+
+// testSender returns a signer and a peer ID to seal envelopes with.
+func testSender(t testing.TB) (signer.Signer, peer.ID) {
+	t.Helper()
+
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pub, err := p2pcrypto.GenerateEd25519Key(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	from, err := peer.IDFromPublicKey(pub)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return signer.NewKeySigner(key), from
+}
+
+// envelopeIDs returns the IDs of proto that carry envelopes, without its
+// previous versions.
+func envelopeIDs(proto string) []protocol.ID {
+	var ids []protocol.ID
+	for _, id := range ProtocolIDs(proto) {
+		if adapterFor(string(id)) == nil {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// newPayload returns a pointer to a new value of the type of payload.
+func newPayload(payload interface{}) interface{} {
+	return reflect.New(reflect.TypeOf(payload)).Interface()
+}
+
+// sealWire seals payload for proto and returns the envelope as it is sent.
+func sealWire(t testing.TB, s signer.Signer, from peer.ID, proto string, payload interface{}) (*Envelope, []byte) {
+	t.Helper()
+
+	env, err := SealEnvelope(s, proto, "", from, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var wire bytes.Buffer
+	if err := EncodeEnvelope(&wire, env); err != nil {
+		t.Fatal(err)
+	}
+	return env, wire.Bytes()
+}
+
+func TestRequestBinaryRoundTrip(t *testing.T) {
+	s, from := testSender(t)
+	for _, req := range testRequests(t, s, from) {
+		data, err := req.payload.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", req.name, err)
+		}
+		decoded := newPayload(req.payload)
+		if err := decoded.(encoding.BinaryUnmarshaler).UnmarshalBinary(data); err != nil {
+			t.Fatalf("%s: %v", req.name, err)
+		}
+		if got := reflect.ValueOf(decoded).Elem().Interface(); !reflect.DeepEqual(got, req.payload) {
+			t.Errorf("%s: decoded %+v, expected %+v", req.name, got, req.payload)
+		}
+	}
+}
+
+func TestEnvelopeRoundTrip(t *testing.T) {
+	s, from := testSender(t)
+	for _, req := range testRequests(t, s, from) {
+		for _, id := range envelopeIDs(req.proto) {
+			env, wire := sealWire(t, s, from, string(id), req.payload)
+
+			decoded, err := DecodeEnvelope(bytes.NewReader(wire), string(id))
+			if err != nil {
+				t.Fatalf("%s on %s: %v", req.name, id, err)
+			}
+			if !reflect.DeepEqual(decoded, env) {
+				t.Errorf("%s on %s: decoded envelope %+v, expected %+v", req.name, id, decoded, env)
+			}
+			if err := decoded.Verify(string(id), from); err != nil {
+				t.Errorf("%s on %s: decoded envelope does not verify: %v", req.name, id, err)
+			}
+
+			payload := newPayload(req.payload)
+			if err := decoded.DecodePayload(payload); err != nil {
+				t.Fatalf("%s on %s: %v", req.name, id, err)
+			}
+			if got := reflect.ValueOf(payload).Elem().Interface(); !reflect.DeepEqual(got, req.payload) {
+				t.Errorf("%s on %s: decoded payload %+v, expected %+v", req.name, id, got, req.payload)
+			}
+		}
+	}
+}
+
+func TestRequestBinaryRejectsMalformed(t *testing.T) {
+	s, from := testSender(t)
+	for _, req := range testRequests(t, s, from) {
+		data, err := req.payload.(encoding.BinaryMarshaler).MarshalBinary()
+		if err != nil {
+			t.Fatalf("%s: %v", req.name, err)
+		}
+		for n := 0; n < len(data); n++ {
+			if err := newPayload(req.payload).(encoding.BinaryUnmarshaler).UnmarshalBinary(data[:n]); err == nil {
+				t.Errorf("%s: request truncated to %d of %d bytes was accepted", req.name, n, len(data))
+			}
+		}
+		trailing := append(append([]byte{}, data...), 0)
+		if err := newPayload(req.payload).(encoding.BinaryUnmarshaler).UnmarshalBinary(trailing); err == nil {
+			t.Errorf("%s: request with a trailing byte was accepted", req.name)
+		}
+	}
+}
+
+func TestCommitRequestRejectsSignatureFlag(t *testing.T) {
+	req := CommitRequest{Round: "1", EOAAddress: "0x0000000000000000000000000000000000000001"}
+	data, err := req.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, flag := range []byte{2, 0xff} {
+		data[len(data)-1] = flag
+		var decoded CommitRequest
+		if err := decoded.UnmarshalBinary(data); err == nil {
+			t.Errorf("signature flag %d was accepted", flag)
+		}
+	}
+}
+
+func TestRegistrationRequestRejectsPeerID(t *testing.T) {
+	data := append(make([]byte, 20), 3, 'b', 'a', 'd')
+	var decoded RegistrationRequest
+	if err := decoded.UnmarshalBinary(data); err == nil {
+		t.Error("invalid peer ID was accepted")
+	}
+}
+
+func TestDecodeEnvelopeRejectsMalformedFrame(t *testing.T) {
+	s, from := testSender(t)
+	req := testRequests(t, s, from)[1]
+	proto := string(ProtocolIDs(req.proto)[0])
+	if !IsBinaryProtocol(proto) {
+		t.Fatalf("%s is not a binary protocol", proto)
+	}
+	_, wire := sealWire(t, s, from, proto, req.payload)
+
+	size, n := binary.Uvarint(wire)
+	body := wire[n:]
+	if n <= 0 || size != uint64(len(body)) {
+		t.Fatalf("frame length %d does not match the %d bytes of the frame", size, len(body))
+	}
+	frame := func(body []byte) []byte {
+		return append(binary.AppendUvarint(nil, uint64(len(body))), body...)
+	}
+	malformed := map[string][]byte{
+		"empty":            nil,
+		"truncated length": {0x80},
+		"truncated frame":  wire[:len(wire)-1],
+		"trailing bytes":   frame(append(append([]byte{}, body...), 0)),
+		"truncated fields": frame(body[:len(body)-1]),
+		"oversize frame":   binary.AppendUvarint(nil, MaxFrameSize+1),
+		"field too long":   frame([]byte{0xff, 0x01}),
+	}
+	for name, data := range malformed {
+		if _, err := DecodeEnvelope(bytes.NewReader(data), proto); err == nil {
+			t.Errorf("%s: malformed frame was accepted", name)
+		}
+	}
+}
+
+// BenchmarkEncodeEnvelope measures encoding each request and sealing it in an
+// envelope, in the JSON and the binary encoding. It reports the size of the
+// envelope on the wire as bytes/msg.
+func BenchmarkEncodeEnvelope(b *testing.B) {
+	s, from := testSender(b)
+	for _, req := range testRequests(b, s, from) {
+		for _, id := range envelopeIDs(req.proto) {
+			env, wire := sealWire(b, s, from, string(id), req.payload)
+			b.Run(benchmarkName(req.name, string(id)), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ReportMetric(float64(len(wire)), "bytes/msg")
+				for i := 0; i < b.N; i++ {
+					data, err := EncodePayload(string(id), req.payload)
+					if err != nil {
+						b.Fatal(err)
+					}
+					e := *env
+					e.Payload = data
+					if err := EncodeEnvelope(io.Discard, &e); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkDecodeEnvelope measures decoding each request and its envelope, in
+// the JSON and the binary encoding, without the signature check.
+func BenchmarkDecodeEnvelope(b *testing.B) {
+	s, from := testSender(b)
+	for _, req := range testRequests(b, s, from) {
+		for _, id := range envelopeIDs(req.proto) {
+			_, wire := sealWire(b, s, from, string(id), req.payload)
+			b.Run(benchmarkName(req.name, string(id)), func(b *testing.B) {
+				b.ReportAllocs()
+				b.ReportMetric(float64(len(wire)), "bytes/msg")
+				for i := 0; i < b.N; i++ {
+					env, err := DecodeEnvelope(bytes.NewReader(wire), string(id))
+					if err != nil {
+						b.Fatal(err)
+					}
+					if err := env.DecodePayload(newPayload(req.payload)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// benchmarkName names the benchmark of a request on protocol ID id.
+func benchmarkName(request, id string) string {
+	if IsBinaryProtocol(id) {
+		return request + "/binary"
+	}
+	return request + "/json"
+}
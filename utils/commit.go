@@ -1,18 +1,16 @@
 package utils
 
 import (
-	"encoding/json"
+	"errors"
 	"fmt"
-	"os"
-)
 
-const commitDataFile = "commits.json"
+	"github.com/tokamak-network/DRB-node/sensitive"
+)
 
 type CommitRequest struct {
 	Round      string            `json:"round"`
 	Cvs        [32]byte          `json:"cvs"`
 	EOAAddress string            `json:"eoa_address"`
-	Signature  []byte            `json:"signed_round"`
 	Sign       map[string]string `json:"sign"` // New field for v, r, s
 }
 
@@ -20,14 +18,13 @@ type CosRequest struct {
 	Round      string   `json:"round"`
 	Cos        [32]byte `json:"cos"`
 	EOAAddress string   `json:"eoa_address"`
-	Signature  []byte   `json:"signed_round"`
 }
 
 // CommitData defines the structure for storing commit data for the regular node.
 type CommitData struct {
 	Round           string            `json:"round"`
-	SecretValue     [32]byte          `json:"secret_value"`
-	Cos             [32]byte          `json:"cos"`
+	SecretValue     sensitive.Bytes32 `json:"secret_value"`
+	Cos             sensitive.Bytes32 `json:"cos"`
 	Cvs             [32]byte          `json:"cvs"`
 	SendToLeader    bool              `json:"send_to_leader"`
 	SendCosToLeader bool              `json:"send_cos_to_leader"`
@@ -37,70 +34,75 @@ type CommitData struct {
 type Request struct {
 	Round      string `json:"round"`
 	EOAAddress string `json:"eoa_address"`
-	Signature  []byte `json:"signed_round"`
 }
 
-// LoadCommitData loads the commit data for a given round number
+// MerkleLeaves lists the leaves of the Merkle root the leader submitted for a
+// round, so a regular node can check that its CVS is included.
+type MerkleLeaves struct {
+	Round     string   `json:"round"`
+	Operators []string `json:"operators"` // on-chain order
+	Leaves    []string `json:"leaves"`    // hex encoded CVS of each operator
+}
+
+// LoadCommitData loads the commit data for a given round number, without the
+// secret value and COS; see UnsealCommitData. Without commit data for the
+// round the error wraps ErrNotFound.
 func LoadCommitData(roundNum string) (*CommitData, error) {
-	// Open the commit file
-	file, err := os.Open(commitDataFile)
-	if err != nil {
-		if os.IsNotExist(err) {
-			return nil, fmt.Errorf("commit not found") // No commits for this round
-		}
-		return nil, fmt.Errorf("error opening commit data file: %v", err)
+	var commitData CommitData
+	err := DefaultStore().Get(BucketCommits, roundNum, &commitData)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("commit not found: %w", ErrNotFound) // No commits for this round
 	}
-	defer file.Close()
-
-	// Decode JSON data
-	var commits map[string]CommitData
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&commits)
 	if err != nil {
-		return nil, fmt.Errorf("error decoding commit data: %v", err)
-	}
-
-	// Check if commit data exists for the given round
-	commitData, exists := commits[roundNum]
-	if !exists {
-		return nil, fmt.Errorf("commit not found")
+		return nil, fmt.Errorf("error loading commit data: %v", err)
 	}
 
 	return &commitData, nil
 }
 
-// saveCommitData saves the commit data to a file
+// SaveCommitData saves the commit data for its round. The secret value and COS
+// are encrypted into the secret store; commit data loaded without them keeps
+// the stored ones.
 func SaveCommitData(commitData CommitData) error {
-	// Open the commit file (create if doesn't exist)
-	file, err := os.OpenFile(commitDataFile, os.O_CREATE|os.O_RDWR, 0666)
-	if err != nil {
-		return fmt.Errorf("error opening commit data file for writing: %v", err)
+	if commitData.SecretValue != [32]byte{} || commitData.Cos != [32]byte{} {
+		secret := append(commitData.SecretValue[:], commitData.Cos[:]...)
+		err := SealSecret(commitData.Round, "commit", secret)
+		sensitive.Zero(secret)
+		commitData.ZeroSecrets()
+		if err != nil {
+			return fmt.Errorf("error saving commit data: %v", err)
+		}
 	}
-	defer file.Close()
 
-	// Read existing commits
-	var commits map[string]CommitData
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&commits)
-	if err != nil && err.Error() != "EOF" {
-		return fmt.Errorf("error decoding existing commit data: %v", err)
+	if err := DefaultStore().Put(BucketCommits, commitData.Round, commitData); err != nil {
+		return fmt.Errorf("error saving commit data: %v", err)
 	}
 
-	// Add new commit data
-	if commits == nil {
-		commits = make(map[string]CommitData)
-	}
-	commits[commitData.Round] = commitData
+	return nil
+}
 
-	// Seek to the beginning of the file to overwrite it
-	file.Seek(0, 0)
+// ZeroSecrets overwrites the secret value and COS of commitData with zeros once
+// they are no longer needed.
+func (c *CommitData) ZeroSecrets() {
+	c.SecretValue.Zero()
+	c.Cos.Zero()
+}
 
-	// Encode and save the updated commit data
-	encoder := json.NewEncoder(file)
-	err = encoder.Encode(commits)
+// UnsealCommitData decrypts the secret value and COS of commitData from the
+// secret store. It fails once they were purged after the round.
+func UnsealCommitData(commitData *CommitData) error {
+	if commitData.SecretValue != [32]byte{} {
+		return nil
+	}
+	secret, err := UnsealSecret(commitData.Round, "commit")
 	if err != nil {
-		return fmt.Errorf("error encoding commit data: %v", err)
+		return err
 	}
-
+	defer sensitive.Zero(secret)
+	if len(secret) != 64 {
+		return fmt.Errorf("stored secret of round %s is corrupt", commitData.Round)
+	}
+	copy(commitData.SecretValue[:], secret[:32])
+	copy(commitData.Cos[:], secret[32:])
 	return nil
 }
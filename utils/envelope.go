@@ -0,0 +1,198 @@
+package utils
+
+import (
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/tokamak-network/DRB-node/signer"
+)
+
+// EnvelopeMaxAge is how far the timestamp of a received envelope may be from
+// the local clock. Nonces are remembered for twice as long.
+const EnvelopeMaxAge = 2 * time.Minute
+
+// envelopeDomain separates envelope signatures from any other signature made
+// with the same key.
+const envelopeDomain = "DRB-node envelope v1"
+
+// Envelope wraps every message sent over a libp2p stream. The signature covers
+// the protocol ID, round, payload hash, sender peer ID, timestamp and nonce, so
+// a message cannot be replayed on another protocol, round or connection, or
+// again on the same one.
+type Envelope struct {
+	Protocol  string          `json:"protocol"`
+	Round     string          `json:"round"`
+	Sender    string          `json:"sender"`  // EOA address of the signer
+	PeerID    string          `json:"peer_id"` // libp2p peer ID of the sender
+	Timestamp int64           `json:"timestamp"`
+	Nonce     string          `json:"nonce"`
+	Payload   json.RawMessage `json:"payload"`
+	Signature []byte          `json:"signature"`
+}
+
+// SealEnvelope wraps payload for protocol and round and signs it with s on
+// behalf of the libp2p peer from.
+func SealEnvelope(s signer.Signer, protocol, round string, from peer.ID, payload interface{}) (*Envelope, error) {
+	data, err := EncodePayload(protocol, payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode payload: %v", err)
+	}
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+
+	env := &Envelope{
+		Protocol:  protocol,
+		Round:     round,
+		Sender:    s.Address().Hex(),
+		PeerID:    from.String(),
+		Timestamp: time.Now().Unix(),
+		Nonce:     hex.EncodeToString(nonce),
+		Payload:   data,
+	}
+	env.Signature, err = s.SignMessage(env.digest())
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign envelope: %v", err)
+	}
+	return env, nil
+}
+
+// digest returns the hash that is signed, with every variable length field
+// prefixed by its length.
+func (e *Envelope) digest() []byte {
+	var buf []byte
+	field := func(b []byte) {
+		buf = binary.BigEndian.AppendUint32(buf, uint32(len(b)))
+		buf = append(buf, b...)
+	}
+	field([]byte(envelopeDomain))
+	field([]byte(e.Protocol))
+	field([]byte(e.Round))
+	field(common.HexToAddress(e.Sender).Bytes())
+	field([]byte(e.PeerID))
+	buf = binary.BigEndian.AppendUint64(buf, uint64(e.Timestamp))
+	field([]byte(e.Nonce))
+	field(crypto.Keccak256(e.Payload))
+	return crypto.Keccak256(buf)
+}
+
+// Verify checks that the envelope was signed by its sender for protocol, was
+// sent by the peer remote, is fresh and has not been seen before.
+func (e *Envelope) Verify(protocol string, remote peer.ID) error {
+	if e.Protocol != protocol {
+		return fmt.Errorf("envelope for protocol %q received on %q", e.Protocol, protocol)
+	}
+	if e.PeerID != remote.String() {
+		return fmt.Errorf("envelope from peer %s received from peer %s", e.PeerID, remote)
+	}
+	if !common.IsHexAddress(e.Sender) {
+		return fmt.Errorf("invalid envelope sender %q", e.Sender)
+	}
+	age := time.Since(time.Unix(e.Timestamp, 0))
+	if age > EnvelopeMaxAge || age < -EnvelopeMaxAge {
+		return fmt.Errorf("envelope timestamp is %v off the local clock", age.Round(time.Second))
+	}
+	if len(e.Nonce) == 0 {
+		return errors.New("envelope has no nonce")
+	}
+	if !signer.VerifyMessage(e.digest(), e.Signature, common.HexToAddress(e.Sender)) {
+		return fmt.Errorf("envelope signature does not match sender %s", e.Sender)
+	}
+	if !envelopeNonces.add(e.Sender+"/"+e.Nonce, time.Now()) {
+		return fmt.Errorf("replayed envelope from %s", e.Sender)
+	}
+	return nil
+}
+
+// SentBy reports whether the envelope was signed by the EOA address.
+func (e *Envelope) SentBy(eoaAddress string) bool {
+	return common.IsHexAddress(eoaAddress) && common.HexToAddress(e.Sender) == common.HexToAddress(eoaAddress)
+}
+
+// WriteEnvelope seals payload for the protocol of stream and writes it. On a
+// previous version of the protocol, payload is written in its format instead.
+func WriteEnvelope(stream network.Stream, s signer.Signer, round string, payload interface{}) error {
+	if adapter := adapterFor(string(stream.Protocol())); adapter != nil {
+		if err := adapter.encode(stream, s, payload); err != nil {
+			return fmt.Errorf("failed to send request: %v", err)
+		}
+		return nil
+	}
+
+	env, err := SealEnvelope(s, string(stream.Protocol()), round, stream.Conn().LocalPeer(), payload)
+	if err != nil {
+		return err
+	}
+	if err := EncodeEnvelope(stream, env); err != nil {
+		return fmt.Errorf("failed to send envelope: %v", err)
+	}
+	return nil
+}
+
+// ReadEnvelope reads an envelope from stream, verifies it against the stream's
+// protocol and remote peer, and decodes its payload into v. A request on a
+// previous version of the protocol is converted to an envelope of the current
+// request, whose peer ID is the stream's.
+func ReadEnvelope(stream network.Stream, v interface{}) (*Envelope, error) {
+	proto := string(stream.Protocol())
+	var env *Envelope
+	var err error
+	if adapter := adapterFor(proto); adapter != nil {
+		if env, err = adapter.decode(stream, proto, stream.Conn().RemotePeer()); err != nil {
+			return nil, fmt.Errorf("failed to decode %s request: %v", proto, err)
+		}
+	} else {
+		if env, err = DecodeEnvelope(stream, proto); err != nil {
+			return nil, fmt.Errorf("failed to decode envelope: %v", err)
+		}
+		if err := env.Verify(proto, stream.Conn().RemotePeer()); err != nil {
+			return nil, err
+		}
+	}
+	if err := env.DecodePayload(v); err != nil {
+		return nil, fmt.Errorf("failed to decode payload: %v", err)
+	}
+	return env, nil
+}
+
+// nonceCache remembers the nonces of recently received envelopes.
+type nonceCache struct {
+	mu     sync.Mutex
+	ttl    time.Duration
+	seen   map[string]time.Time
+	pruned time.Time
+}
+
+var envelopeNonces = &nonceCache{ttl: 2 * EnvelopeMaxAge, seen: make(map[string]time.Time)}
+
+// add records key and reports whether it was not seen within the TTL.
+func (c *nonceCache) add(key string, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.Sub(c.pruned) > c.ttl {
+		for k, t := range c.seen {
+			if now.Sub(t) > c.ttl {
+				delete(c.seen, k)
+			}
+		}
+		c.pruned = now
+	}
+
+	if t, ok := c.seen[key]; ok && now.Sub(t) <= c.ttl {
+		return false
+	}
+	c.seen[key] = now
+	return true
+}
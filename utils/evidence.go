@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+)
+
+// BucketEvidence holds, on a regular node, the records of leader misbehaviour.
+const BucketEvidence = "evidence"
+
+// Kinds of evidence.
+const (
+	EvidenceRevealOrder  = "reveal_order"  // the published reveal order is wrong
+	EvidenceRevealTiming = "reveal_timing" // a secret was requested out of turn
+	EvidenceMerkleRoot   = "merkle_root"   // the submitted Merkle root does not commit to the node's CVS
+)
+
+// Evidence records a leader message a regular node refused to act on. Message
+// is the envelope as received, so its signature proves what the leader sent.
+type Evidence struct {
+	Round      string    `json:"round"`
+	Kind       string    `json:"kind"`
+	Reason     string    `json:"reason"`
+	Leader     string    `json:"leader"`
+	Message    *Envelope `json:"message,omitempty"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// SaveEvidence stores an evidence record and logs it.
+func SaveEvidence(evidence Evidence) error {
+	evidence.RecordedAt = time.Now()
+	log.Printf("Evidence for round %s (%s): %s", evidence.Round, evidence.Kind, evidence.Reason)
+
+	key := fmt.Sprintf("%s/%s/%d", evidence.Round, evidence.Kind, evidence.RecordedAt.UnixNano())
+	if err := DefaultStore().Put(BucketEvidence, key, evidence); err != nil {
+		return fmt.Errorf("failed to save evidence for round %s: %v", evidence.Round, err)
+	}
+	return nil
+}
+
+// HasEvidence reports whether evidence of a kind was recorded for a round.
+func HasEvidence(round, kind string) (bool, error) {
+	records, err := LoadEvidence(round)
+	if err != nil {
+		return false, err
+	}
+	for _, evidence := range records {
+		if evidence.Kind == kind {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// LoadEvidence returns the evidence recorded for a round, oldest first.
+func LoadEvidence(round string) ([]Evidence, error) {
+	entries, err := DefaultStore().List(BucketEvidence)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load evidence: %v", err)
+	}
+
+	var records []Evidence
+	for key, raw := range entries {
+		var evidence Evidence
+		if err := json.Unmarshal(raw, &evidence); err != nil {
+			return nil, fmt.Errorf("failed to decode evidence %s: %v", key, err)
+		}
+		if evidence.Round == round {
+			records = append(records, evidence)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].RecordedAt.Before(records[j].RecordedAt) })
+	return records, nil
+}
@@ -3,115 +3,169 @@ package utils
 import (
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
-	"os"
-)
 
-const leaderCommitDataFile = "leader_commits.json"
+	"github.com/tokamak-network/DRB-node/sensitive"
+)
 
 // LeaderCommitData defines the structure for storing commit data in the leader node.
 type LeaderCommitData struct {
-	Round                 string            `json:"round"`
-	EOAAddress            string            `json:"eoa_address"`
-	Cvs                   [32]byte          `json:"cvs"`
-	CvsHex                string            `json:"cvs_hex,omitempty"`
-	Cos                   [32]byte          `json:"cos"`
-	CosHex                string            `json:"cos_hex"`
-	SecretValue           [32]byte          `json:"secret_value"`
-	SecretValueHex        string            `json:"secret_value_hex"`
-	Sign                  map[string]string `json:"sign"` // New field for v, r, s
-	SubmitMerkleRootDone  bool              `json:"submit_merkle_root_done"`
-	RandomNumberGenerated bool              `json:"random_number_generated"`
+	Round          string            `json:"round"`
+	EOAAddress     string            `json:"eoa_address"`
+	Cvs            [32]byte          `json:"cvs"`
+	CvsHex         string            `json:"cvs_hex,omitempty"`
+	Cos            [32]byte          `json:"cos"`
+	CosHex         string            `json:"cos_hex"`
+	SecretValue    sensitive.Bytes32 `json:"secret_value"`
+	SecretValueHex sensitive.String  `json:"secret_value_hex"`
+	SecretReceived bool              `json:"secret_received,omitempty"` // the secret value is in the secret store
+	Sign           map[string]string `json:"sign"`                      // New field for v, r, s
+
+	// Deprecated: round progress is tracked by RoundState. These flags are only
+	// read to migrate rounds stored by earlier versions.
+	SubmitMerkleRootDone  bool `json:"submit_merkle_root_done,omitempty"`
+	RandomNumberGenerated bool `json:"random_number_generated,omitempty"`
 }
 
-// LoadLeaderCommitData should load data from the file and return the commit data for a specific round and EOA
+// LoadLeaderCommitData should load data from the store and return the commit data for a specific round and EOA
 func LoadLeaderCommitData(roundNum, eoaAddress string) (*LeaderCommitData, error) {
-    // Open the commit file
-    file, err := os.Open(leaderCommitDataFile)
-    if err != nil {
-        if os.IsNotExist(err) {
-            return nil, fmt.Errorf("commit data not found")
-        }
-        return nil, fmt.Errorf("error opening leader commit data file: %v", err)
-    }
-    defer file.Close()
-
-    // Decode JSON data
-    var commits map[string]LeaderCommitData // Use LeaderCommitData
-    decoder := json.NewDecoder(file)
-    err = decoder.Decode(&commits)
-    if err != nil {
-        return nil, fmt.Errorf("error decoding leader commit data: %v", err)
-    }
-
-    // Construct the composite key: ROUND+EOA
-    key := roundNum + "+" + eoaAddress
-    log.Printf("Loading commit data for key: %s", key) // Debug log for the key
-
-    // Check if commit data exists for the given key
-    commitData, exists := commits[key]
-    if !exists {
-        return nil, fmt.Errorf("commit data not found for key: %s", key)
-    }
-
-    // Convert the CVS hex string back to a byte array
-    if commitData.CvsHex != "" {
-        cvsBytes, err := hex.DecodeString(commitData.CvsHex)
-        if err != nil {
-            return nil, fmt.Errorf("failed to decode CVS hex string: %v", err)
-        }
-        copy(commitData.Cvs[:], cvsBytes)
-    }
-
-    log.Printf("Loaded commit data for key: %s, CVS: %v", key, commitData.Cvs) // Debug log for loaded data
-
-    return &commitData, nil
-}
-
+	// Construct the composite key: ROUND+EOA
+	key := leaderCommitKey(roundNum, eoaAddress)
+	log.Printf("Loading commit data for key: %s", key) // Debug log for the key
 
-// SaveLeaderCommitData should save commit data in the correct format
-func SaveLeaderCommitData(commitData LeaderCommitData) error {
-	// Open the commit file (create if doesn't exist)
-	file, err := os.OpenFile(leaderCommitDataFile, os.O_CREATE|os.O_RDWR, 0666)
+	var commitData LeaderCommitData
+	err := DefaultStore().Get(BucketLeaderCommits, key, &commitData)
+	if errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("commit data not found for key: %s", key)
+	}
 	if err != nil {
-		return fmt.Errorf("error opening leader commit data file for writing: %v", err)
+		return nil, fmt.Errorf("error loading leader commit data: %v", err)
 	}
-	defer file.Close()
 
-	// Read existing commits
-	var commits map[string]LeaderCommitData
-	decoder := json.NewDecoder(file)
-	err = decoder.Decode(&commits)
-	if err != nil && err.Error() != "EOF" {
-		return fmt.Errorf("error decoding existing leader commit data: %v", err)
+	if err := decodeCvsHex(&commitData); err != nil {
+		return nil, err
 	}
 
-	// Construct the composite key: ROUND+EOA
-	key := commitData.Round + "+" + commitData.EOAAddress
+	log.Printf("Loaded commit data for key: %s, CVS: %v", key, commitData.Cvs) // Debug log for loaded data
 
-	// Add or update the commit data
-	if commits == nil {
-		commits = make(map[string]LeaderCommitData)
-	}
+	return &commitData, nil
+}
+
+// SaveLeaderCommitData should save commit data in the correct format
+func SaveLeaderCommitData(commitData LeaderCommitData) error {
+	// Construct the composite key: ROUND+EOA
+	key := leaderCommitKey(commitData.Round, commitData.EOAAddress)
 
 	// If Cvs is present, also store the hex value
 	if commitData.Cvs != [32]byte{} {
 		commitData.CvsHex = hex.EncodeToString(commitData.Cvs[:]) // Convert Cvs byte array to hex string
 	}
 
-	commits[key] = commitData
+	// Keep the secret value encrypted until the random number is generated
+	if err := sealLeaderSecret(&commitData); err != nil {
+		return err
+	}
+
+	if err := DefaultStore().Put(BucketLeaderCommits, key, commitData); err != nil {
+		return fmt.Errorf("error saving leader commit data: %v", err)
+	}
 
-	// Seek to the beginning of the file to overwrite it
-	file.Seek(0, 0)
+	log.Printf("Saved commit data for key: %s", key) // Debug log for commit save
+	return nil
+}
 
-	// Encode and save the updated commit data
-	encoder := json.NewEncoder(file)
-	err = encoder.Encode(commits)
+// LoadAllLeaderCommitData returns every leader commit keyed by ROUND+EOA.
+func LoadAllLeaderCommitData() (map[string]LeaderCommitData, error) {
+	entries, err := DefaultStore().List(BucketLeaderCommits)
 	if err != nil {
-		return fmt.Errorf("error encoding leader commit data: %v", err)
+		return nil, fmt.Errorf("error loading leader commit data: %v", err)
 	}
 
-	log.Printf("Saved commit data for key: %s", key) // Debug log for commit save
+	commits := make(map[string]LeaderCommitData, len(entries))
+	for key, raw := range entries {
+		var commitData LeaderCommitData
+		if err := json.Unmarshal(raw, &commitData); err != nil {
+			return nil, fmt.Errorf("error decoding leader commit data for key %s: %v", key, err)
+		}
+		if err := decodeCvsHex(&commitData); err != nil {
+			return nil, err
+		}
+		commits[key] = commitData
+	}
+	return commits, nil
+}
+
+// SaveAllLeaderCommitData writes several leader commits in one transaction.
+func SaveAllLeaderCommitData(commits map[string]LeaderCommitData) error {
+	values := make(map[string]interface{}, len(commits))
+	for key, commitData := range commits {
+		if err := sealLeaderSecret(&commitData); err != nil {
+			return err
+		}
+		values[key] = commitData
+	}
+	if err := DefaultStore().PutAll(BucketLeaderCommits, values); err != nil {
+		return fmt.Errorf("error saving leader commit data: %v", err)
+	}
+	return nil
+}
+
+// HasSecret reports whether the operator's secret value was received.
+func (c *LeaderCommitData) HasSecret() bool {
+	return c.SecretReceived || c.SecretValue != [32]byte{}
+}
+
+// UnsealLeaderCommitData decrypts the secret value of commitData from the
+// secret store.
+func UnsealLeaderCommitData(commitData *LeaderCommitData) error {
+	if commitData.SecretValue != [32]byte{} || !commitData.SecretReceived {
+		return nil
+	}
+	secret, err := UnsealSecret(commitData.Round, commitData.EOAAddress)
+	if err != nil {
+		return err
+	}
+	copy(commitData.SecretValue[:], secret)
+	sensitive.Zero(secret)
+	return nil
+}
+
+// sealLeaderSecret moves the secret value of commitData into the secret store.
+func sealLeaderSecret(commitData *LeaderCommitData) error {
+	if commitData.SecretValue == [32]byte{} && commitData.SecretValueHex != "" {
+		secret, err := hex.DecodeString(string(commitData.SecretValueHex))
+		if err != nil {
+			return fmt.Errorf("failed to decode secret value hex string: %v", err)
+		}
+		copy(commitData.SecretValue[:], secret)
+		sensitive.Zero(secret)
+	}
+	if commitData.SecretValue == [32]byte{} {
+		return nil
+	}
+	if err := SealSecret(commitData.Round, commitData.EOAAddress, commitData.SecretValue[:]); err != nil {
+		return fmt.Errorf("error saving leader commit data: %v", err)
+	}
+	commitData.SecretValue.Zero()
+	commitData.SecretValueHex, commitData.SecretReceived = "", true
+	return nil
+}
+
+func leaderCommitKey(roundNum, eoaAddress string) string {
+	return roundNum + "+" + eoaAddress
+}
+
+// decodeCvsHex converts the CVS hex string back to a byte array
+func decodeCvsHex(commitData *LeaderCommitData) error {
+	if commitData.CvsHex == "" {
+		return nil
+	}
+	cvsBytes, err := hex.DecodeString(commitData.CvsHex)
+	if err != nil {
+		return fmt.Errorf("failed to decode CVS hex string: %v", err)
+	}
+	copy(commitData.Cvs[:], cvsBytes)
 	return nil
 }
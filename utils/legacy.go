@@ -0,0 +1,144 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/tokamak-network/DRB-node/signer"
+)
+
+// protocolAdapter reads and writes the messages of a previous version of a
+// protocol as envelopes of the current requests.
+type protocolAdapter interface {
+	// decode reads a request sent on id by the peer remote and verifies its
+	// signature.
+	decode(r io.Reader, id string, remote peer.ID) (*Envelope, error)
+	// encode writes payload, a current request, signed with s.
+	encode(w io.Writer, s signer.Signer, payload interface{}) error
+	// answered reports whether the version sends and reads responses.
+	answered() bool
+}
+
+// Versions before the handshake send the bare JSON request on an unversioned
+// ID and read no response. The request carries a signature over its sender's
+// EOA address only, which binds it to neither the round nor the payload; the
+// handlers still bind it where it matters: a registration must come from the
+// peer it names, a CVS carries its EIP-712 signature, a COS must hash to the
+// CVS and a secret value to the COS, and reveal requests must come from the
+// leader's peer.
+
+// legacyRequest is a request in the format of versions before the handshake.
+type legacyRequest interface {
+	// signed returns the EOA address the request claims to be from, and the
+	// signature over it.
+	signed() (string, []byte)
+	// current returns the round and the request in the current format.
+	current() (string, interface{})
+}
+
+type legacyRegistrationRequest struct {
+	RegistrationRequest
+	Signature []byte `json:"signature"`
+}
+
+func (r *legacyRegistrationRequest) signed() (string, []byte) { return r.EOAAddress, r.Signature }
+func (r *legacyRegistrationRequest) current() (string, interface{}) {
+	return "", r.RegistrationRequest
+}
+
+type legacyCommitRequest struct {
+	CommitRequest
+	Signature []byte `json:"signed_round"`
+}
+
+func (r *legacyCommitRequest) signed() (string, []byte)       { return r.EOAAddress, r.Signature }
+func (r *legacyCommitRequest) current() (string, interface{}) { return r.Round, r.CommitRequest }
+
+type legacyCosRequest struct {
+	CosRequest
+	Signature []byte `json:"signed_round"`
+}
+
+func (r *legacyCosRequest) signed() (string, []byte)       { return r.EOAAddress, r.Signature }
+func (r *legacyCosRequest) current() (string, interface{}) { return r.Round, r.CosRequest }
+
+// legacySecretValueRequest is sent both by the leader to request a secret
+// value, without it, and by a node to send it.
+type legacySecretValueRequest struct {
+	SecretValueRequest
+	Signature []byte `json:"signature"`
+}
+
+func (r *legacySecretValueRequest) signed() (string, []byte) { return r.EOAAddress, r.Signature }
+func (r *legacySecretValueRequest) current() (string, interface{}) {
+	return r.Round, r.SecretValueRequest
+}
+
+// legacyAdapter handles the unversioned ID of a protocol. newRequest returns
+// the request sent on it.
+type legacyAdapter struct {
+	newRequest func() legacyRequest
+}
+
+func (a legacyAdapter) decode(r io.Reader, id string, remote peer.ID) (*Envelope, error) {
+	req := a.newRequest()
+	if err := json.NewDecoder(r).Decode(req); err != nil {
+		return nil, err
+	}
+	eoa, signature := req.signed()
+	if !common.IsHexAddress(eoa) {
+		return nil, fmt.Errorf("invalid sender %q", eoa)
+	}
+	if !signer.VerifyMessage([]byte(eoa), signature, common.HexToAddress(eoa)) {
+		return nil, fmt.Errorf("signature does not match sender %s", eoa)
+	}
+
+	round, current := req.current()
+	payload, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	// The peer ID is the one of the stream, as the request does not carry it
+	return &Envelope{
+		Protocol:  id,
+		Round:     round,
+		Sender:    common.HexToAddress(eoa).Hex(),
+		PeerID:    remote.String(),
+		Timestamp: time.Now().Unix(),
+		Payload:   payload,
+		Signature: signature,
+	}, nil
+}
+
+func (a legacyAdapter) encode(w io.Writer, s signer.Signer, payload interface{}) error {
+	signature, err := s.SignMessage([]byte(s.Address().Hex()))
+	if err != nil {
+		return fmt.Errorf("failed to sign request: %v", err)
+	}
+
+	var req interface{}
+	switch current := reflect.Indirect(reflect.ValueOf(payload)).Interface().(type) {
+	case RegistrationRequest:
+		req = legacyRegistrationRequest{current, signature}
+	case CommitRequest:
+		req = legacyCommitRequest{current, signature}
+	case CosRequest:
+		req = legacyCosRequest{current, signature}
+	case SecretValueRequest:
+		// Versions before the handshake do not check the reveal order
+		current.RevealOrder, current.PreviousSecrets = nil, nil
+		req = legacySecretValueRequest{current, signature}
+	default:
+		return fmt.Errorf("%T has no format before the handshake", payload)
+	}
+	return json.NewEncoder(w).Encode(req)
+}
+
+func (legacyAdapter) answered() bool {
+	return false
+}
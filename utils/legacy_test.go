@@ -0,0 +1,123 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// legacyID returns the unversioned ID of proto.
+func legacyID(t *testing.T, proto string) string {
+	t.Helper()
+
+	versions := previousVersions[proto]
+	if len(versions) == 0 {
+		t.Fatalf("%s has no previous version", proto)
+	}
+	return versions[len(versions)-1].id
+}
+
+func TestLegacyRequestRoundTrip(t *testing.T) {
+	s, from := testSender(t)
+	requests := testRequests(t, s, from)
+	requests = append(requests, codecRequest{"reveal-request", ProtocolRevealRequest, SecretValueRequest{
+		EOAAddress:      s.Address().Hex(),
+		Round:           "3",
+		RevealOrder:     &RevealOrder{Round: "3"},
+		PreviousSecrets: []string{"00"},
+	}})
+
+	for _, req := range requests {
+		id := legacyID(t, req.proto)
+		adapter := adapterFor(id)
+
+		var wire bytes.Buffer
+		if err := adapter.encode(&wire, s, req.payload); err != nil {
+			t.Fatalf("%s: %v", req.name, err)
+		}
+		env, err := adapter.decode(&wire, id, from)
+		if err != nil {
+			t.Fatalf("%s: %v", req.name, err)
+		}
+		if env.Protocol != id || env.PeerID != from.String() || !env.SentBy(s.Address().Hex()) {
+			t.Errorf("%s: envelope %+v is not from %s on %s", req.name, env, s.Address().Hex(), id)
+		}
+
+		payload := newPayload(req.payload)
+		if err := env.DecodePayload(payload); err != nil {
+			t.Fatalf("%s: %v", req.name, err)
+		}
+		expected := req.payload
+		if secret, ok := expected.(SecretValueRequest); ok {
+			secret.RevealOrder, secret.PreviousSecrets = nil, nil
+			expected = secret
+		}
+		if got := reflect.ValueOf(payload).Elem().Interface(); !reflect.DeepEqual(got, expected) {
+			t.Errorf("%s: decoded %+v, expected %+v", req.name, got, expected)
+		}
+	}
+}
+
+// TestLegacyBaselineRequest decodes a COS request as versions before the
+// handshake send it.
+func TestLegacyBaselineRequest(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, from := testSender(t)
+	eoa := crypto.PubkeyToAddress(key.PublicKey).Hex()
+	signature, err := crypto.Sign(crypto.Keccak256([]byte(eoa)), key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	baseline := struct {
+		Round      string   `json:"round"`
+		Cos        [32]byte `json:"cos"`
+		EOAAddress string   `json:"eoa_address"`
+		Signature  []byte   `json:"signed_round"`
+	}{"5", crypto.Keccak256Hash([]byte("secret")), eoa, signature}
+	wire, err := json.Marshal(baseline)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := legacyID(t, ProtocolCOS)
+	env, err := adapterFor(id).decode(bytes.NewReader(wire), id, from)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var req CosRequest
+	if err := env.DecodePayload(&req); err != nil {
+		t.Fatal(err)
+	}
+	if env.Round != "5" || !env.SentBy(eoa) || req.Round != "5" || req.Cos != baseline.Cos || req.EOAAddress != eoa {
+		t.Errorf("decoded %+v in %+v, expected %+v", req, env, baseline)
+	}
+}
+
+func TestLegacyRequestRejectsForgedSender(t *testing.T) {
+	s, from := testSender(t)
+	other, _ := testSender(t)
+	id := legacyID(t, ProtocolCOS)
+	adapter := adapterFor(id)
+
+	var wire bytes.Buffer
+	req := CosRequest{Round: "1", EOAAddress: common.HexToAddress("0x01").Hex()}
+	if err := adapter.encode(&wire, other, req); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := adapter.decode(&wire, id, from); err == nil {
+		t.Error("request signed by another EOA than its sender was accepted")
+	}
+
+	wire.Reset()
+	if err := adapter.encode(&wire, s, RevealOrder{}); err == nil {
+		t.Error("request without a format before the handshake was encoded")
+	}
+}
@@ -1,8 +1,6 @@
 package utils
 
 import (
-	"encoding/json"
-	"io/ioutil"
 	"log"
 )
 
@@ -14,18 +12,14 @@ type NodeInfo struct {
 	EOAAddress string `json:"eoa_address"`
 }
 
-// SaveNodeInfo saves the node information to a file
-func SaveNodeInfo(nodeInfos []NodeInfo) error {
-	fileName := "node_info.json"
-	data, err := json.MarshalIndent(nodeInfos, "", "  ")
-	if err != nil {
-		return err
-	}
+// nodeInfoKey is the key of this node's own information in BucketNodeInfo.
+const nodeInfoKey = "self"
 
-	err = ioutil.WriteFile(fileName, data, 0644)
-	if err != nil {
+// SaveNodeInfo saves the node information to the store
+func SaveNodeInfo(nodeInfos []NodeInfo) error {
+	if err := DefaultStore().Put(BucketNodeInfo, nodeInfoKey, nodeInfos); err != nil {
 		return err
 	}
-	log.Printf("Node info saved to %s", fileName)
+	log.Printf("Node info saved")
 	return nil
 }
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// BucketOutbox holds, on a regular node, the messages waiting for the leader
+// to accept them.
+const BucketOutbox = "outbox"
+
+// Kinds of outbox messages.
+const (
+	OutboxCVS    = "cvs"
+	OutboxCOS    = "cos"
+	OutboxSecret = "secret_value"
+)
+
+// OutboxMessage is a message to the leader that is sent until the leader
+// accepts it or its round ends. Only the kind is stored: the request is built
+// from the commit data when it is sent, so no secret is kept in the outbox.
+type OutboxMessage struct {
+	Round       string    `json:"round"`
+	Kind        string    `json:"kind"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// QueueOutboxMessage adds a message of kind for round, due at once. A message
+// already queued keeps its attempts and is made due at once.
+func QueueOutboxMessage(round, kind string) error {
+	now := time.Now()
+	msg := OutboxMessage{Round: round, Kind: kind, CreatedAt: now}
+	err := DefaultStore().Get(BucketOutbox, outboxKey(round, kind), &msg)
+	if err != nil && !errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to load outbox message %s of round %s: %v", kind, round, err)
+	}
+	msg.NextAttempt = now
+	return SaveOutboxMessage(msg)
+}
+
+// SaveOutboxMessage stores or replaces a message.
+func SaveOutboxMessage(msg OutboxMessage) error {
+	if err := DefaultStore().Put(BucketOutbox, outboxKey(msg.Round, msg.Kind), msg); err != nil {
+		return fmt.Errorf("failed to save outbox message %s of round %s: %v", msg.Kind, msg.Round, err)
+	}
+	return nil
+}
+
+// DeleteOutboxMessage removes a message once it is delivered or given up.
+func DeleteOutboxMessage(round, kind string) error {
+	if err := DefaultStore().Delete(BucketOutbox, outboxKey(round, kind)); err != nil {
+		return fmt.Errorf("failed to delete outbox message %s of round %s: %v", kind, round, err)
+	}
+	return nil
+}
+
+// HasOutboxMessage reports whether a message of kind for round is queued.
+func HasOutboxMessage(round, kind string) (bool, error) {
+	var msg OutboxMessage
+	err := DefaultStore().Get(BucketOutbox, outboxKey(round, kind), &msg)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to load outbox message %s of round %s: %v", kind, round, err)
+	}
+	return true, nil
+}
+
+// LoadOutbox returns every queued message, the earliest due first.
+func LoadOutbox() ([]OutboxMessage, error) {
+	entries, err := DefaultStore().List(BucketOutbox)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load outbox: %v", err)
+	}
+
+	messages := make([]OutboxMessage, 0, len(entries))
+	for key, raw := range entries {
+		var msg OutboxMessage
+		if err := json.Unmarshal(raw, &msg); err != nil {
+			return nil, fmt.Errorf("failed to decode outbox message %s: %v", key, err)
+		}
+		messages = append(messages, msg)
+	}
+	sort.Slice(messages, func(i, j int) bool {
+		return messages[i].NextAttempt.Before(messages[j].NextAttempt)
+	})
+	return messages, nil
+}
+
+// LoadRoundOutbox returns the messages queued for a round.
+func LoadRoundOutbox(round string) ([]OutboxMessage, error) {
+	messages, err := LoadOutbox()
+	if err != nil {
+		return nil, err
+	}
+	var queued []OutboxMessage
+	for _, msg := range messages {
+		if msg.Round == round {
+			queued = append(queued, msg)
+		}
+	}
+	return queued, nil
+}
+
+// PurgeRoundOutbox drops every message of a round once the round has ended.
+func PurgeRoundOutbox(round string) error {
+	entries, err := DefaultStore().List(BucketOutbox)
+	if err != nil {
+		return fmt.Errorf("failed to load outbox: %v", err)
+	}
+	for key := range entries {
+		if !strings.HasPrefix(key, round+"/") {
+			continue
+		}
+		if err := DefaultStore().Delete(BucketOutbox, key); err != nil {
+			return fmt.Errorf("failed to delete outbox message %s: %v", key, err)
+		}
+	}
+	return nil
+}
+
+func outboxKey(round, kind string) string {
+	return round + "/" + kind
+}
@@ -1,37 +1,28 @@
 package utils
 
 import (
-	"encoding/json"
 	"errors"
-	"io/ioutil"
 	"log"
-	"os"
 
+	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/libp2p/go-libp2p/core/crypto"
 	"github.com/libp2p/go-libp2p/core/peer"
 )
 
-// PeerIDStorage structure to store PeerID's private key as bytes
+// PeerIDStorage structure to store PeerID's private key encrypted with a passphrase
 type PeerIDStorage struct {
-	PrivateKeyBytes []byte `json:"private_key_bytes"`
+	// PrivateKeyBytes holds an unencrypted key written by older versions. It is
+	// replaced by Crypto the next time the key is loaded.
+	PrivateKeyBytes []byte               `json:"private_key_bytes,omitempty"`
+	Crypto          *keystore.CryptoJSON `json:"crypto,omitempty"`
 }
 
-// getPeerIDFileName returns the appropriate file name based on the node type
-func getPeerIDFileName() string {
-	// Read NODE_TYPE from the environment variable
-	nodeType := os.Getenv("NODE_TYPE")
-
-	// Default to "regular" if NODE_TYPE is not set
-	if nodeType == "" {
-		nodeType = "regular"
-	} else if nodeType == "leader" {
-		return "leader_peer_id.json"
+// SavePeerID encrypts the libp2p PeerID's private key with passphrase and saves it to the store
+func SavePeerID(privKey crypto.PrivKey, passphrase string) error {
+	if passphrase == "" {
+		return errors.New("a passphrase is required to store the libp2p identity")
 	}
-	return "regular_peer_id.json"
-}
 
-// SavePeerID saves the libp2p PeerID's private key to a file as bytes
-func SavePeerID(privKey crypto.PrivKey) error {
 	// Convert the private key to bytes
 	privKeyBytes, err := crypto.MarshalPrivateKey(privKey)
 	if err != nil {
@@ -39,56 +30,60 @@ func SavePeerID(privKey crypto.PrivKey) error {
 		return err
 	}
 
-	// Create the storage object
-	peerIDStorage := PeerIDStorage{PrivateKeyBytes: privKeyBytes}
-
-	// Marshal and save to the correct file based on the node type
-	fileName := getPeerIDFileName()
-	data, err := json.MarshalIndent(peerIDStorage, "", "  ")
+	// Encrypt the key the same way an Ethereum V3 keystore does
+	encrypted, err := keystore.EncryptDataV3(privKeyBytes, []byte(passphrase), keystore.StandardScryptN, keystore.StandardScryptP)
 	if err != nil {
-		log.Printf("Failed to marshal private key bytes: %v", err)
+		log.Printf("Failed to encrypt private key: %v", err)
 		return err
 	}
 
-	err = ioutil.WriteFile(fileName, data, 0644)
+	// Create the storage object
+	peerIDStorage := PeerIDStorage{Crypto: &encrypted}
+
+	// Save under the node type so a leader and a regular node can share storage
+	nodeType := nodeTypeOrDefault()
+	err = DefaultStore().Put(BucketPeerIdentity, nodeType, peerIDStorage)
 	if err != nil {
-		log.Printf("Failed to write private key bytes to %s: %v", fileName, err)
+		log.Printf("Failed to save private key for %s node: %v", nodeType, err)
 		return err
 	}
 
-	log.Printf("Private key saved to %s", fileName)
+	log.Printf("Encrypted private key saved for %s node", nodeType)
 	return nil
 }
 
-// LoadPeerID loads the libp2p PeerID's private key from a file
-func LoadPeerID() (crypto.PrivKey, peer.ID, error) {
-	// Get the correct file name based on the node type
-	fileName := getPeerIDFileName()
+// LoadPeerID loads the libp2p PeerID's private key from the store and decrypts it with passphrase.
+// An unencrypted key left by an older version is encrypted in place.
+func LoadPeerID(passphrase string) (crypto.PrivKey, peer.ID, error) {
+	// Get the correct key based on the node type
+	nodeType := nodeTypeOrDefault()
 
-	// Attempt to read the file containing the private key bytes
-	data, err := ioutil.ReadFile(fileName)
-	if err != nil {
-		// If the file does not exist, return an error
-		log.Printf("Failed to read %s: %v", fileName, err)
-		return nil, "", err
-	}
-
-	// Unmarshal the storage data
+	// Attempt to read the stored private key
 	var peerIDStorage PeerIDStorage
-	err = json.Unmarshal(data, &peerIDStorage)
+	err := DefaultStore().Get(BucketPeerIdentity, nodeType, &peerIDStorage)
 	if err != nil {
-		log.Printf("Failed to unmarshal private key bytes from %s: %v", fileName, err)
+		// If the key does not exist, return an error
+		log.Printf("Failed to load private key for %s node: %v", nodeType, err)
 		return nil, "", err
 	}
 
-	// Ensure the private key bytes exist
-	if peerIDStorage.PrivateKeyBytes == nil {
+	var privKeyBytes []byte
+	switch {
+	case peerIDStorage.Crypto != nil:
+		privKeyBytes, err = keystore.DecryptDataV3(*peerIDStorage.Crypto, passphrase)
+		if err != nil {
+			log.Printf("Failed to decrypt private key for %s node: %v", nodeType, err)
+			return nil, "", err
+		}
+	case peerIDStorage.PrivateKeyBytes != nil:
+		privKeyBytes = peerIDStorage.PrivateKeyBytes
+	default:
 		log.Printf("Private key bytes are missing in the file.")
 		return nil, "", errors.New("private key bytes are empty in storage")
 	}
 
 	// Recreate the private key from the bytes
-	privKey, err := crypto.UnmarshalPrivateKey(peerIDStorage.PrivateKeyBytes)
+	privKey, err := crypto.UnmarshalPrivateKey(privKeyBytes)
 	if err != nil {
 		log.Printf("Failed to unmarshal private key from bytes: %v", err)
 		return nil, "", err
@@ -101,6 +96,13 @@ func LoadPeerID() (crypto.PrivKey, peer.ID, error) {
 		return nil, "", err
 	}
 
-	log.Printf("Loaded private key and PeerID successfully from %s", fileName)
+	if peerIDStorage.Crypto == nil {
+		log.Printf("Encrypting the unencrypted private key of the %s node", nodeType)
+		if err := SavePeerID(privKey, passphrase); err != nil {
+			return nil, "", err
+		}
+	}
+
+	log.Printf("Loaded private key and PeerID successfully for %s node", nodeType)
 	return privKey, peerID, nil
 }
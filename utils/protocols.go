@@ -0,0 +1,128 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/libp2p/go-libp2p/core/protocol"
+)
+
+// Version is the software version a node announces in the handshake. Release
+// builds set it with -ldflags "-X github.com/tokamak-network/DRB-node/utils.Version=<version>".
+var Version = "dev"
+
+// Protocol IDs of the messages between the nodes. The version of a protocol is
+// the version of its payload: a payload change older nodes cannot read gets a
+// new protocol version, and the handler of the previous version is kept until
+// every operator has upgraded.
+const (
+	ProtocolHandshake     = "/drb/handshake/1.0.0"
+	ProtocolRegister      = "/drb/register/1.0.0"
+	ProtocolCVS           = "/drb/cvs/1.0.0"
+	ProtocolCOS           = "/drb/cos/1.0.0"
+	ProtocolSecretValue   = "/drb/secret-value/1.0.0"
+	ProtocolRevealRequest = "/drb/reveal-request/1.0.0" // the leader asks a node for its secret value
+	ProtocolRevealOrder   = "/drb/reveal-order/1.0.0"
+	ProtocolMerkleLeaves  = "/drb/merkle-leaves/1.0.0"
+	ProtocolSync          = "/drb/sync/1.0.0"
+)
+
+// binaryProtocols maps protocols to the IDs that carry the same messages in
+// the binary encoding of codec.go. Both ends of a stream on one of them use
+// the binary encoding; nodes that do not handle it fall back to JSON.
+var binaryProtocols = map[string]string{
+	ProtocolRegister:    "/drb/register/bin/1.0.0",
+	ProtocolCVS:         "/drb/cvs/bin/1.0.0",
+	ProtocolCOS:         "/drb/cos/bin/1.0.0",
+	ProtocolSecretValue: "/drb/secret-value/bin/1.0.0",
+}
+
+// previousVersion is the ID of a previous version of a protocol, with the
+// adapter that converts its messages from and to the current requests.
+type previousVersion struct {
+	id      string
+	adapter protocolAdapter
+}
+
+// previousVersions lists the previous versions of each protocol that are still
+// handled, newest first. A node opens a stream on one of them only when the
+// peer does not handle the current version, so operators can upgrade one at a
+// time. The unversioned IDs are those of versions before the handshake; see
+// legacy.go.
+var previousVersions = map[string][]previousVersion{
+	ProtocolRegister:      {{"/register", legacyAdapter{func() legacyRequest { return new(legacyRegistrationRequest) }}}},
+	ProtocolCVS:           {{"/cvs", legacyAdapter{func() legacyRequest { return new(legacyCommitRequest) }}}},
+	ProtocolCOS:           {{"/cos", legacyAdapter{func() legacyRequest { return new(legacyCosRequest) }}}},
+	ProtocolSecretValue:   {{"/secretValue", legacyAdapter{func() legacyRequest { return new(legacySecretValueRequest) }}}},
+	ProtocolRevealRequest: {{"/sendSecretValue", legacyAdapter{func() legacyRequest { return new(legacySecretValueRequest) }}}},
+}
+
+// adapterFor returns the adapter of the protocol ID if it is a previous
+// version, or nil.
+func adapterFor(id string) protocolAdapter {
+	for _, versions := range previousVersions {
+		for _, version := range versions {
+			if version.id == id {
+				return version.adapter
+			}
+		}
+	}
+	return nil
+}
+
+// IsBinaryProtocol reports whether messages on the protocol ID are sent in the
+// binary encoding.
+func IsBinaryProtocol(id string) bool {
+	for _, binary := range binaryProtocols {
+		if binary == id {
+			return true
+		}
+	}
+	return false
+}
+
+// ProtocolIDs returns the IDs a stream for proto is opened on, the preferred
+// one first, and that its handler is set for. libp2p picks the first one the
+// peer supports.
+func ProtocolIDs(proto string) []protocol.ID {
+	var ids []protocol.ID
+	if binary, ok := binaryProtocols[proto]; ok {
+		ids = append(ids, protocol.ID(binary))
+	}
+	ids = append(ids, protocol.ID(proto))
+	for _, version := range previousVersions[proto] {
+		ids = append(ids, protocol.ID(version.id))
+	}
+	return ids
+}
+
+// Hello is what a node announces about itself in the handshake.
+type Hello struct {
+	EOAAddress string   `json:"eoa_address"` // Sender's EOA address
+	Version    string   `json:"version"`
+	Protocols  []string `json:"protocols"`
+	ChainID    string   `json:"chain_id"`
+	Contract   string   `json:"contract"`
+}
+
+// Supports reports whether the node announcing h handles proto.
+func (h *Hello) Supports(proto string) bool {
+	for _, p := range h.Protocols {
+		if p == proto {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckPeer returns why a node announcing h cannot work with the node
+// announcing peer, or nil.
+func (h *Hello) CheckPeer(peer *Hello) error {
+	if peer.ChainID != h.ChainID {
+		return fmt.Errorf("peer is on chain %s, not %s", peer.ChainID, h.ChainID)
+	}
+	if !strings.EqualFold(peer.Contract, h.Contract) {
+		return fmt.Errorf("peer uses contract %s, not %s", peer.Contract, h.Contract)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/tokamak-network/DRB-node/signer"
+)
+
+// ResponseTimeout is how long a sender waits for the response to a request
+// when its context has no deadline.
+const ResponseTimeout = 30 * time.Second
+
+// Status is the outcome of a request, sent back in its Response.
+type Status string
+
+const (
+	StatusOK            Status = "ok"             // the request was accepted
+	StatusDuplicate     Status = "duplicate"      // the same value was accepted before
+	StatusInvalid       Status = "invalid"        // the request is malformed or not signed by its EOA
+	StatusNotRegistered Status = "not_registered" // the sender must register with the leader again
+	StatusNotActivated  Status = "not_activated"  // the sender is not an activated operator of the round
+	StatusTooEarly      Status = "too_early"      // the round has not reached the phase for the request yet
+	StatusTooLate       Status = "too_late"       // the round is past the phase for the request
+	StatusConflict      Status = "conflict"       // the value contradicts what the receiver already holds
+	StatusRefused       Status = "refused"        // a regular node does not trust the leader's request
+	StatusUnavailable   Status = "unavailable"    // the receiver failed to process the request
+)
+
+// Accepted reports whether the receiver holds the value of the request.
+func (s Status) Accepted() bool {
+	return s == StatusOK || s == StatusDuplicate
+}
+
+// Retryable reports whether the same request may succeed when sent again later.
+func (s Status) Retryable() bool {
+	return s == StatusTooEarly || s == StatusUnavailable
+}
+
+// OperatorState is the leader's view of an operator in a round.
+type OperatorState struct {
+	Phase          RoundPhase `json:"phase,omitempty"`
+	Registered     bool       `json:"registered"`
+	Participant    bool       `json:"participant"` // the operator is expected to take part in the round
+	CvsReceived    bool       `json:"cvs_received"`
+	CosReceived    bool       `json:"cos_received"`
+	SecretReceived bool       `json:"secret_received"`
+}
+
+// String describes the state for logs.
+func (s *OperatorState) String() string {
+	if s == nil {
+		return "unknown"
+	}
+	return fmt.Sprintf("phase=%s registered=%t participant=%t cvs=%t cos=%t secret=%t",
+		s.Phase, s.Registered, s.Participant, s.CvsReceived, s.CosReceived, s.SecretReceived)
+}
+
+// Response answers every request, on the stream of the request. The leader
+// adds its view of the requesting operator; Data carries the result of
+// requests that return one, such as MerkleLeaves.
+type Response struct {
+	Round  string          `json:"round"`
+	Status Status          `json:"status"`
+	Reason string          `json:"reason,omitempty"`
+	State  *OperatorState  `json:"state,omitempty"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+// Error describes a response that was not accepted.
+func (r *Response) Error() string {
+	if r.Reason == "" {
+		return string(r.Status)
+	}
+	return fmt.Sprintf("%s: %s", r.Status, r.Reason)
+}
+
+// WriteResponse signs resp with s and writes it to stream, unless the stream is
+// on a previous protocol version without responses.
+func WriteResponse(stream network.Stream, s signer.Signer, resp *Response) error {
+	if adapter := adapterFor(string(stream.Protocol())); adapter != nil && !adapter.answered() {
+		return nil
+	}
+	return WriteEnvelope(stream, s, resp.Round, resp)
+}
+
+// ReadResponse closes stream for writing and reads the response to a request
+// for round, which must be signed by the EOA sender. An empty sender accepts
+// any signer, for callers that learn it from the response. The envelope is
+// returned so a signed answer can be kept as evidence. On a previous protocol
+// version without responses the request counts as accepted once it is sent,
+// and the envelope is nil.
+func ReadResponse(stream network.Stream, round, sender string) (*Response, *Envelope, error) {
+	if err := stream.CloseWrite(); err != nil {
+		return nil, nil, fmt.Errorf("failed to close stream for writing: %v", err)
+	}
+	if adapter := adapterFor(string(stream.Protocol())); adapter != nil && !adapter.answered() {
+		return &Response{Round: round, Status: StatusOK}, nil, nil
+	}
+	var resp Response
+	env, err := ReadEnvelope(stream, &resp)
+	if err != nil {
+		return nil, nil, fmt.Errorf("no response: %v", err)
+	}
+	if sender != "" && !env.SentBy(sender) {
+		return nil, nil, fmt.Errorf("response was signed by %s, expected %s", env.Sender, sender)
+	}
+	// A request the receiver could not read is rejected without its round
+	unread := resp.Round == "" && !resp.Status.Accepted()
+	if env.Round != resp.Round || (resp.Round != round && !unread) {
+		return nil, nil, fmt.Errorf("response is for round %s, not %s", resp.Round, round)
+	}
+	return &resp, env, nil
+}
+
+// RoundTrip sends req for round on stream, signed with s, and reads the
+// response of sender before the deadline of ctx, or within ResponseTimeout.
+func RoundTrip(ctx context.Context, stream network.Stream, s signer.Signer, round, sender string, req interface{}) (*Response, *Envelope, error) {
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(ResponseTimeout)
+	}
+	stream.SetDeadline(deadline)
+
+	if err := WriteEnvelope(stream, s, round, req); err != nil {
+		return nil, nil, err
+	}
+	return ReadResponse(stream, round, sender)
+}
@@ -0,0 +1,68 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// BucketVerifiedRevealOrders holds, on a regular node, the reveal orders
+// received from the leader and checked by the node itself.
+const BucketVerifiedRevealOrders = "verified_reveal_orders"
+
+// RevealOrder is the Commit-Reveal² reveal order of a round. The leader stores
+// it and publishes it to the participants, who recompute it before revealing.
+type RevealOrder struct {
+	Round string `json:"round"`
+	// Operators are the participants in on-chain activated-operator order.
+	Operators []string `json:"operators"`
+	// Cos holds the hex encoded COS of each operator, in the order of Operators.
+	Cos []string `json:"cos"`
+	// RV is the hex encoded keccak256 of all COS values.
+	RV string `json:"rv"`
+	// Indexes lists the operator indexes in reveal order.
+	Indexes []int `json:"reveal_order"`
+	// OrderedNodes lists the operators in reveal order.
+	OrderedNodes []string `json:"ordered_nodes"`
+}
+
+// Equal reports whether two reveal orders are identical.
+func (o *RevealOrder) Equal(other *RevealOrder) bool {
+	return reflect.DeepEqual(o, other)
+}
+
+// Position returns the index of eoaAddress in the reveal order, or -1.
+func (o *RevealOrder) Position(eoaAddress string) int {
+	for i, node := range o.OrderedNodes {
+		if node == eoaAddress {
+			return i
+		}
+	}
+	return -1
+}
+
+// LoadRevealOrder returns the reveal order the leader stored for a round.
+func LoadRevealOrder(round string) (*RevealOrder, error) {
+	var order RevealOrder
+	if err := DefaultStore().Get(BucketRevealOrders, round, &order); err != nil {
+		return nil, fmt.Errorf("failed to load reveal order for round %s: %w", round, err)
+	}
+	order.Round = round
+	return &order, nil
+}
+
+// SaveVerifiedRevealOrder stores a reveal order the regular node has checked.
+func SaveVerifiedRevealOrder(order *RevealOrder) error {
+	if err := DefaultStore().Put(BucketVerifiedRevealOrders, order.Round, order); err != nil {
+		return fmt.Errorf("failed to save verified reveal order for round %s: %v", order.Round, err)
+	}
+	return nil
+}
+
+// LoadVerifiedRevealOrder returns the checked reveal order of a round.
+func LoadVerifiedRevealOrder(round string) (*RevealOrder, error) {
+	var order RevealOrder
+	if err := DefaultStore().Get(BucketVerifiedRevealOrders, round, &order); err != nil {
+		return nil, fmt.Errorf("failed to load verified reveal order for round %s: %w", round, err)
+	}
+	return &order, nil
+}
@@ -0,0 +1,57 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+const BucketRoundReports = "round_reports"
+
+// RoundReport describes a round that missed a phase deadline or whose
+// transaction was rejected by the contract.
+type RoundReport struct {
+	Round            string     `json:"round"`
+	Phase            RoundPhase `json:"phase"`
+	Reason           string     `json:"reason"`
+	MissingOperators []string   `json:"missing_operators"`
+	Participants     []string   `json:"participants,omitempty"`
+	Recovery         string     `json:"recovery,omitempty"`
+	Deadline         time.Time  `json:"deadline"`
+	CreatedAt        time.Time  `json:"created_at"`
+}
+
+// String formats the report for the node log.
+func (r RoundReport) String() string {
+	s := fmt.Sprintf("round %s %s: %s; missing operators: %v", r.Round, r.Phase, r.Reason, r.MissingOperators)
+	if r.Recovery != "" {
+		s += "; recovery: " + r.Recovery
+	}
+	return s
+}
+
+// SaveRoundReport stores the report of a round, replacing any earlier one.
+func SaveRoundReport(report RoundReport) error {
+	if err := DefaultStore().Put(BucketRoundReports, report.Round, report); err != nil {
+		return fmt.Errorf("failed to save round report %s: %v", report.Round, err)
+	}
+	return nil
+}
+
+// LoadRoundReports returns every stored round report keyed by round number.
+func LoadRoundReports() (map[string]RoundReport, error) {
+	entries, err := DefaultStore().List(BucketRoundReports)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load round reports: %v", err)
+	}
+
+	reports := make(map[string]RoundReport, len(entries))
+	for round, raw := range entries {
+		var report RoundReport
+		if err := json.Unmarshal(raw, &report); err != nil {
+			return nil, fmt.Errorf("failed to decode round report %s: %v", round, err)
+		}
+		reports[round] = report
+	}
+	return reports, nil
+}
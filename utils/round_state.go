@@ -0,0 +1,268 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const BucketRoundStates = "round_states"
+
+// RoundPhase is the leader's progress through a single round.
+type RoundPhase string
+
+const (
+	PhaseRequested       RoundPhase = "requested"
+	PhaseCollectingCVS   RoundPhase = "collecting_cvs"
+	PhaseMerkleSubmitted RoundPhase = "merkle_submitted"
+	PhaseCollectingCOS   RoundPhase = "collecting_cos"
+	PhaseRevealing       RoundPhase = "revealing"
+	PhaseGenerating      RoundPhase = "generating"
+	PhaseCompleted       RoundPhase = "completed"
+	PhaseFailed          RoundPhase = "failed"
+)
+
+// successPath lists the phases of a successful round in order.
+var successPath = []RoundPhase{
+	PhaseRequested,
+	PhaseCollectingCVS,
+	PhaseMerkleSubmitted,
+	PhaseCollectingCOS,
+	PhaseRevealing,
+	PhaseGenerating,
+	PhaseCompleted,
+}
+
+// phaseOrder ranks the phases of a successful round.
+var phaseOrder = func() map[RoundPhase]int {
+	order := make(map[RoundPhase]int, len(successPath))
+	for i, phase := range successPath {
+		order[phase] = i
+	}
+	return order
+}()
+
+// roundTransitions lists the phases each phase may move to.
+var roundTransitions = map[RoundPhase][]RoundPhase{
+	PhaseRequested:       {PhaseCollectingCVS, PhaseFailed},
+	PhaseCollectingCVS:   {PhaseMerkleSubmitted, PhaseFailed},
+	PhaseMerkleSubmitted: {PhaseCollectingCOS, PhaseFailed},
+	PhaseCollectingCOS:   {PhaseRevealing, PhaseFailed},
+	PhaseRevealing:       {PhaseGenerating, PhaseFailed},
+	PhaseGenerating:      {PhaseCompleted, PhaseFailed},
+	PhaseCompleted:       {},
+	PhaseFailed:          {},
+}
+
+// PhaseChange records when a round entered a phase.
+type PhaseChange struct {
+	Phase RoundPhase `json:"phase"`
+	At    time.Time  `json:"at"`
+}
+
+// RoundState is the persisted state of a round on the leader node.
+type RoundState struct {
+	Round              string        `json:"round"`
+	Phase              RoundPhase    `json:"phase"`
+	ActivatedOperators []string      `json:"activated_operators"` // on-chain order
+	MerkleRoot         string        `json:"merkle_root,omitempty"`
+	MerkleOperators    []string      `json:"merkle_operators,omitempty"` // operators whose CVS are the Merkle leaves
+	MerkleLeaves       []string      `json:"merkle_leaves,omitempty"`    // hex encoded CVS, in leaf order
+	RevealOrder        []string      `json:"reveal_order,omitempty"`
+	RevealRequested    []string      `json:"reveal_requested,omitempty"`
+	Participants       []string      `json:"participants,omitempty"` // set when the round continues without some operators
+	MissingOperators   []string      `json:"missing_operators,omitempty"`
+	FailureReason      string        `json:"failure_reason,omitempty"`
+	History            []PhaseChange `json:"history"`
+	UpdatedAt          time.Time     `json:"updated_at"`
+}
+
+// NewRoundState returns a round in the Requested phase.
+func NewRoundState(round string, activatedOperators []string) *RoundState {
+	now := time.Now()
+	return &RoundState{
+		Round:              round,
+		Phase:              PhaseRequested,
+		ActivatedOperators: activatedOperators,
+		History:            []PhaseChange{{Phase: PhaseRequested, At: now}},
+		UpdatedAt:          now,
+	}
+}
+
+// CanTransition reports whether the round may move to the given phase.
+func (s *RoundState) CanTransition(to RoundPhase) bool {
+	for _, next := range roundTransitions[s.Phase] {
+		if next == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Transition moves the round to the given phase. Moving to the current phase is a no-op.
+func (s *RoundState) Transition(to RoundPhase) error {
+	if s.Phase == to {
+		return nil
+	}
+	if !s.CanTransition(to) {
+		return fmt.Errorf("invalid round transition for round %s: %s -> %s", s.Round, s.Phase, to)
+	}
+
+	now := time.Now()
+	s.Phase = to
+	s.History = append(s.History, PhaseChange{Phase: to, At: now})
+	s.UpdatedAt = now
+	return nil
+}
+
+// AdvanceTo walks the round forward through every intermediate phase up to the
+// given one. It is used when a later phase is observed directly, e.g. a Merkle
+// root found on-chain for a round whose submission was not recorded locally.
+func (s *RoundState) AdvanceTo(to RoundPhase) error {
+	if to == PhaseFailed {
+		return s.Transition(PhaseFailed)
+	}
+	if s.Reached(to) {
+		return nil
+	}
+	if s.Phase == PhaseFailed {
+		return fmt.Errorf("round %s has failed and cannot advance to %s", s.Round, to)
+	}
+	for _, phase := range successPath[phaseOrder[s.Phase]+1 : phaseOrder[to]+1] {
+		if err := s.Transition(phase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reached reports whether the round has progressed to at least the given phase.
+// A failed round has not reached any phase.
+func (s *RoundState) Reached(phase RoundPhase) bool {
+	if s.Phase == PhaseFailed {
+		return phase == PhaseFailed
+	}
+	return phaseOrder[s.Phase] >= phaseOrder[phase]
+}
+
+// Operators returns the operators expected to take part in the round: the
+// participants if the round continued without some operators, otherwise every
+// activated operator.
+func (s *RoundState) Operators() []string {
+	if len(s.Participants) > 0 {
+		return s.Participants
+	}
+	return s.ActivatedOperators
+}
+
+// EnteredAt returns when the round last entered the given phase.
+func (s *RoundState) EnteredAt(phase RoundPhase) (time.Time, bool) {
+	for i := len(s.History) - 1; i >= 0; i-- {
+		if s.History[i].Phase == phase {
+			return s.History[i].At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// Terminal reports whether the round is completed or failed.
+func (s *RoundState) Terminal() bool {
+	return s.Phase == PhaseCompleted || s.Phase == PhaseFailed
+}
+
+// roundStateMu serializes read-modify-write cycles on round states.
+var roundStateMu sync.Mutex
+
+// LoadRoundState returns the stored state of a round, or ErrNotFound.
+func LoadRoundState(round string) (*RoundState, error) {
+	var state RoundState
+	if err := DefaultStore().Get(BucketRoundStates, round, &state); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// LoadRoundStates returns every stored round state keyed by round number.
+func LoadRoundStates() (map[string]*RoundState, error) {
+	entries, err := DefaultStore().List(BucketRoundStates)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load round states: %v", err)
+	}
+
+	states := make(map[string]*RoundState, len(entries))
+	for round, raw := range entries {
+		var state RoundState
+		if err := json.Unmarshal(raw, &state); err != nil {
+			return nil, fmt.Errorf("failed to decode round state %s: %v", round, err)
+		}
+		states[round] = &state
+	}
+	return states, nil
+}
+
+// SaveRoundState stores the state of a round.
+func SaveRoundState(state *RoundState) error {
+	if err := DefaultStore().Put(BucketRoundStates, state.Round, state); err != nil {
+		return fmt.Errorf("failed to save round state %s: %v", state.Round, err)
+	}
+	return nil
+}
+
+// UpdateRoundState loads the state of a round, applies fn and saves the result.
+// Nothing is saved when fn returns an error.
+func UpdateRoundState(round string, fn func(*RoundState) error) (*RoundState, error) {
+	roundStateMu.Lock()
+	defer roundStateMu.Unlock()
+
+	state, err := LoadRoundState(round)
+	if err != nil {
+		return nil, err
+	}
+	if err := fn(state); err != nil {
+		return nil, err
+	}
+	state.UpdatedAt = time.Now()
+	if err := SaveRoundState(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// EnsureRoundState creates the state of a round in the Requested phase unless it already exists.
+func EnsureRoundState(round string, activatedOperators []string) (*RoundState, error) {
+	roundStateMu.Lock()
+	defer roundStateMu.Unlock()
+
+	state, err := LoadRoundState(round)
+	if err == nil {
+		return state, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	state = NewRoundState(round, activatedOperators)
+	if err := SaveRoundState(state); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// TransitionRound moves a stored round to the given phase.
+func TransitionRound(round string, to RoundPhase) (*RoundState, error) {
+	return UpdateRoundState(round, func(state *RoundState) error {
+		return state.Transition(to)
+	})
+}
+
+// FailRound moves a stored round to the Failed phase, recording why and the
+// operators that were missing, if any.
+func FailRound(round, reason string, missing []string) (*RoundState, error) {
+	return UpdateRoundState(round, func(state *RoundState) error {
+		state.FailureReason = reason
+		state.MissingOperators = missing
+		return state.Transition(PhaseFailed)
+	})
+}
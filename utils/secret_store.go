@@ -0,0 +1,327 @@
+package utils
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/tokamak-network/DRB-node/sensitive"
+	"golang.org/x/crypto/scrypt"
+)
+
+// BucketSecrets holds pre-reveal secrets encrypted with AES-256-GCM. Each key
+// is "<round>/<name>", so the secrets of a round can be purged together.
+const BucketSecrets = "secrets"
+
+// secretParamsKey holds the key derivation parameters of the bucket.
+const secretParamsKey = "params"
+
+// secretCheck is encrypted with the derived key to detect a wrong passphrase.
+const secretCheck = "DRB-node secret store"
+
+// ErrSecretStoreClosed is returned when a secret is stored or read before
+// OpenSecretStore was called.
+var ErrSecretStoreClosed = errors.New("secret store is not open")
+
+// ErrWrongSecretPassphrase is returned when the secret store was encrypted with
+// another passphrase.
+var ErrWrongSecretPassphrase = errors.New("failed to open the secret store: wrong passphrase")
+
+type secretParams struct {
+	Salt  []byte `json:"salt"`
+	N     int    `json:"n"`
+	R     int    `json:"r"`
+	P     int    `json:"p"`
+	Check []byte `json:"check"`
+}
+
+type sealedSecret struct {
+	Nonce      []byte `json:"nonce"`
+	Ciphertext []byte `json:"ciphertext"`
+}
+
+var (
+	secretMu   sync.Mutex
+	secretAEAD cipher.AEAD
+)
+
+// OpenSecretStore derives the secret store key from passphrase with scrypt and
+// makes the secrets available to the Load and Save helpers. Secrets stored in
+// plain text by earlier versions are encrypted.
+func OpenSecretStore(passphrase string) error {
+	if passphrase == "" {
+		return errors.New("a passphrase is required to encrypt the stored secrets")
+	}
+	params, isNew, err := loadSecretParams()
+	if err != nil {
+		return err
+	}
+
+	var aead cipher.AEAD
+	if isNew {
+		params, aead, err = newSecretParams(passphrase)
+		if err != nil {
+			return err
+		}
+		if err := DefaultStore().Put(BucketSecrets, secretParamsKey, params); err != nil {
+			return fmt.Errorf("failed to save secret store parameters: %v", err)
+		}
+	} else {
+		aead, err = openSecretParams(passphrase, params)
+		if err != nil {
+			return err
+		}
+	}
+
+	secretMu.Lock()
+	secretAEAD = aead
+	secretMu.Unlock()
+
+	return encryptPlaintextSecrets()
+}
+
+// ChangeSecretStorePassphrase encrypts every stored secret again with a key
+// derived from newPassphrase, in one transaction. The store must be closed.
+func ChangeSecretStorePassphrase(oldPassphrase, newPassphrase string) error {
+	params, isNew, err := loadSecretParams()
+	if err != nil || isNew {
+		return err
+	}
+	oldAEAD, err := openSecretParams(oldPassphrase, params)
+	if err != nil {
+		return err
+	}
+	newParams, newAEAD, err := newSecretParams(newPassphrase)
+	if err != nil {
+		return err
+	}
+
+	entries, err := DefaultStore().List(BucketSecrets)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %v", err)
+	}
+	values := map[string]interface{}{secretParamsKey: newParams}
+	for key, raw := range entries {
+		if key == secretParamsKey {
+			continue
+		}
+		var sealed sealedSecret
+		if err := json.Unmarshal(raw, &sealed); err != nil {
+			return fmt.Errorf("failed to decode secret %s: %v", key, err)
+		}
+		plaintext, err := open(oldAEAD, key, sealed)
+		if err != nil {
+			return err
+		}
+		resealed, err := seal(newAEAD, key, plaintext)
+		sensitive.Zero(plaintext)
+		if err != nil {
+			return err
+		}
+		values[key] = resealed
+	}
+	if err := DefaultStore().PutAll(BucketSecrets, values); err != nil {
+		return fmt.Errorf("failed to save re-encrypted secrets: %v", err)
+	}
+	log.Printf("Re-encrypted %d secrets with a new passphrase", len(values)-1)
+	return nil
+}
+
+// loadSecretParams returns the key derivation parameters of the store, or
+// isNew when the store has none yet.
+func loadSecretParams() (params secretParams, isNew bool, err error) {
+	err = DefaultStore().Get(BucketSecrets, secretParamsKey, &params)
+	if errors.Is(err, ErrNotFound) {
+		return params, true, nil
+	}
+	if err != nil {
+		return params, false, fmt.Errorf("failed to load secret store parameters: %v", err)
+	}
+	return params, false, nil
+}
+
+// newSecretParams returns fresh key derivation parameters for passphrase and
+// the cipher of the derived key.
+func newSecretParams(passphrase string) (secretParams, cipher.AEAD, error) {
+	params := secretParams{Salt: make([]byte, 32), N: keystore.StandardScryptN, R: 8, P: keystore.StandardScryptP}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return params, nil, fmt.Errorf("failed to generate secret store salt: %v", err)
+	}
+	aead, err := deriveSecretCipher(passphrase, params)
+	if err != nil {
+		return params, nil, err
+	}
+	sealed, err := seal(aead, secretParamsKey, []byte(secretCheck))
+	if err != nil {
+		return params, nil, err
+	}
+	params.Check = append(sealed.Nonce, sealed.Ciphertext...)
+	return params, aead, nil
+}
+
+// openSecretParams returns the cipher of the key derived from passphrase with
+// params, after checking it against the stored check value.
+func openSecretParams(passphrase string, params secretParams) (cipher.AEAD, error) {
+	aead, err := deriveSecretCipher(passphrase, params)
+	if err != nil {
+		return nil, err
+	}
+	size := aead.NonceSize()
+	if len(params.Check) < size {
+		return nil, errors.New("secret store parameters are corrupt")
+	}
+	sealed := sealedSecret{Nonce: params.Check[:size], Ciphertext: params.Check[size:]}
+	if _, err := open(aead, secretParamsKey, sealed); err != nil {
+		return nil, ErrWrongSecretPassphrase
+	}
+	return aead, nil
+}
+
+// deriveSecretCipher derives the AES-256-GCM key of passphrase with scrypt.
+func deriveSecretCipher(passphrase string, params secretParams) (cipher.AEAD, error) {
+	password := []byte(passphrase)
+	key, err := scrypt.Key(password, params.Salt, params.N, params.R, params.P, 32)
+	sensitive.Zero(password)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive secret store key: %v", err)
+	}
+	block, err := aes.NewCipher(key)
+	sensitive.Zero(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// SealSecret encrypts secret and stores it under the name of a round.
+func SealSecret(round, name string, secret []byte) error {
+	aead, err := secretCipher()
+	if err != nil {
+		return err
+	}
+	key := secretKey(round, name)
+	sealed, err := seal(aead, key, secret)
+	if err != nil {
+		return err
+	}
+	if err := DefaultStore().Put(BucketSecrets, key, sealed); err != nil {
+		return fmt.Errorf("failed to save secret %s: %v", key, err)
+	}
+	return nil
+}
+
+// UnsealSecret returns the decrypted secret stored under the name of a round,
+// or an error wrapping ErrNotFound.
+func UnsealSecret(round, name string) ([]byte, error) {
+	aead, err := secretCipher()
+	if err != nil {
+		return nil, err
+	}
+	key := secretKey(round, name)
+	var sealed sealedSecret
+	if err := DefaultStore().Get(BucketSecrets, key, &sealed); err != nil {
+		return nil, fmt.Errorf("failed to load secret %s: %w", key, err)
+	}
+	return open(aead, key, sealed)
+}
+
+// PurgeRoundSecrets deletes every secret of a round. It is called once the
+// random number of the round is generated and the secrets are public.
+func PurgeRoundSecrets(round string) error {
+	entries, err := DefaultStore().List(BucketSecrets)
+	if err != nil {
+		return fmt.Errorf("failed to list secrets: %v", err)
+	}
+	purged := 0
+	for key := range entries {
+		if !strings.HasPrefix(key, round+"/") {
+			continue
+		}
+		if err := DefaultStore().Delete(BucketSecrets, key); err != nil {
+			return fmt.Errorf("failed to purge secret %s: %v", key, err)
+		}
+		purged++
+	}
+	if purged > 0 {
+		log.Printf("Purged %d secrets of round %s", purged, round)
+	}
+	return nil
+}
+
+func secretKey(round, name string) string {
+	return round + "/" + name
+}
+
+func secretCipher() (cipher.AEAD, error) {
+	secretMu.Lock()
+	defer secretMu.Unlock()
+	if secretAEAD == nil {
+		return nil, ErrSecretStoreClosed
+	}
+	return secretAEAD, nil
+}
+
+// seal encrypts plaintext, binding it to key as additional data so a sealed
+// secret cannot be moved to another round or operator.
+func seal(aead cipher.AEAD, key string, plaintext []byte) (sealedSecret, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return sealedSecret{}, fmt.Errorf("failed to generate nonce: %v", err)
+	}
+	return sealedSecret{Nonce: nonce, Ciphertext: aead.Seal(nil, nonce, plaintext, []byte(key))}, nil
+}
+
+func open(aead cipher.AEAD, key string, sealed sealedSecret) ([]byte, error) {
+	if len(sealed.Nonce) != aead.NonceSize() {
+		return nil, fmt.Errorf("secret %s is corrupt", key)
+	}
+	plaintext, err := aead.Open(nil, sealed.Nonce, sealed.Ciphertext, []byte(key))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decrypt secret %s: %v", key, err)
+	}
+	return plaintext, nil
+}
+
+// encryptPlaintextSecrets moves the secrets stored in plain text by earlier
+// versions into the secret store.
+func encryptPlaintextSecrets() error {
+	commits, err := DefaultStore().List(BucketCommits)
+	if err != nil {
+		return fmt.Errorf("failed to list commits: %v", err)
+	}
+	for round := range commits {
+		var commitData CommitData
+		if err := DefaultStore().Get(BucketCommits, round, &commitData); err != nil {
+			return fmt.Errorf("failed to load commit data for round %s: %v", round, err)
+		}
+		if commitData.SecretValue == [32]byte{} && commitData.Cos == [32]byte{} {
+			continue
+		}
+		if err := SaveCommitData(commitData); err != nil {
+			return err
+		}
+		log.Printf("Encrypted the stored secret of round %s", round)
+	}
+
+	leaderCommits, err := LoadAllLeaderCommitData()
+	if err != nil {
+		return err
+	}
+	for key, commitData := range leaderCommits {
+		if commitData.SecretValue == [32]byte{} && commitData.SecretValueHex == "" {
+			continue
+		}
+		if err := SaveLeaderCommitData(commitData); err != nil {
+			return err
+		}
+		log.Printf("Encrypted the stored secret value of %s", key)
+	}
+	return nil
+}
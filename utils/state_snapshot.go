@@ -0,0 +1,89 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// StateSnapshot is a portable copy of a node's stored state. It holds every
+// bucket as raw JSON, so it can move state between storage backends or hosts.
+type StateSnapshot struct {
+	NodeType   string                                `json:"node_type"`
+	ExportedAt time.Time                             `json:"exported_at"`
+	Buckets    map[string]map[string]json.RawMessage `json:"buckets"`
+}
+
+// listedBuckets are the buckets exported entry by entry.
+var listedBuckets = []string{
+	BucketCommits,
+	BucketLeaderCommits,
+	BucketRevealOrders,
+	BucketRegisteredNodes,
+	BucketRoundStates,
+	BucketRoundReports,
+	BucketVerifiedRevealOrders,
+	BucketEvidence,
+	BucketSecrets,
+	BucketOutbox,
+}
+
+// documentKeys returns the single key of each bucket the JSON backend keeps as
+// a document instead of a map.
+func documentKeys(nodeType string) map[string]string {
+	return map[string]string{
+		BucketPeerIdentity: nodeType,
+		BucketWatcher:      nodeType,
+		BucketNodeInfo:     nodeInfoKey,
+	}
+}
+
+// ExportState copies the state of a node out of the default store.
+func ExportState(nodeType string) (*StateSnapshot, error) {
+	store := DefaultStore()
+	snapshot := &StateSnapshot{
+		NodeType:   nodeType,
+		ExportedAt: time.Now().UTC(),
+		Buckets:    make(map[string]map[string]json.RawMessage),
+	}
+
+	for _, bucket := range listedBuckets {
+		entries, err := store.List(bucket)
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %v", bucket, err)
+		}
+		if len(entries) > 0 {
+			snapshot.Buckets[bucket] = entries
+		}
+	}
+
+	for bucket, key := range documentKeys(nodeType) {
+		var raw json.RawMessage
+		err := store.Get(bucket, key, &raw)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to export %s: %v", bucket, err)
+		}
+		snapshot.Buckets[bucket] = map[string]json.RawMessage{key: raw}
+	}
+	return snapshot, nil
+}
+
+// ImportState writes every entry of a snapshot to the default store, replacing
+// entries with the same key. Each bucket is written in a single transaction.
+func ImportState(snapshot *StateSnapshot) error {
+	store := DefaultStore()
+	for bucket, entries := range snapshot.Buckets {
+		values := make(map[string]interface{}, len(entries))
+		for key, raw := range entries {
+			values[key] = raw
+		}
+		if err := store.PutAll(bucket, values); err != nil {
+			return fmt.Errorf("failed to import %s: %v", bucket, err)
+		}
+	}
+	return nil
+}
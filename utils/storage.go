@@ -0,0 +1,149 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Buckets used by the node. With the JSON backend each bucket keeps the file
+// name used before the storage layer existed.
+const (
+	BucketCommits         = "commits"
+	BucketLeaderCommits   = "leader_commits"
+	BucketRevealOrders    = "reveal_orders"
+	BucketRegisteredNodes = "registered_nodes"
+	BucketNodeInfo        = "node_info"
+	BucketPeerIdentity    = "peer_identity"
+	BucketWatcher         = "watcher"
+)
+
+// ErrNotFound is returned by Store.Get when the key does not exist.
+var ErrNotFound = errors.New("not found")
+
+// Store persists node state as JSON documents grouped in buckets.
+// Every Put and PutAll must be atomic: after a crash either the old or the new
+// value is visible, never a partially written one.
+type Store interface {
+	// Get decodes the value stored under key into value.
+	Get(bucket, key string, value interface{}) error
+	// Put stores value under key.
+	Put(bucket, key string, value interface{}) error
+	// PutAll stores every entry of values in a single transaction.
+	PutAll(bucket string, values map[string]interface{}) error
+	// Delete removes key. Deleting a missing key is not an error.
+	Delete(bucket, key string) error
+	// List returns every raw value of a bucket keyed by its key.
+	List(bucket string) (map[string]json.RawMessage, error)
+	Close() error
+}
+
+var (
+	storeMu      sync.Mutex
+	defaultStore Store
+	storeNode    = "regular"
+)
+
+// SetDefaultStore sets the store used by the package-level Load/Save helpers.
+// nodeType ("leader" or "regular") keys the state both node types keep in the
+// same bucket, such as the libp2p identity.
+func SetDefaultStore(s Store, nodeType string) {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	defaultStore = s
+	storeNode = nodeType
+}
+
+// DefaultStore returns the process-wide store, opening the default bolt
+// database in the working directory if none was set.
+func DefaultStore() Store {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+
+	if defaultStore == nil {
+		s, err := OpenStore("", "", storeNode)
+		if err != nil {
+			log.Fatalf("Failed to open storage: %v", err)
+		}
+		defaultStore = s
+	}
+	return defaultStore
+}
+
+// OpenStore opens a store backend. The bolt database is named after the node type
+// so a leader and a regular node can share a working directory. On first use the
+// bolt backend imports any state left by the JSON backend.
+func OpenStore(backend, dir, nodeType string) (Store, error) {
+	if dir == "" {
+		dir = "."
+	}
+
+	switch backend {
+	case "", "bolt":
+		path := filepath.Join(dir, nodeType+".db")
+		_, statErr := os.Stat(path)
+		s, err := NewBoltStore(path)
+		if err != nil {
+			return nil, err
+		}
+		if os.IsNotExist(statErr) {
+			if err := importJSONStore(s, NewJSONStore(dir), nodeType); err != nil {
+				s.Close()
+				return nil, fmt.Errorf("failed to import JSON state into %s: %v", path, err)
+			}
+		}
+		return s, nil
+	case "json":
+		return NewJSONStore(dir), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", backend)
+	}
+}
+
+// importJSONStore copies the legacy JSON files into dst.
+func importJSONStore(dst Store, src *JSONStore, nodeType string) error {
+	for _, bucket := range []string{BucketCommits, BucketLeaderCommits, BucketRevealOrders, BucketRegisteredNodes} {
+		entries, err := src.List(bucket)
+		if err != nil {
+			return err
+		}
+		if len(entries) == 0 {
+			continue
+		}
+		values := make(map[string]interface{}, len(entries))
+		for key, raw := range entries {
+			values[key] = raw
+		}
+		if err := dst.PutAll(bucket, values); err != nil {
+			return err
+		}
+		log.Printf("Imported %d %s entries from JSON storage", len(entries), bucket)
+	}
+
+	for _, bucket := range []string{BucketPeerIdentity, BucketWatcher} {
+		var raw json.RawMessage
+		err := src.Get(bucket, nodeType, &raw)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err := dst.Put(bucket, nodeType, raw); err != nil {
+			return err
+		}
+		log.Printf("Imported %s for %s node from JSON storage", bucket, nodeType)
+	}
+	return nil
+}
+
+// nodeTypeOrDefault returns the node type set with SetDefaultStore, defaulting to "regular".
+func nodeTypeOrDefault() string {
+	storeMu.Lock()
+	defer storeMu.Unlock()
+	return storeNode
+}
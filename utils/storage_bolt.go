@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// BoltStore keeps every bucket in a single bbolt database. Each write is a
+// bbolt transaction, which is fsynced on commit.
+type BoltStore struct {
+	db *bolt.DB
+}
+
+// NewBoltStore opens (or creates) the database at path.
+func NewBoltStore(path string) (*BoltStore, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open bolt database %s: %v", path, err)
+	}
+	return &BoltStore{db: db}, nil
+}
+
+func (s *BoltStore) Get(bucket, key string, value interface{}) error {
+	return s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return ErrNotFound
+		}
+		data := b.Get([]byte(key))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, value)
+	})
+}
+
+func (s *BoltStore) Put(bucket, key string, value interface{}) error {
+	return s.PutAll(bucket, map[string]interface{}{key: value})
+}
+
+func (s *BoltStore) PutAll(bucket string, values map[string]interface{}) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return fmt.Errorf("failed to create bucket %s: %v", bucket, err)
+		}
+		for key, value := range values {
+			data, err := json.Marshal(value)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s/%s: %v", bucket, key, err)
+			}
+			if err := b.Put([]byte(key), data); err != nil {
+				return fmt.Errorf("failed to write %s/%s: %v", bucket, key, err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *BoltStore) Delete(bucket, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func (s *BoltStore) List(bucket string) (map[string]json.RawMessage, error) {
+	entries := make(map[string]json.RawMessage)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(k, v []byte) error {
+			// Values are only valid for the life of the transaction.
+			entries[string(k)] = append(json.RawMessage(nil), v...)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func (s *BoltStore) Close() error {
+	return s.db.Close()
+}
@@ -0,0 +1,206 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// JSONStore keeps each bucket in a JSON file, compatible with the files written
+// by earlier versions of the node. Writes go to a temporary file that is fsynced
+// and renamed over the original.
+type JSONStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// documentFiles lists buckets stored as one file per key, where the file holds
+// the value itself rather than a map.
+var documentFiles = map[string]func(key string) string{
+	BucketPeerIdentity: func(key string) string { return key + "_peer_id.json" },
+	BucketWatcher:      func(key string) string { return key + "_watcher_state.json" },
+	BucketNodeInfo:     func(string) string { return "node_info.json" },
+}
+
+// NewJSONStore returns a JSON store rooted at dir.
+func NewJSONStore(dir string) *JSONStore {
+	if dir == "" {
+		dir = "."
+	}
+	return &JSONStore{dir: dir}
+}
+
+func (s *JSONStore) Get(bucket, key string, value interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fileName, ok := documentFiles[bucket]; ok {
+		data, err := os.ReadFile(filepath.Join(s.dir, fileName(key)))
+		if os.IsNotExist(err) {
+			return ErrNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read %s/%s: %v", bucket, key, err)
+		}
+		return json.Unmarshal(data, value)
+	}
+
+	entries, err := s.readBucket(bucket)
+	if err != nil {
+		return err
+	}
+	raw, ok := entries[key]
+	if !ok {
+		return ErrNotFound
+	}
+	return json.Unmarshal(raw, value)
+}
+
+func (s *JSONStore) Put(bucket, key string, value interface{}) error {
+	return s.PutAll(bucket, map[string]interface{}{key: value})
+}
+
+func (s *JSONStore) PutAll(bucket string, values map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fileName, ok := documentFiles[bucket]; ok {
+		for key, value := range values {
+			data, err := json.MarshalIndent(value, "", "  ")
+			if err != nil {
+				return fmt.Errorf("failed to encode %s/%s: %v", bucket, key, err)
+			}
+			if err := writeFileAtomic(filepath.Join(s.dir, fileName(key)), data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	entries, err := s.readBucket(bucket)
+	if err != nil {
+		return err
+	}
+	for key, value := range values {
+		data, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s/%s: %v", bucket, key, err)
+		}
+		entries[key] = data
+	}
+	return s.writeBucket(bucket, entries)
+}
+
+func (s *JSONStore) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if fileName, ok := documentFiles[bucket]; ok {
+		err := os.Remove(filepath.Join(s.dir, fileName(key)))
+		if err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to delete %s/%s: %v", bucket, key, err)
+		}
+		return nil
+	}
+
+	entries, err := s.readBucket(bucket)
+	if err != nil {
+		return err
+	}
+	if _, ok := entries[key]; !ok {
+		return nil
+	}
+	delete(entries, key)
+	return s.writeBucket(bucket, entries)
+}
+
+func (s *JSONStore) List(bucket string) (map[string]json.RawMessage, error) {
+	if _, ok := documentFiles[bucket]; ok {
+		return nil, fmt.Errorf("bucket %s cannot be listed with the JSON backend", bucket)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.readBucket(bucket)
+}
+
+func (s *JSONStore) Close() error {
+	return nil
+}
+
+func (s *JSONStore) bucketFile(bucket string) string {
+	return filepath.Join(s.dir, bucket+".json")
+}
+
+// readBucket loads a bucket file. Called with s.mu locked.
+func (s *JSONStore) readBucket(bucket string) (map[string]json.RawMessage, error) {
+	entries := make(map[string]json.RawMessage)
+
+	data, err := os.ReadFile(s.bucketFile(bucket))
+	if os.IsNotExist(err) {
+		return entries, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %v", s.bucketFile(bucket), err)
+	}
+	if len(data) == 0 {
+		return entries, nil
+	}
+
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %v", s.bucketFile(bucket), err)
+	}
+	return entries, nil
+}
+
+// writeBucket replaces a bucket file. Called with s.mu locked.
+func (s *JSONStore) writeBucket(bucket string, entries map[string]json.RawMessage) error {
+	data, err := json.Marshal(entries)
+	if err != nil {
+		return fmt.Errorf("failed to encode %s: %v", bucket, err)
+	}
+	return writeFileAtomic(s.bucketFile(bucket), data)
+}
+
+// writeFileAtomic writes data to a temporary file in the same directory, fsyncs
+// it, renames it over path and fsyncs the directory.
+func writeFileAtomic(path string, data []byte) error {
+	dir := filepath.Dir(path)
+	tmp, err := os.CreateTemp(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file for %s: %v", path, err)
+	}
+	tmpName := tmp.Name()
+	defer os.Remove(tmpName)
+
+	if err := tmp.Chmod(0600); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to set permissions on %s: %v", tmpName, err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write %s: %v", tmpName, err)
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to fsync %s: %v", tmpName, err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %v", tmpName, err)
+	}
+	if err := os.Rename(tmpName, path); err != nil {
+		return fmt.Errorf("failed to rename %s to %s: %v", tmpName, path, err)
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return fmt.Errorf("failed to open directory %s: %v", dir, err)
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil {
+		return fmt.Errorf("failed to fsync directory %s: %v", dir, err)
+	}
+	return nil
+}
@@ -2,14 +2,12 @@ package utils
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 
 	"github.com/libp2p/go-libp2p/core/host"
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/libp2p/go-libp2p/core/peerstore"
-	"github.com/libp2p/go-libp2p/core/protocol"
 	"github.com/multiformats/go-multiaddr"
 )
 
@@ -30,25 +28,12 @@ func CreateStream(h host.Host, nodeInfo NodeInfo, protocolStr string) (network.S
 	// Add the peer address to the peerstore
 	h.Peerstore().AddAddrs(peerInfo.ID, peerInfo.Addrs, peerstore.PermanentAddrTTL)
 
-	// Convert the protocol string to protocol.ID
-	protoID := protocol.ID(protocolStr)
-
-	// Open a stream to the peer using the specified protocol
-	stream, err := h.NewStream(context.Background(), peerInfo.ID, protoID)
+	// Open a stream to the peer using the specified protocol, or the ID an
+	// older node uses for it
+	stream, err := h.NewStream(context.Background(), peerInfo.ID, ProtocolIDs(protocolStr)...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open stream: %v", err)
 	}
 
 	return stream, nil
 }
-
-func SendDataOverStream(stream network.Stream, data interface{}) error {
-	// Encode the data into JSON
-	encoder := json.NewEncoder(stream)
-	err := encoder.Encode(data)
-	if err != nil {
-		return fmt.Errorf("failed to encode and send data over stream: %v", err)
-	}
-
-	return nil
-}
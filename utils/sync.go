@@ -0,0 +1,17 @@
+package utils
+
+// SyncRequest lists what the sender holds of an operator in some rounds, on
+// ProtocolSync. A regular node sends its own records to the leader, which
+// answers with its records in the Data of the Response. The leader sends its
+// records to a regular node to ask for the CVS and COS it is missing.
+type SyncRequest struct {
+	EOAAddress string      `json:"eoa_address"` // Sender's EOA address
+	Rounds     []RoundSync `json:"rounds"`
+}
+
+// RoundSync is what one side holds of an operator in a round.
+type RoundSync struct {
+	Round string         `json:"round"`
+	Cvs   string         `json:"cvs,omitempty"`   // hex encoded CVS, if one is held
+	State *OperatorState `json:"state,omitempty"` // set by the leader
+}
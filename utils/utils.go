@@ -1,47 +1,21 @@
 package utils
 
-import (
-	"crypto/ecdsa"
-	"log"
+import "github.com/tokamak-network/DRB-node/sensitive"
 
-	"github.com/ethereum/go-ethereum/crypto"
-)
+// Payloads are sent inside an Envelope, which carries the signature.
 
 type RegistrationRequest struct {
 	EOAAddress string `json:"eoa_address"`
-	Signature  []byte `json:"signature"`
 	PeerID     string `json:"peer_id"`
 }
 
 type SecretValueRequest struct {
-	EOAAddress string `json:"eoa_address"` // Sender's EOA address
-	Round      string `json:"round"`       // Round number
-	Signature  []byte `json:"signature"`   // Signature
-	SecretValue []byte  `json:"secret_value"`
-}
-
-// VerifySignature checks if the signature matches the EOA address
-func VerifySignature(req RegistrationRequest) bool {
-	hash := crypto.Keccak256Hash([]byte(req.EOAAddress))
-	pubKey, err := crypto.SigToPub(hash.Bytes(), req.Signature)
-	if err != nil {
-		log.Printf("Error recovering public key: %v", err)
-		return false
-	}
-
-	recoveredAddress := crypto.PubkeyToAddress(*pubKey).Hex()
-	log.Printf("recoveredAddress........:%s", recoveredAddress)
-	log.Printf("req.EOAAddress........:%s", req.EOAAddress)
-
-	return recoveredAddress == req.EOAAddress
-}
-
-// SignData signs the given data with the provided private key
-func SignData(data string, privateKey *ecdsa.PrivateKey) []byte {
-	hash := crypto.Keccak256Hash([]byte(data))
-	signature, err := crypto.Sign(hash.Bytes(), privateKey)
-	if err != nil {
-		log.Fatalf("Failed to sign data: %v", err)
-	}
-	return signature
+	EOAAddress  string          `json:"eoa_address"` // Sender's EOA address
+	Round       string          `json:"round"`       // Round number
+	SecretValue sensitive.Bytes `json:"secret_value"`
+
+	// Set by the leader when requesting a secret: the reveal order of the round
+	// and the hex encoded secrets of the operators before the recipient in it.
+	RevealOrder     *RevealOrder `json:"reveal_order,omitempty"`
+	PreviousSecrets []string     `json:"previous_secrets,omitempty"`
 }